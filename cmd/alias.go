@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+
+	"github.com/spf13/cobra"
+)
+
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Named task bookmarks",
+	Long: `Named task bookmarks. An alias can be used anywhere a task ID is expected,
+e.g. "ticky tasks complete standup".`,
+}
+
+var aliasSetCmd = &cobra.Command{
+	Use:   "set <name> <task>",
+	Short: "Bookmark a task under a name",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if err := ticktick.ValidateAliasName(name); err != nil {
+			return err
+		}
+
+		client, err := ticktick.NewClient()
+		if err != nil {
+			return err
+		}
+		taskID, err := client.ResolveTaskRef(args[1])
+		if err != nil {
+			return err
+		}
+		task, err := client.LocateTask(taskID)
+		if err != nil {
+			return fmt.Errorf("failed to get task: %w", err)
+		}
+
+		err = ticktick.UpdateAliases(func(aliases map[string]string) error {
+			aliases[name] = task.ID
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save aliases: %w", err)
+		}
+
+		fmt.Printf("Alias %s -> %s (%s)\n", name, task.ID, task.Title)
+		return nil
+	},
+}
+
+var aliasListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List aliases",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		aliases, err := ticktick.LoadAliases()
+		if err != nil {
+			return fmt.Errorf("failed to load aliases: %w", err)
+		}
+
+		if outputJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(aliases)
+		}
+
+		names := make([]string, 0, len(aliases))
+		for name := range aliases {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		if outputPlain {
+			for _, name := range names {
+				fmt.Printf("%s\t%s\n", name, aliases[name])
+			}
+			return nil
+		}
+
+		if len(names) == 0 {
+			fmt.Println("No aliases found")
+			return nil
+		}
+		for _, name := range names {
+			fmt.Printf("%-16s %s\n", name, aliases[name])
+		}
+		return nil
+	},
+}
+
+var aliasRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove an alias",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var found bool
+		err := ticktick.UpdateAliases(func(aliases map[string]string) error {
+			_, found = aliases[args[0]]
+			delete(aliases, args[0])
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save aliases: %w", err)
+		}
+		if !found {
+			return fmt.Errorf("alias not found: %s", args[0])
+		}
+
+		fmt.Printf("Alias %s removed\n", args[0])
+		return nil
+	},
+}
+
+func init() {
+	aliasCmd.AddCommand(aliasSetCmd)
+	aliasCmd.AddCommand(aliasListCmd)
+	aliasCmd.AddCommand(aliasRemoveCmd)
+	rootCmd.AddCommand(aliasCmd)
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/tackeyy/ticky/internal/ticktick"
 
+	"github.com/charmbracelet/x/term"
 	"github.com/spf13/cobra"
 )
 
@@ -18,8 +19,37 @@ var authCmd = &cobra.Command{
 var authLoginCmd = &cobra.Command{
 	Use:   "login",
 	Short: "Login to TickTick via OAuth",
+	Long: `Login to TickTick via OAuth. The token is saved to the active profile; use
+--profile to log in to another account.
+
+With --no-browser, no browser is opened and no local callback server is
+started: open the printed URL on any machine, then paste back the URL the
+browser was redirected to (or just the code). Use this over SSH or in
+containers.
+
+The login uses PKCE. The redirect URI defaults to
+http://localhost:18080/callback and can be changed with --redirect-host,
+--redirect-port and --redirect-path (or the redirect_* config keys); it must
+match the redirect URI registered for your TickTick app. The local callback
+server listens on the redirect URI's host and port.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		token, err := ticktick.OAuthLogin()
+		noBrowser, _ := cmd.Flags().GetBool("no-browser")
+		host, _ := cmd.Flags().GetString("redirect-host")
+		port, _ := cmd.Flags().GetString("redirect-port")
+		path, _ := cmd.Flags().GetString("redirect-path")
+
+		redirectURI, err := ticktick.RedirectURI(host, port, path)
+		if err != nil {
+			return err
+		}
+		opts := ticktick.LoginOptions{RedirectURI: redirectURI}
+
+		var token *ticktick.OAuthToken
+		if noBrowser {
+			token, err = ticktick.OAuthLoginNoBrowser(opts, os.Stdin, os.Stdout)
+		} else {
+			token, err = ticktick.OAuthLogin(opts)
+		}
 		if err != nil {
 			return fmt.Errorf("login failed: %w", err)
 		}
@@ -28,8 +58,8 @@ var authLoginCmd = &cobra.Command{
 			return fmt.Errorf("failed to save token: %w", err)
 		}
 
-		fmt.Println("Successfully authenticated!")
-		fmt.Printf("Token saved to %s\n", ticktick.TokenPath())
+		fmt.Printf("Successfully authenticated! (profile: %s)\n", ticktick.ActiveProfile())
+		fmt.Printf("Token saved to %s\n", tokenLocation())
 		return nil
 	},
 }
@@ -43,9 +73,9 @@ var authStatusCmd = &cobra.Command{
 			if outputJSON {
 				enc := json.NewEncoder(os.Stdout)
 				enc.SetIndent("", "  ")
-				return enc.Encode(map[string]string{"status": "not authenticated", "error": err.Error()})
+				return enc.Encode(map[string]string{"status": "not authenticated", "profile": ticktick.ActiveProfile(), "error": err.Error()})
 			}
-			fmt.Println("Not authenticated")
+			fmt.Printf("Not authenticated (profile: %s)\n", ticktick.ActiveProfile())
 			return nil
 		}
 
@@ -57,9 +87,11 @@ var authStatusCmd = &cobra.Command{
 
 		if outputJSON {
 			out := map[string]any{
-				"status":         "authenticated",
-				"project_count":  len(projects),
-				"token_path":     ticktick.TokenPath(),
+				"status":        "authenticated",
+				"profile":       ticktick.ActiveProfile(),
+				"project_count": len(projects),
+				"token_path":    tokenLocation(),
+				"encrypted":     ticktick.TokenEncrypted(),
 			}
 			enc := json.NewEncoder(os.Stdout)
 			enc.SetIndent("", "  ")
@@ -67,11 +99,12 @@ var authStatusCmd = &cobra.Command{
 		}
 
 		if outputPlain {
-			fmt.Printf("authenticated\t%d projects\n", len(projects))
+			fmt.Printf("authenticated\t%d projects\t%s\n", len(projects), ticktick.ActiveProfile())
 			return nil
 		}
 
-		fmt.Printf("Authenticated (token: %s)\n", ticktick.TokenPath())
+		fmt.Printf("Authenticated (token: %s)\n", tokenLocation())
+		fmt.Printf("Profile:  %s\n", ticktick.ActiveProfile())
 		fmt.Printf("Projects: %d\n", len(projects))
 		return nil
 	},
@@ -89,9 +122,93 @@ var authLogoutCmd = &cobra.Command{
 	},
 }
 
+var authEncryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypt the saved token in place",
+	Long: `Encrypt the saved token in place with AES-256-GCM and turn on encrypt_token,
+so refreshed tokens stay encrypted. The key is derived from the file named by
+TICKY_KEY_FILE if set, otherwise from a passphrase (TICKY_PASSPHRASE or a
+prompt).`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		changed, err := ticktick.EncryptTokenFile()
+		if err != nil {
+			return fmt.Errorf("encryption failed: %w", err)
+		}
+		if !changed {
+			fmt.Printf("Token is already encrypted (%s)\n", ticktick.TokenPath())
+			return nil
+		}
+		fmt.Printf("Token encrypted (%s)\n", ticktick.TokenPath())
+		return nil
+	},
+}
+
+var authDecryptCmd = &cobra.Command{
+	Use:   "decrypt",
+	Short: "Store the saved token as plaintext again",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		changed, err := ticktick.DecryptTokenFile()
+		if err != nil {
+			return fmt.Errorf("decryption failed: %w", err)
+		}
+		if !changed {
+			fmt.Printf("Token is not encrypted (%s)\n", ticktick.TokenPath())
+			return nil
+		}
+		fmt.Printf("Token decrypted (%s)\n", ticktick.TokenPath())
+		return nil
+	},
+}
+
+// tokenLocation describes where the token of the active profile is kept.
+func tokenLocation() string {
+	if helper := ticktick.CredentialHelper(); helper != "" {
+		return fmt.Sprintf("credential helper %q", helper)
+	}
+	return ticktick.TokenPath()
+}
+
+// promptPassphrase reads the token passphrase from the terminal without
+// echoing it.
+func promptPassphrase(confirm bool) (string, error) {
+	fd := os.Stdin.Fd()
+	if !term.IsTerminal(fd) {
+		return "", ticktick.ErrNoTokenKey
+	}
+	read := func(prompt string) (string, error) {
+		fmt.Fprint(os.Stderr, prompt)
+		p, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		return string(p), err
+	}
+	p, err := read("Token passphrase: ")
+	if err != nil || !confirm {
+		return p, err
+	}
+	again, err := read("Repeat passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if p != again {
+		return "", fmt.Errorf("passphrases do not match")
+	}
+	return p, nil
+}
+
 func init() {
+	ticktick.PassphraseFunc = promptPassphrase
+
+	authLoginCmd.Flags().Bool("no-browser", false, "Print the authorization URL and read the redirect URL or code from stdin")
+	authLoginCmd.Flags().String("redirect-host", "", "Host of the OAuth redirect URI (default: redirect_host config, localhost)")
+	authLoginCmd.Flags().String("redirect-port", "", "Port of the OAuth redirect URI (default: redirect_port config, 18080)")
+	authLoginCmd.Flags().String("redirect-path", "", "Path of the OAuth redirect URI (default: redirect_path config, /callback)")
+
 	authCmd.AddCommand(authLoginCmd)
 	authCmd.AddCommand(authStatusCmd)
 	authCmd.AddCommand(authLogoutCmd)
+	authCmd.AddCommand(authEncryptCmd)
+	authCmd.AddCommand(authDecryptCmd)
 	rootCmd.AddCommand(authCmd)
 }
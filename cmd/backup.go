@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+
+	"github.com/spf13/cobra"
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Back up all projects and tasks to a JSON archive",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := ticktick.NewClient()
+		if err != nil {
+			return err
+		}
+
+		snapshot, err := client.Snapshot()
+		if err != nil {
+			return fmt.Errorf("backup failed: %w", err)
+		}
+
+		compress, _ := cmd.Flags().GetBool("gzip")
+		path, _ := cmd.Flags().GetString("output")
+		if path == "" {
+			path = ticktick.BackupFileName(snapshot.CreatedAt, compress)
+		}
+
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", path, err)
+		}
+		if err := ticktick.WriteBackup(f, snapshot, compress); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+
+		taskCount := 0
+		for _, pd := range snapshot.Projects {
+			taskCount += len(pd.Tasks)
+		}
+
+		if outputJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(map[string]any{
+				"path":     path,
+				"version":  snapshot.Version,
+				"projects": len(snapshot.Projects),
+				"tasks":    taskCount,
+			})
+		}
+
+		if outputPlain {
+			fmt.Printf("%s\t%d\t%d\n", path, len(snapshot.Projects), taskCount)
+			return nil
+		}
+
+		fmt.Printf("Backed up %d projects and %d tasks to %s\n", len(snapshot.Projects), taskCount, path)
+		return nil
+	},
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <archive>",
+	Short: "Restore missing projects and tasks from a backup archive",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		archive, err := readBackupFile(args[0])
+		if err != nil {
+			return err
+		}
+
+		client, err := ticktick.NewClient()
+		if err != nil {
+			return err
+		}
+
+		live, err := client.Snapshot()
+		if err != nil {
+			return fmt.Errorf("failed to read current account state: %w", err)
+		}
+
+		opts := ticktick.RestoreOptions{}
+		opts.DryRun, _ = cmd.Flags().GetBool("dry-run")
+		if projectsStr, _ := cmd.Flags().GetString("project"); projectsStr != "" {
+			// Names are matched against the archive, whose projects may no
+			// longer exist in the account
+			var archived []ticktick.Project
+			for _, pd := range archive.Projects {
+				archived = append(archived, pd.Project)
+			}
+			for _, ref := range strings.Split(projectsStr, ",") {
+				ref = strings.TrimSpace(ref)
+				if strings.EqualFold(ref, "inbox") {
+					opts.Projects = append(opts.Projects, archive.InboxID)
+					continue
+				}
+				p, err := ticktick.MatchProject(archived, ref)
+				if err != nil {
+					return err
+				}
+				opts.Projects = append(opts.Projects, p.ID)
+			}
+		}
+		if tagsStr, _ := cmd.Flags().GetString("tags"); tagsStr != "" {
+			opts.Tags = strings.Split(tagsStr, ",")
+		}
+
+		actions, restoreErr := client.Restore(archive, live, opts)
+
+		var failed int
+		for _, a := range actions {
+			if a.Status == "failed" {
+				failed++
+			}
+		}
+
+		if outputJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(actions); err != nil {
+				return err
+			}
+		} else if outputPlain {
+			for _, a := range actions {
+				fmt.Printf("%s\t%s\t%s\t%s\t%s\n", a.Kind, a.OldID, a.NewID, a.Status, a.Name)
+			}
+		} else {
+			created := 0
+			for _, a := range actions {
+				switch a.Status {
+				case "created", "would-create":
+					created++
+					newID := a.NewID
+					if newID == "" {
+						newID = "(new)"
+					}
+					fmt.Printf("%-7s %-24s -> %-24s %s\n", a.Kind, a.OldID, newID, a.Name)
+				case "failed":
+					fmt.Fprintf(os.Stderr, "Failed to restore %s %q: %s\n", a.Kind, a.Name, a.Error)
+				}
+			}
+			if opts.DryRun {
+				fmt.Printf("Dry run: %d items would be restored (%d already present)\n", created, len(actions)-created-failed)
+			} else {
+				fmt.Printf("Restored %d items (%d already present, %d failed)\n", created, len(actions)-created-failed, failed)
+			}
+		}
+
+		if restoreErr != nil {
+			return restoreErr
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d items failed to restore", failed)
+		}
+		return nil
+	},
+}
+
+func init() {
+	backupCmd.Flags().StringP("output", "o", "", "Archive path (default: ticky-backup-<timestamp>.json[.gz])")
+	backupCmd.Flags().Bool("gzip", false, "Compress the archive with gzip")
+
+	restoreCmd.Flags().Bool("dry-run", false, "Show what would be restored without changing anything")
+	restoreCmd.Flags().String("project", "", "Only restore these projects (comma-separated IDs or names)")
+	restoreCmd.Flags().String("tags", "", "Only restore tasks with any of these tags (comma-separated)")
+
+	rootCmd.AddCommand(backupCmd)
+	rootCmd.AddCommand(restoreCmd)
+}
+
+// readBackupFile loads a backup archive from path.
+func readBackupFile(path string) (*ticktick.Backup, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	b, err := ticktick.ReadBackup(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return b, nil
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+
+	"github.com/spf13/cobra"
+)
+
+// cfg holds the effective settings: config file, overridden by environment
+// variables. Flags override both where they are read.
+var cfg = &ticktick.Config{}
+
+// loadConfig reads the effective settings and applies the global ones. The
+// config commands skip this so a broken file can still be fixed.
+func loadConfig(cmd *cobra.Command) error {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c == configCmd {
+			return nil
+		}
+	}
+
+	loaded, err := ticktick.LoadEffectiveConfig()
+	if err != nil {
+		return err
+	}
+	cfg = loaded
+
+	if cfg.Timezone != "" {
+		loc, err := time.LoadLocation(cfg.Timezone)
+		if err != nil {
+			return fmt.Errorf("invalid timezone %q: %w", cfg.Timezone, err)
+		}
+		time.Local = loc
+	}
+	if cfg.ProjectCacheTTL != "" {
+		ttl, err := time.ParseDuration(cfg.ProjectCacheTTL)
+		if err != nil {
+			return fmt.Errorf("invalid project_cache_ttl %q: %w", cfg.ProjectCacheTTL, err)
+		}
+		ticktick.ProjectCacheTTL = ttl
+	}
+
+	flags := cmd.Flags()
+	if !flags.Changed("json") && !flags.Changed("plain") {
+		switch cfg.Output {
+		case "json":
+			outputJSON = true
+		case "plain":
+			outputPlain = true
+		}
+	}
+	return nil
+}
+
+// findDefaultProjectID returns the configured default project, or the Inbox.
+func findDefaultProjectID(client *ticktick.Client) (string, error) {
+	if cfg.DefaultProject != "" {
+		return client.ResolveProject(cfg.DefaultProject)
+	}
+	return findInboxID(client)
+}
+
+// formatDue formats a due date in the configured date style.
+func formatDue(due string) string {
+	return ticktick.FormatDueDate(due, cfg.DateStyle, time.Now())
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage settings",
+	Long: `Manage settings of the active profile, stored in its config.json.
+
+Flags take precedence over environment variables, which take precedence over
+the config file. Run "ticky config list" to see every key and its source.`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the effective value of a setting",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		value, _, err := ticktick.ConfigValue(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println(value)
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Store a setting in the config file",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := ticktick.SetConfigValue(args[0], args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("%s = %s\n", args[0], args[1])
+		return nil
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a setting from the config file",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := ticktick.UnsetConfigValue(args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("%s unset\n", args[0])
+		return nil
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all settings with their effective values",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		type entry struct {
+			Key         string `json:"key"`
+			Value       string `json:"value"`
+			Source      string `json:"source"`
+			Env         string `json:"env,omitempty"`
+			Description string `json:"description"`
+		}
+		var entries []entry
+		for _, k := range ticktick.ConfigKeys() {
+			value, source, err := ticktick.ConfigValue(k.Name)
+			if err != nil {
+				return err
+			}
+			if k.Secret && value != "" {
+				value = "********"
+			}
+			entries = append(entries, entry{k.Name, value, source, k.Env, k.Description})
+		}
+
+		if outputJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(entries)
+		}
+
+		if outputPlain {
+			for _, e := range entries {
+				fmt.Printf("%s\t%s\t%s\n", e.Key, e.Value, e.Source)
+			}
+			return nil
+		}
+
+		for _, e := range entries {
+			value := e.Value
+			if value == "" {
+				value = "-"
+			}
+			fmt.Printf("%-18s %-24s (%s)\n", e.Key, value, e.Source)
+		}
+		return nil
+	},
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Open the config file in $EDITOR",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := ticktick.ConfigPath()
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+				return fmt.Errorf("failed to create config directory: %w", err)
+			}
+			if err := os.WriteFile(path, []byte("{}\n"), 0600); err != nil {
+				return fmt.Errorf("failed to create config file: %w", err)
+			}
+		}
+
+		editor := os.Getenv("VISUAL")
+		if editor == "" {
+			editor = os.Getenv("EDITOR")
+		}
+		if editor == "" {
+			editor = "vi"
+		}
+		fields := strings.Fields(editor)
+		c := exec.Command(fields[0], append(fields[1:], path)...)
+		c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := c.Run(); err != nil {
+			return fmt.Errorf("failed to run editor: %w", err)
+		}
+
+		if _, err := ticktick.LoadEffectiveConfig(); err != nil {
+			return fmt.Errorf("config file is invalid, run \"ticky config edit\" again to fix it: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configEditCmd)
+	rootCmd.AddCommand(configCmd)
+}
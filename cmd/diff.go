@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <a> [<b>]",
+	Short: "Compare two backups, or a backup against the live account",
+	Long:  "Compare two backup archives, or a backup against the live account with --live, and report added, removed, completed and modified tasks.",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		live, _ := cmd.Flags().GetBool("live")
+		if live == (len(args) == 2) {
+			return fmt.Errorf("specify either a second archive or --live")
+		}
+
+		a, err := readBackupFile(args[0])
+		if err != nil {
+			return err
+		}
+
+		var d *ticktick.SnapshotDiff
+		if live {
+			client, err := ticktick.NewClient()
+			if err != nil {
+				return err
+			}
+			b, err := client.Snapshot()
+			if err != nil {
+				return fmt.Errorf("failed to read current account state: %w", err)
+			}
+			d = ticktick.DiffSnapshots(a, b)
+			client.ClassifyRemoved(d)
+		} else {
+			b, err := readBackupFile(args[1])
+			if err != nil {
+				return err
+			}
+			d = ticktick.DiffSnapshots(a, b)
+		}
+
+		if outputJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(d)
+		}
+
+		groups := []struct {
+			kind   string
+			mark   string
+			change []ticktick.TaskChange
+		}{
+			{"added", "+", d.Added},
+			{"removed", "-", d.Removed},
+			{"completed", "x", d.Completed},
+			{"modified", "~", d.Modified},
+		}
+
+		if outputPlain {
+			for _, g := range groups {
+				for _, tc := range g.change {
+					if len(tc.Changes) == 0 {
+						fmt.Printf("%s\t%s\t%s\t%s\t\t\t\n", g.kind, tc.ID, tc.ProjectID, tc.Title)
+					}
+					for _, fc := range tc.Changes {
+						fmt.Printf("%s\t%s\t%s\t%s\t%s\t%s\t%s\n", g.kind, tc.ID, tc.ProjectID, tc.Title, fc.Field, fc.Old, fc.New)
+					}
+				}
+			}
+			return nil
+		}
+
+		if d.Empty() {
+			fmt.Println("No differences")
+			return nil
+		}
+		for _, g := range groups {
+			for _, tc := range g.change {
+				fmt.Printf("%s %-24s %s\n", g.mark, tc.ID, tc.Title)
+				for _, fc := range tc.Changes {
+					fmt.Printf("    %s: %q -> %q\n", fc.Field, fc.Old, fc.New)
+				}
+			}
+		}
+		fmt.Printf("\n%d added, %d removed, %d completed, %d modified\n",
+			len(d.Added), len(d.Removed), len(d.Completed), len(d.Modified))
+		return nil
+	},
+}
+
+func init() {
+	diffCmd.Flags().Bool("live", false, "Compare the archive against the live account")
+
+	rootCmd.AddCommand(diffCmd)
+}
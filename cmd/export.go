@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export tasks to other formats",
+}
+
+var exportTodoTxtCmd = &cobra.Command{
+	Use:   "todotxt",
+	Short: "Export tasks in todo.txt format",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := ticktick.NewClient()
+		if err != nil {
+			return err
+		}
+
+		projectID, err := resolveProjectFlag(cmd, client)
+		if err != nil {
+			return err
+		}
+		projects, err := fetchProjectData(client, projectID)
+		if err != nil {
+			return err
+		}
+
+		return writeExport(cmd, func(w io.Writer) error {
+			for _, pd := range projects {
+				for _, t := range pd.Tasks {
+					if _, err := fmt.Fprintln(w, ticktick.FormatTodoTxt(t, pd.Project.Name)); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+	},
+}
+
+var exportMarkdownCmd = &cobra.Command{
+	Use:   "markdown",
+	Short: "Export tasks as a Markdown checklist",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := ticktick.NewClient()
+		if err != nil {
+			return err
+		}
+
+		projectID, err := resolveProjectFlag(cmd, client)
+		if err != nil {
+			return err
+		}
+		projects, err := fetchProjectData(client, projectID)
+		if err != nil {
+			return err
+		}
+
+		return writeExport(cmd, func(w io.Writer) error {
+			return ticktick.RenderMarkdown(w, projects)
+		})
+	},
+}
+
+func init() {
+	exportTodoTxtCmd.Flags().String("project", "", "Project name or ID (default: all projects)")
+	exportTodoTxtCmd.Flags().StringP("output", "o", "", "Output file (default: stdout)")
+
+	exportMarkdownCmd.Flags().String("project", "", "Project name or ID (default: all projects)")
+	exportMarkdownCmd.Flags().StringP("output", "o", "", "Output file (default: stdout)")
+
+	exportCmd.AddCommand(exportTodoTxtCmd)
+	exportCmd.AddCommand(exportMarkdownCmd)
+	rootCmd.AddCommand(exportCmd)
+}
+
+// fetchProjectData returns the data of a single project, or of every project
+// (Inbox first) when projectID is empty.
+func fetchProjectData(client *ticktick.Client, projectID string) ([]ticktick.ProjectData, error) {
+	if projectID == "" {
+		snapshot, err := client.Snapshot()
+		if err != nil {
+			return nil, err
+		}
+		return snapshot.Projects, nil
+	}
+
+	pd, err := client.GetProjectData(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project %s: %w", projectID, err)
+	}
+	pd.Project.ID = projectID
+	if pd.Project.Name == "" {
+		pd.Project.Name = "Inbox"
+	}
+	return []ticktick.ProjectData{*pd}, nil
+}
+
+// writeExport runs write against the --output file, or stdout when unset.
+func writeExport(cmd *cobra.Command, write func(w io.Writer) error) error {
+	path, _ := cmd.Flags().GetString("output")
+	if path == "" {
+		return write(os.Stdout)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return f.Close()
+}
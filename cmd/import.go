@@ -0,0 +1,373 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import tasks from other tools",
+}
+
+var importICSCmd = &cobra.Command{
+	Use:   "ics <file>",
+	Short: "Import VTODOs from an iCalendar (.ics) file",
+	Long:  "Import VTODOs from an iCalendar (.ics) file. Use - to read from stdin. UIDs that were already imported are skipped.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := readInputFile(args[0])
+		if err != nil {
+			return err
+		}
+
+		todos, err := ticktick.ParseICS(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("failed to parse calendar: %w", err)
+		}
+
+		client, err := ticktick.NewClient()
+		if err != nil {
+			return err
+		}
+
+		projectID, err := resolveProjectFlag(cmd, client)
+		if err == nil && projectID == "" {
+			projectID, err = findDefaultProjectID(client)
+		}
+		if err != nil {
+			return err
+		}
+		includeCompleted, _ := cmd.Flags().GetBool("include-completed")
+
+		imported, err := ticktick.LoadICSImports()
+		if err != nil {
+			return fmt.Errorf("failed to load import history: %w", err)
+		}
+
+		// record saves the import history after every change, so an
+		// interrupted run neither creates tasks again nor leaves them open
+		record := func(uid string, rec ticktick.ICSImport) error {
+			if uid == "" {
+				return nil
+			}
+			imported[uid] = rec
+			if err := ticktick.SaveICSImports(imported); err != nil {
+				return fmt.Errorf("failed to save import history: %w", err)
+			}
+			return nil
+		}
+
+		var results []importResult
+		var failed int
+		for _, todo := range todos {
+			res := importResult{Ref: todo.UID, Title: todo.Summary}
+			rec, seen := imported[todo.UID]
+			switch {
+			case todo.UID != "" && seen && !rec.PendingComplete:
+				res.TaskID = rec.TaskID
+				res.Status = "skipped"
+			case todo.UID != "" && seen:
+				// Created by an earlier run whose completion failed
+				res.TaskID = rec.TaskID
+				if err := client.CompleteTask(rec.ProjectID, rec.TaskID); err != nil {
+					res.Status = "failed"
+					res.Error = fmt.Sprintf("failed to complete: %v", err)
+					failed++
+					break
+				}
+				rec.PendingComplete = false
+				if err := record(todo.UID, rec); err != nil {
+					return err
+				}
+				res.Status = "completed"
+			case todo.Completed() && !includeCompleted:
+				res.Status = "skipped"
+			default:
+				task, err := client.CreateTask(todo.CreateRequest(projectID))
+				if err != nil {
+					res.Status = "failed"
+					res.Error = err.Error()
+					failed++
+					break
+				}
+				res.TaskID = task.ID
+				rec = ticktick.ICSImport{TaskID: task.ID, ProjectID: task.ProjectID, PendingComplete: todo.Completed()}
+				if err := record(todo.UID, rec); err != nil {
+					return err
+				}
+				if todo.Completed() {
+					if err := client.CompleteTask(task.ProjectID, task.ID); err != nil {
+						res.Status = "failed"
+						res.Error = fmt.Sprintf("created but failed to complete (retried on the next import): %v", err)
+						failed++
+						break
+					}
+					rec.PendingComplete = false
+					if err := record(todo.UID, rec); err != nil {
+						return err
+					}
+				}
+				res.Status = "created"
+			}
+			results = append(results, res)
+		}
+
+		if err := printImportResults(results); err != nil {
+			return err
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d tasks failed to import", failed, len(results))
+		}
+		return nil
+	},
+}
+
+var importTodoTxtCmd = &cobra.Command{
+	Use:   "todotxt <file>",
+	Short: "Import tasks from a todo.txt file",
+	Long:  "Import tasks from a todo.txt file. Use - to read from stdin. +project tokens select the target project by name; lines without one go to --project.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := readInputFile(args[0])
+		if err != nil {
+			return err
+		}
+
+		items, err := ticktick.ParseTodoTxt(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("failed to parse todo.txt: %w", err)
+		}
+
+		client, err := ticktick.NewClient()
+		if err != nil {
+			return err
+		}
+
+		projects, err := client.GetProjects()
+		if err != nil {
+			return fmt.Errorf("failed to list projects: %w", err)
+		}
+		projectIDs := make(map[string]string)
+		for _, p := range projects {
+			projectIDs[strings.ToLower(ticktick.TodoTxtProjectName(p.Name))] = p.ID
+		}
+
+		defaultProjectID, err := resolveProjectFlag(cmd, client)
+		if err == nil && defaultProjectID == "" {
+			defaultProjectID, err = findDefaultProjectID(client)
+		}
+		if err != nil {
+			return err
+		}
+
+		var results []importResult
+		var failed int
+		for i, item := range items {
+			res := importResult{Ref: fmt.Sprintf("line %d", i+1), Title: item.Title}
+
+			projectID := defaultProjectID
+			if item.Project != "" {
+				name := strings.ToLower(item.Project)
+				switch id, ok := projectIDs[name]; {
+				case ok:
+					projectID = id
+				case name == "inbox":
+					projectID, err = findInboxID(client)
+					if err != nil {
+						return err
+					}
+				default:
+					fmt.Fprintf(os.Stderr, "Warning: unknown project +%s, using default project\n", item.Project)
+				}
+			}
+
+			req, err := item.CreateRequest(projectID)
+			var task *ticktick.Task
+			if err == nil {
+				task, err = createImportedTask(client, req, item.Completed)
+			}
+			if err != nil {
+				res.Status = "failed"
+				res.Error = err.Error()
+				failed++
+			} else {
+				res.TaskID = task.ID
+				res.Status = "created"
+			}
+			results = append(results, res)
+		}
+
+		if err := printImportResults(results); err != nil {
+			return err
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d tasks failed to import", failed, len(results))
+		}
+		return nil
+	},
+}
+
+var importMarkdownCmd = &cobra.Command{
+	Use:   "markdown <file>",
+	Short: "Import tasks from Markdown checklist lines",
+	Long:  "Import \"- [ ]\" and \"- [x]\" lines from a Markdown file as tasks. Use - to read from stdin. Indented checkboxes become checklist items of the task above them.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := readInputFile(args[0])
+		if err != nil {
+			return err
+		}
+
+		mdTasks, err := ticktick.ParseMarkdownChecklist(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("failed to parse markdown: %w", err)
+		}
+
+		client, err := ticktick.NewClient()
+		if err != nil {
+			return err
+		}
+
+		projectID, err := resolveProjectFlag(cmd, client)
+		if err == nil && projectID == "" {
+			projectID, err = findDefaultProjectID(client)
+		}
+		if err != nil {
+			return err
+		}
+		skipCompleted, _ := cmd.Flags().GetBool("skip-completed")
+
+		var results []importResult
+		var failed int
+		for i, mt := range mdTasks {
+			res := importResult{Ref: fmt.Sprintf("item %d", i+1), Title: mt.Title}
+			if mt.Completed && skipCompleted {
+				res.Status = "skipped"
+				results = append(results, res)
+				continue
+			}
+
+			req, err := mt.CreateRequest(projectID)
+			var task *ticktick.Task
+			if err == nil {
+				task, err = createImportedTask(client, req, mt.Completed)
+			}
+			if err != nil {
+				res.Status = "failed"
+				res.Error = err.Error()
+				failed++
+			} else {
+				res.TaskID = task.ID
+				res.Status = "created"
+			}
+			results = append(results, res)
+		}
+
+		if err := printImportResults(results); err != nil {
+			return err
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d tasks failed to import", failed, len(results))
+		}
+		return nil
+	},
+}
+
+func init() {
+	importICSCmd.Flags().String("project", "", "Target project name or ID (default: the default_project setting, or Inbox)")
+	importICSCmd.Flags().Bool("include-completed", false, "Also import completed VTODOs (created, then marked complete)")
+
+	importTodoTxtCmd.Flags().String("project", "", "Project name or ID for lines without a +project (default: the default_project setting, or Inbox)")
+
+	importMarkdownCmd.Flags().String("project", "", "Target project name or ID (default: the default_project setting, or Inbox)")
+	importMarkdownCmd.Flags().Bool("skip-completed", false, "Skip checked \"- [x]\" lines")
+
+	importCmd.AddCommand(importICSCmd)
+	importCmd.AddCommand(importTodoTxtCmd)
+	importCmd.AddCommand(importMarkdownCmd)
+	rootCmd.AddCommand(importCmd)
+}
+
+// readInputFile reads the named file, or stdin when name is "-".
+func readInputFile(name string) ([]byte, error) {
+	if name == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read stdin: %w", err)
+		}
+		return data, nil
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return data, nil
+}
+
+// importResult is the per-task outcome reported by import commands.
+type importResult struct {
+	Ref    string `json:"ref"`
+	TaskID string `json:"task_id,omitempty"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// printImportResults writes import results in the selected output format.
+func printImportResults(results []importResult) error {
+	if outputJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	}
+
+	if outputPlain {
+		for _, r := range results {
+			fmt.Printf("%s\t%s\t%s\t%s\n", r.Ref, r.TaskID, r.Status, r.Title)
+		}
+		return nil
+	}
+
+	var created, skipped, failed int
+	for _, r := range results {
+		switch r.Status {
+		case "created", "completed":
+			created++
+		case "skipped":
+			skipped++
+		case "failed":
+			failed++
+			fmt.Fprintf(os.Stderr, "Failed to import %q: %s\n", r.Title, r.Error)
+		}
+	}
+	fmt.Printf("Imported %d tasks (%d skipped, %d failed)\n", created, skipped, failed)
+	return nil
+}
+
+// createImportedTask creates a task, completing it afterwards when the
+// imported entry is marked done. A failed completion only warns, since the
+// task exists and importing it again would create a duplicate.
+func createImportedTask(client *ticktick.Client, req *ticktick.TaskCreateRequest, completed bool) (*ticktick.Task, error) {
+	task, err := client.CreateTask(req)
+	if err != nil {
+		return nil, err
+	}
+	if completed {
+		if err := client.CompleteTask(task.ProjectID, task.ID); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: created %q but failed to complete it: %v\n", task.Title, err)
+		}
+	}
+	return task, nil
+}
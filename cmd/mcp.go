@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/tackeyy/ticky/internal/mcp"
+	"github.com/tackeyy/ticky/internal/ticktick"
+
+	"github.com/spf13/cobra"
+)
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Model Context Protocol server for AI agents",
+}
+
+var mcpServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve TickTick tools over MCP on stdin/stdout",
+	Long: `Serve the Model Context Protocol (JSON-RPC 2.0) on stdin and stdout so AI
+agents can call TickTick tools directly instead of parsing command output.
+Register "ticky mcp serve" as a stdio server in the agent's MCP settings.
+
+Tools: list_projects, list_tasks, search_tasks, create_task, update_task,
+complete_task, delete_task and list_tags. Failed calls are returned as tool
+results with isError set, so the agent sees the message.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := ticktick.NewClient()
+		if err != nil {
+			return err
+		}
+		if err := mcp.NewServer(client, version).Serve(os.Stdin, os.Stdout); err != nil {
+			return fmt.Errorf("mcp server failed: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	mcpCmd.AddCommand(mcpServeCmd)
+	rootCmd.AddCommand(mcpCmd)
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+
+	"github.com/spf13/cobra"
+)
+
+// selectProfile activates the profile given by --profile, TICKY_PROFILE or
+// "profile use", in that order.
+func selectProfile() error {
+	name := profileName
+	if name == "" {
+		name = os.Getenv("TICKY_PROFILE")
+	}
+	if name == "" {
+		var err error
+		if name, err = ticktick.LoadDefaultProfile(); err != nil {
+			return err
+		}
+	}
+	return ticktick.SetProfile(name)
+}
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage account profiles",
+	Long: `Manage account profiles. Each profile has its own token, client credentials,
+cached Inbox ID and config. Select one per command with --profile or
+TICKY_PROFILE, or make it the default with "profile use".`,
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List profiles",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profiles, err := ticktick.ListProfiles()
+		if err != nil {
+			return err
+		}
+		active := ticktick.ActiveProfile()
+
+		if outputJSON {
+			type listedProfile struct {
+				ticktick.ProfileInfo
+				Active bool `json:"active"`
+			}
+			listed := make([]listedProfile, len(profiles))
+			for i, p := range profiles {
+				listed[i] = listedProfile{ProfileInfo: p, Active: p.Name == active}
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(listed)
+		}
+
+		if outputPlain {
+			for _, p := range profiles {
+				loggedIn := "unknown"
+				if p.LoggedIn != nil {
+					loggedIn = strconv.FormatBool(*p.LoggedIn)
+				}
+				fmt.Printf("%s\t%t\t%s\t%s\n", p.Name, p.Name == active, loggedIn, p.Dir)
+			}
+			return nil
+		}
+
+		for _, p := range profiles {
+			marker := " "
+			if p.Name == active {
+				marker = "*"
+			}
+			status := "not logged in"
+			switch {
+			case p.LoggedIn == nil:
+				status = fmt.Sprintf("token kept by credential helper %q", p.CredentialHelper)
+			case *p.LoggedIn:
+				status = "logged in"
+			}
+			fmt.Printf("%s %-16s %s\n", marker, p.Name, status)
+		}
+		return nil
+	},
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Set the default profile",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if err := ticktick.SaveDefaultProfile(name); err != nil {
+			return err
+		}
+
+		fmt.Printf("Default profile set to %s\n", name)
+		if err := ticktick.SetProfile(name); err == nil {
+			if p := ticktick.GetProfile(name); p.LoggedIn != nil && !*p.LoggedIn {
+				fmt.Printf("Profile %s is not logged in yet; run \"ticky auth login --profile %s\"\n", name, name)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	profileCmd.AddCommand(profileListCmd)
+	profileCmd.AddCommand(profileUseCmd)
+	rootCmd.AddCommand(profileCmd)
+}
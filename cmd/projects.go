@@ -50,7 +50,8 @@ var projectsListCmd = &cobra.Command{
 }
 
 var projectsGetCmd = &cobra.Command{
-	Use:   "get <project_id>",
+	Use:   "get <project>",
+	Long:  "Get project details. The project may be given by name or ID.",
 	Short: "Get project details",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -59,7 +60,12 @@ var projectsGetCmd = &cobra.Command{
 			return err
 		}
 
-		project, err := client.GetProject(args[0])
+		projectID, err := client.ResolveProject(args[0])
+		if err != nil {
+			return err
+		}
+
+		project, err := client.GetProject(projectID)
 		if err != nil {
 			return fmt.Errorf("failed to get project: %w", err)
 		}
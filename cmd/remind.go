@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+
+	"github.com/spf13/cobra"
+)
+
+var remindCmd = &cobra.Command{
+	Use:   "remind",
+	Short: "Task reminders",
+}
+
+var remindDaemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run a command when tasks are due or their reminders go off",
+	Long: `Track the due dates and reminders of open tasks and run a command at the
+right time, with the task in TICKY_* environment variables:
+
+  TICKY_NOTIFY_KIND      due or reminder
+  TICKY_NOTIFY_AT        when the notification was due (RFC 3339)
+  TICKY_NOTIFY_LATE      true when it fires late, e.g. after a restart
+  TICKY_TASK_ID, TICKY_TASK_PROJECT_ID, TICKY_TASK_TITLE, TICKY_TASK_CONTENT,
+  TICKY_TASK_DUE, TICKY_TASK_ALL_DAY, TICKY_TASK_PRIORITY, TICKY_TASK_TAGS
+
+Tasks with reminders are announced at each reminder, other tasks when they
+are due (all-day tasks at --all-day-at). Fired notifications are recorded in
+remind_state.json in the profile directory, so a restarted daemon neither
+repeats them nor misses those that came due while it was stopped, up to
+--catch-up ago.
+
+The command is run by the shell; it defaults to the remind_command setting.`,
+	Example: `  ticky remind daemon --command 'notify-send "$TICKY_TASK_TITLE" "Due $TICKY_TASK_DUE"'
+  ticky config set remind_command 'tmux display-message "ticky: $TICKY_TASK_TITLE"'`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		command, _ := cmd.Flags().GetString("command")
+		refresh, _ := cmd.Flags().GetDuration("refresh")
+		catchUp, _ := cmd.Flags().GetDuration("catch-up")
+		allDayAtStr, _ := cmd.Flags().GetString("all-day-at")
+		projects, _ := cmd.Flags().GetStringArray("project")
+
+		if command == "" {
+			command = cfg.RemindCommand
+		}
+		if command == "" {
+			return fmt.Errorf("no command to run: pass --command or set remind_command")
+		}
+		if refresh < minWatchInterval {
+			return fmt.Errorf("--refresh must be at least %s", minWatchInterval)
+		}
+		clock, err := time.Parse("15:04", allDayAtStr)
+		if err != nil {
+			return fmt.Errorf("invalid --all-day-at %q: use HH:MM", allDayAtStr)
+		}
+		allDayAt := time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute
+
+		client, err := ticktick.NewClient()
+		if err != nil {
+			return err
+		}
+		var projectIDs []string
+		for _, p := range projects {
+			id, err := client.ResolveProject(p)
+			if err != nil {
+				return err
+			}
+			projectIDs = append(projectIDs, id)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		statePath := ticktick.RemindStatePath()
+		fire := func(n ticktick.Notification) {
+			if err := ticktick.RunReminderCommand(command, n, time.Now()); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %s %s: %v\n", n.Kind, n.Task.Title, err)
+			}
+		}
+
+		var notes []ticktick.Notification
+		var nextRefresh time.Time
+		for {
+			if now := time.Now(); !now.Before(nextRefresh) {
+				tasks, err := openTasks(client, projectIDs)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: failed to refresh tasks: %v\n", err)
+				} else {
+					notes = ticktick.Notifications(tasks, allDayAt)
+				}
+				nextRefresh = now.Add(refresh)
+			}
+
+			wake := nextRefresh
+			next, err := ticktick.FireNotifications(statePath, notes, time.Now(), catchUp, fire)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+			if !next.IsZero() && next.Before(wake) {
+				wake = next
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(time.Until(wake)):
+			}
+		}
+	},
+}
+
+// openTasks returns the open tasks of the given projects, or of all projects
+// when none are given.
+func openTasks(client *ticktick.Client, projectIDs []string) ([]ticktick.Task, error) {
+	if len(projectIDs) == 0 {
+		var err error
+		if projectIDs, err = client.GetAllProjectIDs(); err != nil {
+			return nil, fmt.Errorf("failed to list projects: %w", err)
+		}
+	}
+	var tasks []ticktick.Task
+	for _, id := range projectIDs {
+		pd, err := client.GetProjectData(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get project %s: %w", id, err)
+		}
+		tasks = append(tasks, pd.Tasks...)
+	}
+	return tasks, nil
+}
+
+func init() {
+	remindDaemonCmd.Flags().String("command", "", "Shell command to run for each notification (default: the remind_command setting)")
+	remindDaemonCmd.Flags().Duration("refresh", 5*time.Minute, "How often tasks are fetched")
+	remindDaemonCmd.Flags().Duration("catch-up", 24*time.Hour, "Fire notifications missed up to this long ago (at least 1m)")
+	remindDaemonCmd.Flags().String("all-day-at", "09:00", "Time of day to announce all-day tasks without reminders")
+	remindDaemonCmd.Flags().StringArray("project", nil, "Only track this project, by name or ID (repeatable)")
+
+	remindCmd.AddCommand(remindDaemonCmd)
+	rootCmd.AddCommand(remindCmd)
+}
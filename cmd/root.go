@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/tackeyy/ticky/internal/ticktick"
 )
 
 var (
@@ -13,12 +14,19 @@ var (
 	date        = "unknown"
 	outputJSON  bool
 	outputPlain bool
+	profileName string
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "ticky",
 	Short: "TickTick CLI tool",
 	Long:  "ticky — A CLI tool for TickTick task management. Designed for both human use and AI agent integration.",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := selectProfile(); err != nil {
+			return err
+		}
+		return loadConfig(cmd)
+	},
 }
 
 func Execute() {
@@ -31,6 +39,8 @@ func Execute() {
 func init() {
 	rootCmd.PersistentFlags().BoolVar(&outputJSON, "json", false, "Output in JSON format")
 	rootCmd.PersistentFlags().BoolVar(&outputPlain, "plain", false, "Output in TSV format")
+	rootCmd.PersistentFlags().BoolVar(&ticktick.HooksDisabled, "no-hooks", false, "Do not run the configured hooks (also $TICKY_NO_HOOKS)")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "Account profile to use (default: $TICKY_PROFILE, then the profile set with \"profile use\")")
 	rootCmd.Version = version
 	rootCmd.SetVersionTemplate(fmt.Sprintf("ticky version %s (commit: %s, built: %s)\n", version, commit, date))
 }
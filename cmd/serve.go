@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/tackeyy/ticky/internal/api"
+	"github.com/tackeyy/ticky/internal/ticktick"
+
+	"github.com/spf13/cobra"
+)
+
+// apiTokenFile holds the bearer token of "ticky serve" in the profile directory.
+const apiTokenFile = "api_token"
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve a local JSON HTTP API",
+	Long: `Serve a small JSON HTTP API backed by the TickTick account of the active
+profile, so local services can read and change tasks without implementing
+OAuth themselves.
+
+Every request needs "Authorization: Bearer <token>". The token is generated
+on first start and stored with 0600 permissions in the profile directory;
+--rotate-token replaces it. Requests are logged to stderr.
+
+Endpoints:
+  GET   /tasks                 open tasks; ?project=, ?where=, ?text=
+  POST  /tasks                 create a task
+  PATCH /tasks/{id}            update the given fields of a task
+  POST  /tasks/{id}/complete   complete a task
+  GET   /projects              projects
+  GET   /tags                  tags with task counts`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr, _ := cmd.Flags().GetString("addr")
+		rotate, _ := cmd.Flags().GetBool("rotate-token")
+
+		client, err := ticktick.NewClient()
+		if err != nil {
+			return err
+		}
+		tokenPath := filepath.Join(ticktick.ProfileDir(), apiTokenFile)
+		token, err := api.LoadOrCreateToken(tokenPath, rotate)
+		if err != nil {
+			return err
+		}
+
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", addr, err)
+		}
+		if host, _, _ := net.SplitHostPort(addr); !isLoopback(host) {
+			fmt.Fprintf(os.Stderr, "Warning: %s is reachable from other machines; anyone with the token can change your tasks\n", addr)
+		}
+
+		logger := log.New(os.Stderr, "", log.LstdFlags)
+		server := &http.Server{
+			Handler:           api.NewServer(client, token, logger).Handler(),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			server.Shutdown(shutdownCtx)
+		}()
+
+		fmt.Fprintf(os.Stderr, "Serving the ticky API on http://%s (profile: %s)\n", listener.Addr(), ticktick.ActiveProfile())
+		fmt.Fprintf(os.Stderr, "Bearer token: %s\n", tokenPath)
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("server failed: %w", err)
+		}
+		return nil
+	},
+}
+
+// isLoopback reports whether host only accepts local connections.
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func init() {
+	serveCmd.Flags().String("addr", "127.0.0.1:8787", "Address to listen on")
+	serveCmd.Flags().Bool("rotate-token", false, "Generate a new bearer token, invalidating the old one")
+	rootCmd.AddCommand(serveCmd)
+}
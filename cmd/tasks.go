@@ -25,9 +25,12 @@ var tasksListCmd = &cobra.Command{
 			return err
 		}
 
-		projectID, _ := cmd.Flags().GetString("project")
+		projectID, err := resolveProjectFlag(cmd, client)
+		if err != nil {
+			return err
+		}
 		if projectID == "" {
-			projectID, err = findInboxID(client)
+			projectID, err = findDefaultProjectID(client)
 			if err != nil {
 				return err
 			}
@@ -37,20 +40,33 @@ var tasksListCmd = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("failed to list tasks: %w", err)
 		}
+		handles, err := ticktick.AssignHandles(pd.Tasks)
+		if err != nil {
+			return fmt.Errorf("failed to assign task handles: %w", err)
+		}
 
 		if outputJSON {
+			type listedTask struct {
+				ticktick.Task
+				Handle int `json:"handle"`
+			}
+			listed := make([]listedTask, len(pd.Tasks))
+			for i, t := range pd.Tasks {
+				listed[i] = listedTask{Task: t, Handle: handles[t.ID]}
+			}
 			enc := json.NewEncoder(os.Stdout)
 			enc.SetIndent("", "  ")
-			return enc.Encode(pd.Tasks)
+			return enc.Encode(listed)
 		}
 
 		if outputPlain {
+			// The handle comes last so the output still pipes into "-" refs
 			for _, t := range pd.Tasks {
 				tags := strings.Join(t.Tags, ",")
-				fmt.Printf("%s\t%s\t%s\t%s\t%s\t%s\n",
+				fmt.Printf("%s\t%s\t%s\t%s\t%s\t%s\t#%d\n",
 					t.ID, t.ProjectID, t.Title,
 					ticktick.PriorityString(t.Priority),
-					t.DueDate, tags)
+					t.DueDate, tags, handles[t.ID])
 			}
 			return nil
 		}
@@ -66,20 +82,21 @@ var tasksListCmd = &cobra.Command{
 			}
 			due := ""
 			if t.DueDate != "" {
-				due = fmt.Sprintf(" (due: %s)", t.DueDate[:10])
+				due = fmt.Sprintf(" (due: %s)", formatDue(t.DueDate))
 			}
 			tags := ""
 			if len(t.Tags) > 0 {
 				tags = fmt.Sprintf(" #%s", strings.Join(t.Tags, " #"))
 			}
-			fmt.Printf("%-24s %s%s%s%s\n", t.ID, t.Title, priority, due, tags)
+			handle := fmt.Sprintf("#%d", handles[t.ID])
+			fmt.Printf("%-5s %-24s %s%s%s%s\n", handle, t.ID, t.Title, priority, due, tags)
 		}
 		return nil
 	},
 }
 
 var tasksGetCmd = &cobra.Command{
-	Use:   "get <task_id>",
+	Use:   "get <task>",
 	Short: "Get task details",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -88,12 +105,20 @@ var tasksGetCmd = &cobra.Command{
 			return err
 		}
 
-		projectID, _ := cmd.Flags().GetString("project")
+		projectID, err := resolveProjectFlag(cmd, client)
+		if err != nil {
+			return err
+		}
+		taskID, err := client.ResolveTaskRef(args[0])
+		if err != nil {
+			return err
+		}
+		var task *ticktick.Task
 		if projectID == "" {
-			return fmt.Errorf("--project is required for get")
+			task, err = client.LocateTask(taskID)
+		} else {
+			task, err = client.GetTask(projectID, taskID)
 		}
-
-		task, err := client.GetTask(projectID, args[0])
 		if err != nil {
 			return fmt.Errorf("failed to get task: %w", err)
 		}
@@ -121,7 +146,7 @@ var tasksGetCmd = &cobra.Command{
 		}
 		fmt.Printf("Priority: %s\n", ticktick.PriorityString(task.Priority))
 		if task.DueDate != "" {
-			fmt.Printf("Due:      %s\n", task.DueDate)
+			fmt.Printf("Due:      %s\n", formatDue(task.DueDate))
 		}
 		if len(task.Tags) > 0 {
 			fmt.Printf("Tags:     %s\n", strings.Join(task.Tags, ", "))
@@ -144,11 +169,26 @@ var tasksCreateCmd = &cobra.Command{
 			return fmt.Errorf("--title is required")
 		}
 
-		projectID, _ := cmd.Flags().GetString("project")
+		projectID, err := resolveProjectFlag(cmd, client)
+		if err != nil {
+			return err
+		}
+		if projectID == "" && cfg.DefaultProject != "" {
+			projectID, err = client.ResolveProject(cfg.DefaultProject)
+			if err != nil {
+				return err
+			}
+		}
 		content, _ := cmd.Flags().GetString("content")
 		priorityStr, _ := cmd.Flags().GetString("priority")
 		dueStr, _ := cmd.Flags().GetString("due")
 		tagsStr, _ := cmd.Flags().GetString("tags")
+		if !cmd.Flags().Changed("priority") {
+			priorityStr = cfg.DefaultPriority
+		}
+		if !cmd.Flags().Changed("tags") && len(cfg.DefaultTags) > 0 {
+			tagsStr = strings.Join(cfg.DefaultTags, ",")
+		}
 
 		req := &ticktick.TaskCreateRequest{
 			Title:     title,
@@ -198,41 +238,186 @@ var tasksCreateCmd = &cobra.Command{
 }
 
 var tasksUpdateCmd = &cobra.Command{
-	Use:   "update <task_id>",
-	Short: "Update an existing task",
-	Args:  cobra.ExactArgs(1),
+	Use:   "update <task>... | -",
+	Short: "Update one or more existing tasks",
+	Long:  "Update one or more existing tasks. Pass task IDs as arguments, - to read \"id<TAB>projectId\" lines from stdin (the --plain output of tasks list), or select tasks with --where.",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		client, err := ticktick.NewClient()
+		apply, err := parseUpdateFlags(cmd)
 		if err != nil {
 			return err
 		}
 
-		projectID, _ := cmd.Flags().GetString("project")
-		if projectID == "" {
-			return fmt.Errorf("--project is required for update")
+		return runBulkTasks(cmd, args, bulkOp{
+			action: "update",
+			done:   "updated",
+			run: func(client *ticktick.Client, ref ticktick.TaskRef) (*ticktick.Task, error) {
+				// Fetch existing task first
+				existing, err := client.GetTask(ref.ProjectID, ref.ID)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get existing task: %w", err)
+				}
+				return client.UpdateTask(apply(existing))
+			},
+		})
+	},
+}
+
+var tasksCompleteCmd = &cobra.Command{
+	Use:   "complete <task>... | -",
+	Short: "Mark one or more tasks as complete",
+	Long:  "Mark one or more tasks as complete. Pass task IDs as arguments, - to read \"id<TAB>projectId\" lines from stdin (the --plain output of tasks list), or select tasks with --where.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runBulkTasks(cmd, args, bulkOp{
+			action: "complete",
+			done:   "completed",
+			run: func(client *ticktick.Client, ref ticktick.TaskRef) (*ticktick.Task, error) {
+				return nil, client.CompleteTask(ref.ProjectID, ref.ID)
+			},
+		})
+	},
+}
+
+var tasksDeleteCmd = &cobra.Command{
+	Use:   "delete <task>... | -",
+	Short: "Delete one or more tasks",
+	Long:  "Delete one or more tasks. Pass task IDs as arguments, - to read \"id<TAB>projectId\" lines from stdin (the --plain output of tasks list), or select tasks with --where.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runBulkTasks(cmd, args, bulkOp{
+			action: "delete",
+			done:   "deleted",
+			run: func(client *ticktick.Client, ref ticktick.TaskRef) (*ticktick.Task, error) {
+				return nil, client.DeleteTask(ref.ProjectID, ref.ID)
+			},
+		})
+	},
+}
+
+var tasksApplyCmd = &cobra.Command{
+	Use:   "apply -f <file>",
+	Short: "Create tasks from a YAML or JSON manifest",
+	Long:  "Create tasks from a YAML or JSON manifest. Each task carries a key stored in its content, so tasks whose key already exists as an open task in the target project are skipped on re-runs.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, _ := cmd.Flags().GetString("file")
+		if file == "" {
+			return fmt.Errorf("--file is required")
 		}
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
 
-		// Fetch existing task first
-		existing, err := client.GetTask(projectID, args[0])
+		data, err := readInputFile(file)
 		if err != nil {
-			return fmt.Errorf("failed to get existing task: %w", err)
+			return err
+		}
+		manifest, err := ticktick.ParseManifest(data)
+		if err != nil {
+			return err
 		}
 
-		req := &ticktick.TaskUpdateRequest{
-			ID:        args[0],
-			ProjectID: projectID,
-			Title:     existing.Title,
-			Content:   existing.Content,
-			Tags:      existing.Tags,
+		client, err := ticktick.NewClient()
+		if err != nil {
+			return err
+		}
+		return applyManifest(client, manifest, dryRun)
+	},
+}
+
+func init() {
+	tasksListCmd.Flags().String("project", "", "Project name or ID (default: the default_project setting, or Inbox)")
+
+	tasksGetCmd.Flags().String("project", "", "Project name or ID (looked up from the task ID when omitted)")
+
+	tasksCreateCmd.Flags().String("title", "", "Task title (required)")
+	tasksCreateCmd.Flags().String("project", "", "Project name or ID (default: the default_project setting, or Inbox)")
+	tasksCreateCmd.Flags().String("content", "", "Task content/description")
+	tasksCreateCmd.Flags().String("priority", "", "Priority: none, low, medium, high (default: the default_priority setting)")
+	tasksCreateCmd.Flags().String("due", "", "Due date: today, tomorrow, +3d, YYYY-MM-DD")
+	tasksCreateCmd.Flags().String("tags", "", "Comma-separated tags (default: the default_tags setting)")
+
+	tasksUpdateCmd.Flags().String("project", "", "Project name or ID (looked up from the task IDs when omitted)")
+	tasksUpdateCmd.Flags().String("title", "", "New title")
+	tasksUpdateCmd.Flags().String("content", "", "New content")
+	tasksUpdateCmd.Flags().String("priority", "", "Priority: none, low, medium, high")
+	tasksUpdateCmd.Flags().String("due", "", "Due date: today, tomorrow, +3d, YYYY-MM-DD")
+	tasksUpdateCmd.Flags().Bool("clear-due", false, "Clear the due date")
+	tasksUpdateCmd.Flags().String("tags", "", "Replace all tags (comma-separated)")
+	tasksUpdateCmd.Flags().String("add-tags", "", "Add tags (comma-separated)")
+	tasksUpdateCmd.Flags().String("remove-tags", "", "Remove tags (comma-separated)")
+
+	tasksCompleteCmd.Flags().String("project", "", "Project name or ID (looked up from the task IDs when omitted)")
+
+	tasksDeleteCmd.Flags().String("project", "", "Project name or ID (looked up from the task IDs when omitted)")
+
+	tasksApplyCmd.Flags().StringP("file", "f", "", "Manifest file (YAML or JSON), or - for stdin (required)")
+	tasksApplyCmd.Flags().Bool("dry-run", false, "Show what would be created without creating anything")
+
+	for _, c := range []*cobra.Command{tasksUpdateCmd, tasksCompleteCmd, tasksDeleteCmd} {
+		c.Flags().String("where", "", "Select tasks by filter, e.g. \"tag=work priority>=medium due<today\"")
+		c.Flags().Int("concurrency", 4, "Maximum number of tasks processed in parallel")
+	}
+
+	tasksCmd.AddCommand(tasksListCmd)
+	tasksCmd.AddCommand(tasksGetCmd)
+	tasksCmd.AddCommand(tasksCreateCmd)
+	tasksCmd.AddCommand(tasksUpdateCmd)
+	tasksCmd.AddCommand(tasksCompleteCmd)
+	tasksCmd.AddCommand(tasksDeleteCmd)
+	tasksCmd.AddCommand(tasksApplyCmd)
+	rootCmd.AddCommand(tasksCmd)
+}
+
+// findInboxID finds the Inbox project ID.
+func findInboxID(client *ticktick.Client) (string, error) {
+	return client.DiscoverInboxID()
+}
+
+// resolveProjectFlag resolves the --project flag, which may be a project
+// name, ID or "inbox", to a project ID. It returns "" when the flag is unset.
+func resolveProjectFlag(cmd *cobra.Command, client *ticktick.Client) (string, error) {
+	ref, _ := cmd.Flags().GetString("project")
+	return client.ResolveProject(ref)
+}
+
+func containsStr(slice []string, s string) bool {
+	for _, v := range slice {
+		if strings.TrimSpace(v) == strings.TrimSpace(s) {
+			return true
 		}
+	}
+	return false
+}
 
-		p := existing.Priority
-		req.Priority = &p
+// parseUpdateFlags validates the update flags once and returns a function
+// that builds the update request for an existing task.
+func parseUpdateFlags(cmd *cobra.Command) (func(existing *ticktick.Task) *ticktick.TaskUpdateRequest, error) {
+	var priority *int
+	if cmd.Flags().Changed("priority") {
+		priorityStr, _ := cmd.Flags().GetString("priority")
+		pVal, err := ticktick.ParsePriority(priorityStr)
+		if err != nil {
+			return nil, err
+		}
+		priority = &pVal
+	}
 
-		if existing.DueDate != "" {
-			d := existing.DueDate
-			req.DueDate = &d
+	var due *string
+	if cmd.Flags().Changed("due") {
+		dueStr, _ := cmd.Flags().GetString("due")
+		d, err := ticktick.ParseDate(dueStr)
+		if err != nil {
+			return nil, err
 		}
+		due = &d
+	}
+	if cmd.Flags().Changed("clear-due") {
+		clearDue, _ := cmd.Flags().GetBool("clear-due")
+		if clearDue {
+			empty := ""
+			due = &empty
+		}
+	}
+
+	return func(existing *ticktick.Task) *ticktick.TaskUpdateRequest {
+		req := existing.UpdateRequest()
 
 		if cmd.Flags().Changed("title") {
 			title, _ := cmd.Flags().GetString("title")
@@ -242,28 +427,11 @@ var tasksUpdateCmd = &cobra.Command{
 			content, _ := cmd.Flags().GetString("content")
 			req.Content = content
 		}
-		if cmd.Flags().Changed("priority") {
-			priorityStr, _ := cmd.Flags().GetString("priority")
-			pVal, err := ticktick.ParsePriority(priorityStr)
-			if err != nil {
-				return err
-			}
-			req.Priority = &pVal
-		}
-		if cmd.Flags().Changed("due") {
-			dueStr, _ := cmd.Flags().GetString("due")
-			due, err := ticktick.ParseDate(dueStr)
-			if err != nil {
-				return err
-			}
-			req.DueDate = &due
+		if priority != nil {
+			req.Priority = priority
 		}
-		if cmd.Flags().Changed("clear-due") {
-			clearDue, _ := cmd.Flags().GetBool("clear-due")
-			if clearDue {
-				empty := ""
-				req.DueDate = &empty
-			}
+		if due != nil {
+			req.DueDate = due
 		}
 		if cmd.Flags().Changed("tags") {
 			tagsStr, _ := cmd.Flags().GetString("tags")
@@ -293,149 +461,327 @@ var tasksUpdateCmd = &cobra.Command{
 			}
 			req.Tags = filtered
 		}
+		return req
+	}, nil
+}
 
-		task, err := client.UpdateTask(req)
-		if err != nil {
-			return fmt.Errorf("failed to update task: %w", err)
-		}
-
-		if outputJSON {
-			enc := json.NewEncoder(os.Stdout)
-			enc.SetIndent("", "  ")
-			return enc.Encode(task)
-		}
+// bulkOp describes a per-task operation run by runBulkTasks.
+type bulkOp struct {
+	action string // imperative verb, e.g. "complete"
+	done   string // past tense reported on success, e.g. "completed"
+	run    func(client *ticktick.Client, ref ticktick.TaskRef) (*ticktick.Task, error)
+}
 
-		if outputPlain {
-			fmt.Printf("%s\t%s\n", task.ID, task.ProjectID)
-			return nil
-		}
+// runBulkTasks collects the targeted tasks, runs op on each with bounded
+// concurrency and prints a per-task summary. It returns an error if any task
+// failed so the process exits non-zero on partial failure.
+func runBulkTasks(cmd *cobra.Command, args []string, op bulkOp) error {
+	where, _ := cmd.Flags().GetString("where")
+	if len(args) == 0 && where == "" {
+		return fmt.Errorf("specify task IDs, - to read from stdin, or --where")
+	}
 
-		fmt.Printf("Updated task: %s (ID: %s)\n", task.Title, task.ID)
-		return nil
-	},
-}
+	client, err := ticktick.NewClient()
+	if err != nil {
+		return err
+	}
 
-var tasksCompleteCmd = &cobra.Command{
-	Use:   "complete <task_id>",
-	Short: "Mark a task as complete",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		client, err := ticktick.NewClient()
-		if err != nil {
-			return err
-		}
+	refs, err := collectTaskRefs(cmd, client, args, where)
+	if err != nil {
+		return err
+	}
 
-		projectID, _ := cmd.Flags().GetString("project")
-		if projectID == "" {
-			return fmt.Errorf("--project is required for complete")
-		}
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	results := ticktick.RunBulk(refs, concurrency, func(ref ticktick.TaskRef) (*ticktick.Task, error) {
+		return op.run(client, ref)
+	})
 
-		if err := client.CompleteTask(projectID, args[0]); err != nil {
-			return fmt.Errorf("failed to complete task: %w", err)
+	single := len(args) == 1 && args[0] != "-" && where == ""
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
 		}
+	}
 
-		if outputJSON {
-			enc := json.NewEncoder(os.Stdout)
-			enc.SetIndent("", "  ")
+	if outputJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if single && failed == 0 {
+			if r := results[0]; r.Task != nil {
+				return enc.Encode(r.Task)
+			}
 			return enc.Encode(map[string]string{
-				"status":  "completed",
-				"task_id": args[0],
+				"status":  op.done,
+				"task_id": results[0].ID,
 			})
 		}
-
-		if outputPlain {
-			fmt.Printf("%s\tcompleted\n", args[0])
-			return nil
+		type bulkResult struct {
+			ticktick.TaskRef
+			Status string `json:"status"`
+			Error  string `json:"error,omitempty"`
+		}
+		out := make([]bulkResult, 0, len(results))
+		for _, r := range results {
+			br := bulkResult{TaskRef: r.TaskRef, Status: op.done}
+			if r.Err != nil {
+				br.Status = "failed"
+				br.Error = r.Err.Error()
+			}
+			out = append(out, br)
+		}
+		if err := enc.Encode(out); err != nil {
+			return err
 		}
+	} else {
+		for _, r := range results {
+			switch {
+			case r.Err != nil && outputPlain:
+				fmt.Printf("%s\tfailed\t%s\n", r.ID, r.Err)
+			case r.Err != nil:
+				fmt.Fprintf(os.Stderr, "Failed to %s task %s: %s\n", op.action, r.ID, r.Err)
+			case outputPlain && r.Task != nil:
+				fmt.Printf("%s\t%s\n", r.Task.ID, r.Task.ProjectID)
+			case outputPlain:
+				fmt.Printf("%s\t%s\n", r.ID, op.done)
+			case r.Task != nil:
+				fmt.Printf("Updated task: %s (ID: %s)\n", r.Task.Title, r.Task.ID)
+			default:
+				fmt.Printf("Task %s %s\n", r.ID, op.done)
+			}
+		}
+		if !single && !outputPlain {
+			fmt.Printf("%s %d of %d tasks\n", strings.ToUpper(op.done[:1])+op.done[1:], len(results)-failed, len(results))
+		}
+	}
 
-		fmt.Printf("Task %s completed\n", args[0])
-		return nil
-	},
+	if failed > 0 {
+		if single {
+			return fmt.Errorf("failed to %s task: %w", op.action, results[0].Err)
+		}
+		return fmt.Errorf("%d of %d tasks failed to %s", failed, len(results), op.action)
+	}
+	return nil
 }
 
-var tasksDeleteCmd = &cobra.Command{
-	Use:   "delete <task_id>",
-	Short: "Delete a task",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		client, err := ticktick.NewClient()
+// collectTaskRefs resolves the tasks targeted by a bulk command: task IDs
+// given as arguments, "-" to read refs from stdin, and tasks matching the
+// --where filter (in --project, or across all projects). Handles, aliases
+// and ID prefixes are resolved to task IDs. Tasks without a project, from
+// the arguments or stdin, are looked up via the task index.
+func collectTaskRefs(cmd *cobra.Command, client *ticktick.Client, args []string, where string) ([]ticktick.TaskRef, error) {
+	projectID, err := resolveProjectFlag(cmd, client)
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []ticktick.TaskRef
+	for _, arg := range args {
+		if arg == "-" {
+			stdinRefs, err := ticktick.ParseTaskRefs(os.Stdin)
+			if err != nil {
+				return nil, err
+			}
+			for _, ref := range stdinRefs {
+				if ref.ID, err = client.ResolveTaskRef(ref.ID); err != nil {
+					return nil, err
+				}
+				if ref.ProjectID == "" {
+					ref.ProjectID = projectID
+				}
+				refs = append(refs, ref)
+			}
+			continue
+		}
+		id, err := client.ResolveTaskRef(arg)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		refs = append(refs, ticktick.TaskRef{ID: id, ProjectID: projectID})
+	}
 
-		projectID, _ := cmd.Flags().GetString("project")
-		if projectID == "" {
-			return fmt.Errorf("--project is required for delete")
+	if where != "" {
+		filter, err := ticktick.ParseFilter(where)
+		if err != nil {
+			return nil, err
 		}
-
-		if err := client.DeleteTask(projectID, args[0]); err != nil {
-			return fmt.Errorf("failed to delete task: %w", err)
+		projectIDs := []string{projectID}
+		if projectID == "" {
+			projectIDs, err = client.GetAllProjectIDs()
+			if err != nil {
+				return nil, fmt.Errorf("failed to list projects: %w", err)
+			}
 		}
-
-		if outputJSON {
-			enc := json.NewEncoder(os.Stdout)
-			enc.SetIndent("", "  ")
-			return enc.Encode(map[string]string{
-				"status":  "deleted",
-				"task_id": args[0],
-			})
+		for _, pid := range projectIDs {
+			pd, err := client.GetProjectData(pid)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list tasks: %w", err)
+			}
+			for _, t := range pd.Tasks {
+				if filter.Match(t) {
+					refs = append(refs, ticktick.TaskRef{ID: t.ID, ProjectID: t.ProjectID})
+				}
+			}
 		}
+	}
 
-		if outputPlain {
-			fmt.Printf("%s\tdeleted\n", args[0])
-			return nil
+	// Look up the projects of tasks given without one
+	for i := range refs {
+		if refs[i].ProjectID == "" {
+			if refs[i].ProjectID, err = client.FindTaskProject(refs[i].ID); err != nil {
+				return nil, err
+			}
 		}
-
-		fmt.Printf("Task %s deleted\n", args[0])
-		return nil
-	},
+	}
+	return refs, nil
 }
 
-func init() {
-	tasksListCmd.Flags().String("project", "", "Project ID (default: Inbox)")
+// applyResult is the outcome of one manifest entry in "tasks apply".
+type applyResult struct {
+	Key       string `json:"key"`
+	TaskID    string `json:"task_id,omitempty"`
+	ProjectID string `json:"project_id"`
+	Title     string `json:"title"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+}
 
-	tasksGetCmd.Flags().String("project", "", "Project ID (required)")
+// resolveManifestProjects maps each spec's project to a project ID. Unknown
+// projects are reported before anything is created.
+func resolveManifestProjects(client *ticktick.Client, specs []ticktick.TaskSpec) ([]string, error) {
+	ids := make([]string, len(specs))
+	for i, spec := range specs {
+		id, err := client.ResolveProject(spec.Project)
+		if err == nil && id == "" {
+			id, err = findDefaultProjectID(client)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("task %d: %w", i+1, err)
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
 
-	tasksCreateCmd.Flags().String("title", "", "Task title (required)")
-	tasksCreateCmd.Flags().String("project", "", "Project ID (default: Inbox)")
-	tasksCreateCmd.Flags().String("content", "", "Task content/description")
-	tasksCreateCmd.Flags().String("priority", "", "Priority: none, low, medium, high")
-	tasksCreateCmd.Flags().String("due", "", "Due date: today, tomorrow, +3d, YYYY-MM-DD")
-	tasksCreateCmd.Flags().String("tags", "", "Comma-separated tags")
+// applyManifest creates the tasks of a manifest, skipping specs whose key
+// already exists as an open task in the target project.
+func applyManifest(client *ticktick.Client, manifest *ticktick.Manifest, dryRun bool) error {
+	projectIDs, err := resolveManifestProjects(client, manifest.Tasks)
+	if err != nil {
+		return err
+	}
 
-	tasksUpdateCmd.Flags().String("project", "", "Project ID (required)")
-	tasksUpdateCmd.Flags().String("title", "", "New title")
-	tasksUpdateCmd.Flags().String("content", "", "New content")
-	tasksUpdateCmd.Flags().String("priority", "", "Priority: none, low, medium, high")
-	tasksUpdateCmd.Flags().String("due", "", "Due date: today, tomorrow, +3d, YYYY-MM-DD")
-	tasksUpdateCmd.Flags().Bool("clear-due", false, "Clear the due date")
-	tasksUpdateCmd.Flags().String("tags", "", "Replace all tags (comma-separated)")
-	tasksUpdateCmd.Flags().String("add-tags", "", "Add tags (comma-separated)")
-	tasksUpdateCmd.Flags().String("remove-tags", "", "Remove tags (comma-separated)")
+	// Collect the keys of open tasks in every target project
+	existing := make(map[string]map[string]string)
+	for _, id := range projectIDs {
+		if _, ok := existing[id]; ok {
+			continue
+		}
+		pd, err := client.GetProjectData(id)
+		if err != nil {
+			return fmt.Errorf("failed to get project data: %w", err)
+		}
+		keys := make(map[string]string)
+		for _, t := range pd.Tasks {
+			if key := ticktick.TaskKey(t.Content); key != "" {
+				keys[key] = t.ID
+			}
+		}
+		existing[id] = keys
+	}
 
-	tasksCompleteCmd.Flags().String("project", "", "Project ID (required)")
+	// Keys of tasks created by earlier runs, which may be completed since
+	applied, err := ticktick.LoadAppliedKeys()
+	if err != nil {
+		return fmt.Errorf("failed to load applied keys: %w", err)
+	}
 
-	tasksDeleteCmd.Flags().String("project", "", "Project ID (required)")
+	results := make([]applyResult, 0, len(manifest.Tasks))
+	var failed int
+	for i, spec := range manifest.Tasks {
+		projectID := projectIDs[i]
+		res := applyResult{Key: spec.ResolvedKey(), ProjectID: projectID, Title: spec.Title}
+		appliedID := applied.Lookup(projectID, res.Key)
+		if appliedID != "" && existing[projectID][res.Key] == "" {
+			// A task deleted since is created again; a completed one is kept
+			if _, err := client.GetTask(projectID, appliedID); ticktick.IsNotFound(err) {
+				applied.Forget(projectID, res.Key)
+				appliedID = ""
+			}
+		}
+		switch {
+		case existing[projectID][res.Key] != "":
+			res.TaskID = existing[projectID][res.Key]
+			res.Status = "exists"
+		case appliedID != "":
+			res.TaskID = appliedID
+			res.Status = "exists"
+		case dryRun:
+			res.Status = "would-create"
+		default:
+			req, err := spec.CreateRequest(projectID)
+			if err == nil {
+				var task *ticktick.Task
+				task, err = client.CreateTask(req)
+				if err == nil {
+					res.TaskID = task.ID
+					applied.Record(projectID, res.Key, task.ID)
+					if err := ticktick.SaveAppliedKeys(applied); err != nil {
+						return fmt.Errorf("failed to save applied keys: %w", err)
+					}
+				}
+			}
+			if err != nil {
+				res.Status = "failed"
+				res.Error = err.Error()
+				failed++
+			} else {
+				res.Status = "created"
+			}
+		}
+		results = append(results, res)
+	}
 
-	tasksCmd.AddCommand(tasksListCmd)
-	tasksCmd.AddCommand(tasksGetCmd)
-	tasksCmd.AddCommand(tasksCreateCmd)
-	tasksCmd.AddCommand(tasksUpdateCmd)
-	tasksCmd.AddCommand(tasksCompleteCmd)
-	tasksCmd.AddCommand(tasksDeleteCmd)
-	rootCmd.AddCommand(tasksCmd)
+	if err := printApplyResults(results, dryRun); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to create %d of %d tasks", failed, len(results))
+	}
+	return nil
 }
 
-// findInboxID finds the Inbox project ID.
-func findInboxID(client *ticktick.Client) (string, error) {
-	return client.DiscoverInboxID()
-}
+func printApplyResults(results []applyResult, dryRun bool) error {
+	if outputJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	}
 
-func containsStr(slice []string, s string) bool {
-	for _, v := range slice {
-		if strings.TrimSpace(v) == strings.TrimSpace(s) {
-			return true
+	if outputPlain {
+		for _, r := range results {
+			fmt.Printf("%s\t%s\t%s\t%s\t%s\n", r.Key, r.TaskID, r.ProjectID, r.Status, r.Title)
 		}
+		return nil
 	}
-	return false
+
+	var created, exists int
+	for _, r := range results {
+		switch r.Status {
+		case "created", "would-create":
+			created++
+			fmt.Printf("+ %s\n", r.Title)
+		case "exists":
+			exists++
+			fmt.Printf("= %s (ID: %s)\n", r.Title, r.TaskID)
+		case "failed":
+			fmt.Fprintf(os.Stderr, "Failed to create %q: %s\n", r.Title, r.Error)
+		}
+	}
+	if dryRun {
+		fmt.Printf("Would create %d tasks (%d already exist)\n", created, exists)
+	} else {
+		fmt.Printf("Created %d tasks (%d already exist)\n", created, exists)
+	}
+	return nil
 }
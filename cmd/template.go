@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+
+	"github.com/spf13/cobra"
+)
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Manage reusable task templates",
+	Long:  "Manage reusable task templates stored in ~/.config/ticky/templates/. A template is a tasks apply manifest expanded with Go text/template: {{.name}} inserts a --var value and {{due \"+3d\"}} inserts a date.",
+}
+
+var templateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved templates",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		names, err := ticktick.ListTemplates()
+		if err != nil {
+			return fmt.Errorf("failed to list templates: %w", err)
+		}
+
+		if outputJSON {
+			if names == nil {
+				names = []string{}
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(names)
+		}
+
+		if len(names) == 0 && !outputPlain {
+			fmt.Printf("No templates found in %s\n", ticktick.TemplatesDir())
+			return nil
+		}
+		for _, name := range names {
+			fmt.Println(name)
+		}
+		return nil
+	},
+}
+
+var templateShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show a template's source",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		src, err := ticktick.LoadTemplate(args[0])
+		if err != nil {
+			return err
+		}
+
+		if outputJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(map[string]string{"name": args[0], "source": src})
+		}
+
+		fmt.Print(src)
+		if !strings.HasSuffix(src, "\n") {
+			fmt.Println()
+		}
+		return nil
+	},
+}
+
+var templateApplyCmd = &cobra.Command{
+	Use:   "apply <name>",
+	Short: "Create tasks from a template",
+	Long:  "Create tasks from a template. Tasks are created like tasks apply, so re-applying with the same variables does not create duplicates.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		varList, _ := cmd.Flags().GetStringArray("var")
+		vars := make(map[string]string, len(varList))
+		for _, v := range varList {
+			k, val, ok := strings.Cut(v, "=")
+			if !ok || k == "" {
+				return fmt.Errorf("invalid --var %q (use key=value)", v)
+			}
+			vars[k] = val
+		}
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		src, err := ticktick.LoadTemplate(args[0])
+		if err != nil {
+			return err
+		}
+		manifest, err := ticktick.RenderTemplate(src, vars)
+		if err != nil {
+			return err
+		}
+		if cmd.Flags().Changed("project") {
+			project, _ := cmd.Flags().GetString("project")
+			for i := range manifest.Tasks {
+				manifest.Tasks[i].Project = project
+			}
+		}
+		ticktick.ScopeTemplateKeys(manifest, args[0], vars)
+
+		client, err := ticktick.NewClient()
+		if err != nil {
+			return err
+		}
+		return applyManifest(client, manifest, dryRun)
+	},
+}
+
+var templateSaveFromProjectCmd = &cobra.Command{
+	Use:   "save-from-project <name>",
+	Short: "Save a project's open tasks as a template",
+	Long:  "Save a project's open tasks as a template. Due dates are stored relative to today so the template can be re-applied later.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		force, _ := cmd.Flags().GetBool("force")
+		if !force {
+			if _, err := ticktick.LoadTemplate(name); err == nil {
+				return fmt.Errorf("template %q already exists (use --force to overwrite)", name)
+			}
+		}
+
+		client, err := ticktick.NewClient()
+		if err != nil {
+			return err
+		}
+
+		projectID, err := resolveProjectFlag(cmd, client)
+		if err == nil && projectID == "" {
+			projectID, err = findDefaultProjectID(client)
+		}
+		if err != nil {
+			return err
+		}
+
+		pd, err := client.GetProjectData(projectID)
+		if err != nil {
+			return fmt.Errorf("failed to get project data: %w", err)
+		}
+		src, err := ticktick.TemplateFromProject(pd, time.Now())
+		if err != nil {
+			return err
+		}
+		if err := ticktick.SaveTemplate(name, src); err != nil {
+			return fmt.Errorf("failed to save template: %w", err)
+		}
+
+		path, _ := ticktick.TemplatePath(name)
+		if outputJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(map[string]any{"name": name, "path": path, "tasks": len(pd.Tasks)})
+		}
+		if outputPlain {
+			fmt.Printf("%s\t%s\t%d\n", name, path, len(pd.Tasks))
+			return nil
+		}
+		fmt.Printf("Saved template %q with %d tasks to %s\n", name, len(pd.Tasks), path)
+		return nil
+	},
+}
+
+func init() {
+	templateApplyCmd.Flags().StringArray("var", nil, "Template variable as key=value (repeatable)")
+	templateApplyCmd.Flags().String("project", "", "Create all tasks in this project (name or ID), overriding the template")
+	templateApplyCmd.Flags().Bool("dry-run", false, "Show what would be created without creating anything")
+
+	templateSaveFromProjectCmd.Flags().String("project", "", "Project name or ID (default: the default_project setting, or Inbox)")
+	templateSaveFromProjectCmd.Flags().Bool("force", false, "Overwrite an existing template")
+
+	templateCmd.AddCommand(templateListCmd)
+	templateCmd.AddCommand(templateShowCmd)
+	templateCmd.AddCommand(templateApplyCmd)
+	templateCmd.AddCommand(templateSaveFromProjectCmd)
+	rootCmd.AddCommand(templateCmd)
+}
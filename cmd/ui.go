@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+	"github.com/tackeyy/ticky/internal/tui"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var uiCmd = &cobra.Command{
+	Use:   "ui",
+	Short: "Open the interactive terminal UI",
+	Long:  "Open a full-screen terminal UI with a project sidebar, task list and detail pane. Changes are shown immediately and rolled back if the API call fails.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Only the flags count: an output format set in the config applies
+		// to the other commands
+		if cmd.Flags().Changed("json") || cmd.Flags().Changed("plain") {
+			return fmt.Errorf("ui does not support --json or --plain")
+		}
+
+		client, err := ticktick.NewClient()
+		if err != nil {
+			return err
+		}
+		client.SetHookOutput(io.Discard)
+		inboxID, err := findInboxID(client)
+		if err != nil {
+			return err
+		}
+		refresh, _ := cmd.Flags().GetDuration("refresh")
+
+		model := tui.New(client, tui.Options{InboxID: inboxID, RefreshInterval: refresh})
+		if _, err := tea.NewProgram(model, tea.WithAltScreen()).Run(); err != nil {
+			return fmt.Errorf("failed to run ui: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	uiCmd.Flags().Duration("refresh", 30*time.Second, "Background refresh interval (0 disables)")
+	rootCmd.AddCommand(uiCmd)
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestUICmd_OutputConfig(t *testing.T) {
+	// Arrange — no token, so a ui that gets past the flag check fails to log in
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("TICKTICK_ACCESS_TOKEN", "")
+	t.Setenv("TICKY_OUTPUT", "json")
+	rootCmd.SetOut(io.Discard)
+	rootCmd.SetErr(io.Discard)
+	t.Cleanup(func() { outputJSON, outputPlain = false, false })
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"output from config", []string{"ui"}, "not authenticated"},
+		{"--json flag", []string{"ui", "--json"}, "ui does not support --json or --plain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			rootCmd.SetArgs(tt.args)
+			err := rootCmd.Execute()
+
+			// Assert
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ticky %s error = %v, want %q", strings.Join(tt.args, " "), err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+
+	"github.com/spf13/cobra"
+)
+
+// minWatchInterval keeps polling within the TickTick API rate limits.
+const minWatchInterval = 10 * time.Second
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Poll for task changes and print them as NDJSON events",
+	Long: `Poll projects on an interval and print one JSON event per line for every
+task that was created, updated (with the changed fields), completed or
+deleted since the previous poll.
+
+The last seen state is saved after each poll (default: watch_state.json in
+the profile directory), so a restarted watch reports what changed while it
+was stopped. The first run only records the current tasks.
+
+Events are also delivered to the webhooks configured with "ticky webhooks"
+unless --no-webhooks is given.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		interval, _ := cmd.Flags().GetDuration("interval")
+		projects, _ := cmd.Flags().GetStringArray("project")
+		tags, _ := cmd.Flags().GetStringArray("tag")
+		statePath, _ := cmd.Flags().GetString("state")
+		once, _ := cmd.Flags().GetBool("once")
+		noWebhooks, _ := cmd.Flags().GetBool("no-webhooks")
+
+		if interval < minWatchInterval {
+			return fmt.Errorf("--interval must be at least %s", minWatchInterval)
+		}
+
+		client, err := ticktick.NewClient()
+		if err != nil {
+			return err
+		}
+		filter := ticktick.WatchFilter{Tags: tags}
+		for _, p := range projects {
+			id, err := client.ResolveProject(p)
+			if err != nil {
+				return err
+			}
+			filter.ProjectIDs = append(filter.ProjectIDs, id)
+		}
+
+		if statePath == "" {
+			statePath = ticktick.WatchStatePath()
+		}
+		state, err := ticktick.LoadWatchState(statePath)
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		enc := json.NewEncoder(os.Stdout)
+		for {
+			firstRun := state.Tasks == nil
+			events, err := client.Poll(state, filter)
+			if err == nil {
+				for _, ev := range events {
+					if err := enc.Encode(ev); err != nil {
+						return fmt.Errorf("failed to write event: %w", err)
+					}
+				}
+			}
+			// Queue before saving the state, so a crash repeats events
+			// rather than losing them; pending retries are sent even when
+			// the poll failed
+			if !noWebhooks {
+				deliverWebhooks(events)
+			}
+			if err == nil {
+				if err := state.Save(statePath); err != nil {
+					return err
+				}
+				if firstRun {
+					fmt.Fprintf(os.Stderr, "Recorded %d tasks; changes are reported from the next poll\n", len(state.Tasks))
+				}
+			}
+			if once {
+				return err
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: poll failed: %v\n", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(interval):
+			}
+		}
+	},
+}
+
+func init() {
+	watchCmd.Flags().Duration("interval", time.Minute, "Time between polls")
+	watchCmd.Flags().StringArray("project", nil, "Watch only this project, by name or ID (repeatable)")
+	watchCmd.Flags().StringArray("tag", nil, "Report only tasks with this tag (repeatable)")
+	watchCmd.Flags().String("state", "", "State file (default: watch_state.json in the profile directory)")
+	watchCmd.Flags().Bool("once", false, "Poll once and exit")
+	watchCmd.Flags().Bool("no-webhooks", false, "Do not deliver events to the configured webhooks")
+	rootCmd.AddCommand(watchCmd)
+}
@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+
+	"github.com/spf13/cobra"
+)
+
+var webhooksCmd = &cobra.Command{
+	Use:   "webhooks",
+	Short: "Outbound webhooks on task events",
+	Long: `Outbound webhooks notified of the task events found by "ticky watch".
+
+Each event is POSTed as JSON to every webhook subscribed to its type, signed
+with the webhook secret in the X-Ticky-Signature header
+("sha256=<hex HMAC-SHA256 of the body>"). Deliveries are queued on disk and
+retried with exponential backoff until the endpoint answers with 2xx.`,
+}
+
+var webhooksAddCmd = &cobra.Command{
+	Use:   "add <name> <url>",
+	Short: "Add or replace a webhook",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		eventsStr, _ := cmd.Flags().GetString("events")
+		secret, _ := cmd.Flags().GetString("secret")
+
+		hook := ticktick.Webhook{URL: args[1], Secret: secret}
+		for _, e := range strings.Split(eventsStr, ",") {
+			if e = strings.TrimSpace(e); e != "" {
+				hook.Events = append(hook.Events, e)
+			}
+		}
+		if err := ticktick.ValidateWebhook(hook); err != nil {
+			return err
+		}
+		if hook.Secret == "" {
+			var err error
+			if hook.Secret, err = ticktick.GenerateWebhookSecret(); err != nil {
+				return err
+			}
+		}
+
+		hooks, err := ticktick.LoadWebhooks()
+		if err != nil {
+			return fmt.Errorf("failed to load webhooks: %w", err)
+		}
+		hooks[args[0]] = hook
+		if err := ticktick.SaveWebhooks(hooks); err != nil {
+			return fmt.Errorf("failed to save webhooks: %w", err)
+		}
+
+		fmt.Printf("Webhook %s -> %s (%s)\n", args[0], hook.URL, webhookEventsString(hook))
+		if secret == "" {
+			fmt.Printf("Signing secret: %s\n", hook.Secret)
+		}
+		return nil
+	},
+}
+
+var webhooksListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List webhooks",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		hooks, err := ticktick.LoadWebhooks()
+		if err != nil {
+			return fmt.Errorf("failed to load webhooks: %w", err)
+		}
+
+		names := make([]string, 0, len(hooks))
+		for name := range hooks {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		if outputJSON {
+			type hookInfo struct {
+				Name   string   `json:"name"`
+				URL    string   `json:"url"`
+				Events []string `json:"events"`
+			}
+			list := []hookInfo{}
+			for _, name := range names {
+				list = append(list, hookInfo{Name: name, URL: hooks[name].URL, Events: hooks[name].Events})
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(list)
+		}
+
+		if outputPlain {
+			for _, name := range names {
+				fmt.Printf("%s\t%s\t%s\n", name, hooks[name].URL, strings.Join(hooks[name].Events, ","))
+			}
+			return nil
+		}
+
+		if len(names) == 0 {
+			fmt.Println("No webhooks found")
+			return nil
+		}
+		for _, name := range names {
+			fmt.Printf("%-16s %s (%s)\n", name, hooks[name].URL, webhookEventsString(hooks[name]))
+		}
+		return nil
+	},
+}
+
+var webhooksRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a webhook",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		hooks, err := ticktick.LoadWebhooks()
+		if err != nil {
+			return fmt.Errorf("failed to load webhooks: %w", err)
+		}
+		if _, ok := hooks[args[0]]; !ok {
+			return fmt.Errorf("webhook not found: %s", args[0])
+		}
+		delete(hooks, args[0])
+		if err := ticktick.SaveWebhooks(hooks); err != nil {
+			return fmt.Errorf("failed to save webhooks: %w", err)
+		}
+
+		fmt.Printf("Webhook %s removed\n", args[0])
+		return nil
+	},
+}
+
+var webhooksTestCmd = &cobra.Command{
+	Use:   "test <name>",
+	Short: "Send a signed test event to a webhook",
+	Long: `Send a signed "test" event with a sample task to a webhook right away,
+bypassing its event filter and the queue, and report the result.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		hooks, err := ticktick.LoadWebhooks()
+		if err != nil {
+			return fmt.Errorf("failed to load webhooks: %w", err)
+		}
+		hook, ok := hooks[args[0]]
+		if !ok {
+			return fmt.Errorf("webhook not found: %s", args[0])
+		}
+
+		now := time.Now().UTC()
+		id := fmt.Sprintf("test-%d", now.UnixNano())
+		body, err := json.Marshal(ticktick.WebhookPayload{
+			ID: id,
+			WatchEvent: ticktick.WatchEvent{
+				Type: ticktick.WebhookTestEvent,
+				Time: now,
+				Task: ticktick.Task{ID: "test", Title: "ticky webhook test", Priority: ticktick.PriorityHigh, Tags: []string{"test"}},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to encode test payload: %w", err)
+		}
+		if err := ticktick.SendWebhook(&http.Client{Timeout: 10 * time.Second}, hook, id, ticktick.WebhookTestEvent, body); err != nil {
+			return err
+		}
+
+		fmt.Printf("Delivered test event to %s (%s)\n", args[0], hook.URL)
+		return nil
+	},
+}
+
+func webhookEventsString(hook ticktick.Webhook) string {
+	if len(hook.Events) == 0 {
+		return "all events"
+	}
+	return strings.Join(hook.Events, ", ")
+}
+
+// deliverWebhooks queues events for the configured webhooks and sends what
+// is due. Failures are reported on stderr and retried later.
+func deliverWebhooks(events []ticktick.WatchEvent) {
+	hooks, err := ticktick.LoadWebhooks()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to load webhooks: %v\n", err)
+		return
+	}
+	if len(hooks) == 0 {
+		return
+	}
+
+	queue := ticktick.NewWebhookQueue()
+	if _, err := queue.Enqueue(hooks, events); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	result, err := queue.Flush(hooks)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to deliver webhooks: %v\n", err)
+		return
+	}
+	for _, d := range result.Dropped {
+		fmt.Fprintf(os.Stderr, "Warning: gave up delivering %s event %s to %s after %d attempts: %s\n",
+			d.Event, d.ID, d.Webhook, d.Attempts, d.LastError)
+	}
+}
+
+func init() {
+	webhooksAddCmd.Flags().String("events", "", "Comma-separated event types: created, updated, completed, deleted (default: all)")
+	webhooksAddCmd.Flags().String("secret", "", "Signing secret (default: generated)")
+
+	webhooksCmd.AddCommand(webhooksAddCmd)
+	webhooksCmd.AddCommand(webhooksListCmd)
+	webhooksCmd.AddCommand(webhooksRemoveCmd)
+	webhooksCmd.AddCommand(webhooksTestCmd)
+	rootCmd.AddCommand(webhooksCmd)
+}
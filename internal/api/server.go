@@ -0,0 +1,350 @@
+// Package api implements the local REST API started by "ticky serve". It
+// exposes TickTick tasks, projects and tags as JSON over HTTP so other local
+// services can use them without implementing OAuth themselves.
+package api
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+)
+
+// maxBodySize limits request bodies.
+const maxBodySize = 1 << 20
+
+// Backend is the subset of the TickTick client used by the API.
+type Backend interface {
+	GetProjects() ([]ticktick.Project, error)
+	GetAllProjectIDs() ([]string, error)
+	GetProjectData(projectID string) (*ticktick.ProjectData, error)
+	GetTask(projectID, taskID string) (*ticktick.Task, error)
+	CreateTask(req *ticktick.TaskCreateRequest) (*ticktick.Task, error)
+	UpdateTask(req *ticktick.TaskUpdateRequest) (*ticktick.Task, error)
+	CompleteTask(projectID, taskID string) error
+	ResolveProject(ref string) (string, error)
+	ResolveTaskRef(ref string) (string, error)
+	FindTaskProject(taskID string) (string, error)
+}
+
+// Server serves the REST API.
+type Server struct {
+	backend Backend
+	token   string
+	logger  *log.Logger
+}
+
+// NewServer returns a server that requires "Authorization: Bearer <token>"
+// on every request and logs each request to logger.
+func NewServer(backend Backend, token string, logger *log.Logger) *Server {
+	return &Server{backend: backend, token: token, logger: logger}
+}
+
+// Handler returns the HTTP handler of the API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /tasks", s.listTasks)
+	mux.HandleFunc("POST /tasks", s.createTask)
+	mux.HandleFunc("PATCH /tasks/{id}", s.updateTask)
+	mux.HandleFunc("POST /tasks/{id}/complete", s.completeTask)
+	mux.HandleFunc("GET /projects", s.listProjects)
+	mux.HandleFunc("GET /tags", s.listTags)
+	return s.logRequests(s.authenticate(mux))
+}
+
+// httpError is an error with the HTTP status it is reported with.
+type httpError struct {
+	status int
+	err    error
+}
+
+func (e *httpError) Error() string {
+	return e.err.Error()
+}
+
+func badRequest(err error) error {
+	return &httpError{http.StatusBadRequest, err}
+}
+
+// statusOf maps an error to an HTTP status. Requests rejected by a pre-hook
+// are unprocessable; errors not marked otherwise come from the TickTick API.
+func statusOf(err error) int {
+	var he *httpError
+	var ambiguous *ticktick.AmbiguousProjectError
+	var hookErr *ticktick.HookError
+	switch {
+	case errors.As(err, &he):
+		return he.status
+	case errors.As(err, &hookErr):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, ticktick.ErrTaskNotFound), errors.Is(err, ticktick.ErrProjectNotFound):
+		return http.StatusNotFound
+	case errors.As(err, &ambiguous):
+		return http.StatusBadRequest
+	}
+	return http.StatusBadGateway
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	writeJSON(w, statusOf(err), map[string]string{"error": err.Error()})
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="ticky"`)
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "missing or invalid bearer token"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// statusRecorder remembers the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (s *Server) logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		s.logger.Printf("%s %s %d %s", r.Method, r.URL.RequestURI(), rec.status, time.Since(start).Round(time.Millisecond))
+	})
+}
+
+// decodeBody decodes a JSON request body into v, rejecting unknown fields.
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) error {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		return badRequest(fmt.Errorf("failed to read request body: %w", err))
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return badRequest(fmt.Errorf("invalid request body: %w", err))
+	}
+	return nil
+}
+
+// listTasks serves GET /tasks. The optional query parameters project (name
+// or ID), where (a filter expression as in "tasks update --where") and text
+// (found in the title or content) narrow down the open tasks returned.
+func (s *Server) listTasks(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter, err := ticktick.ParseFilter(q.Get("where"))
+	if err != nil {
+		writeError(w, badRequest(err))
+		return
+	}
+	projectID, err := s.backend.ResolveProject(q.Get("project"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	projectIDs := []string{projectID}
+	if projectID == "" {
+		if projectIDs, err = s.backend.GetAllProjectIDs(); err != nil {
+			writeError(w, fmt.Errorf("failed to list projects: %w", err))
+			return
+		}
+	}
+
+	text := strings.ToLower(q.Get("text"))
+	tasks := []ticktick.Task{}
+	for _, pid := range projectIDs {
+		pd, err := s.backend.GetProjectData(pid)
+		if err != nil {
+			writeError(w, fmt.Errorf("failed to list tasks: %w", err))
+			return
+		}
+		for _, t := range pd.Tasks {
+			if !filter.Match(t) {
+				continue
+			}
+			if text != "" && !strings.Contains(strings.ToLower(t.Title+"\n"+t.Content), text) {
+				continue
+			}
+			tasks = append(tasks, t)
+		}
+	}
+	writeJSON(w, http.StatusOK, tasks)
+}
+
+// createTask serves POST /tasks. The body is a task create request whose
+// projectId may be a project name and dueDate any --due expression.
+func (s *Server) createTask(w http.ResponseWriter, r *http.Request) {
+	var req ticktick.TaskCreateRequest
+	if err := decodeBody(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+	if strings.TrimSpace(req.Title) == "" {
+		writeError(w, badRequest(fmt.Errorf("title is required")))
+		return
+	}
+	projectID, err := s.backend.ResolveProject(req.ProjectID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	req.ProjectID = projectID
+	if req.DueDate != "" {
+		if req.DueDate, err = ticktick.ParseDate(req.DueDate); err != nil {
+			writeError(w, badRequest(err))
+			return
+		}
+	}
+
+	task, err := s.backend.CreateTask(&req)
+	if err != nil {
+		writeError(w, fmt.Errorf("failed to create task: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusCreated, task)
+}
+
+// resolveTask resolves the {id} path value, which may be any task reference,
+// and the optional project query parameter.
+func (s *Server) resolveTask(r *http.Request) (ticktick.TaskRef, error) {
+	id, err := s.backend.ResolveTaskRef(r.PathValue("id"))
+	if err != nil {
+		return ticktick.TaskRef{}, badRequest(err)
+	}
+	projectID, err := s.backend.ResolveProject(r.URL.Query().Get("project"))
+	if err != nil {
+		return ticktick.TaskRef{}, err
+	}
+	if projectID == "" {
+		if projectID, err = s.backend.FindTaskProject(id); err != nil {
+			return ticktick.TaskRef{}, err
+		}
+	}
+	return ticktick.TaskRef{ID: id, ProjectID: projectID}, nil
+}
+
+// updateTask serves PATCH /tasks/{id}. Only the fields present in the body
+// change; an empty dueDate clears the due date.
+func (s *Server) updateTask(w http.ResponseWriter, r *http.Request) {
+	ref, err := s.resolveTask(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	existing, err := s.backend.GetTask(ref.ProjectID, ref.ID)
+	if err != nil {
+		writeError(w, fmt.Errorf("failed to get existing task: %w", err))
+		return
+	}
+
+	// Decoding over the current values changes only the given fields
+	req := existing.UpdateRequest()
+	if err := decodeBody(w, r, req); err != nil {
+		writeError(w, err)
+		return
+	}
+	req.ID, req.ProjectID = existing.ID, existing.ProjectID
+	if req.DueDate != nil && *req.DueDate != "" {
+		due, err := ticktick.ParseDate(*req.DueDate)
+		if err != nil {
+			writeError(w, badRequest(err))
+			return
+		}
+		req.DueDate = &due
+	}
+
+	task, err := s.backend.UpdateTask(req)
+	if err != nil {
+		writeError(w, fmt.Errorf("failed to update task: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, task)
+}
+
+// completeTask serves POST /tasks/{id}/complete.
+func (s *Server) completeTask(w http.ResponseWriter, r *http.Request) {
+	ref, err := s.resolveTask(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.backend.CompleteTask(ref.ProjectID, ref.ID); err != nil {
+		writeError(w, fmt.Errorf("failed to complete task: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"id": ref.ID, "projectId": ref.ProjectID, "status": "completed"})
+}
+
+// listProjects serves GET /projects.
+func (s *Server) listProjects(w http.ResponseWriter, r *http.Request) {
+	projects, err := s.backend.GetProjects()
+	if err != nil {
+		writeError(w, fmt.Errorf("failed to list projects: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, projects)
+}
+
+// listTags serves GET /tags: every tag in use with the number of open tasks
+// carrying it, most used first.
+func (s *Server) listTags(w http.ResponseWriter, r *http.Request) {
+	projectIDs, err := s.backend.GetAllProjectIDs()
+	if err != nil {
+		writeError(w, fmt.Errorf("failed to list projects: %w", err))
+		return
+	}
+	counts := make(map[string]int)
+	for _, pid := range projectIDs {
+		pd, err := s.backend.GetProjectData(pid)
+		if err != nil {
+			writeError(w, fmt.Errorf("failed to list tasks: %w", err))
+			return
+		}
+		for _, t := range pd.Tasks {
+			for _, tag := range t.Tags {
+				counts[tag]++
+			}
+		}
+	}
+
+	type tagInfo struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+	tags := []tagInfo{}
+	for name, count := range counts {
+		tags = append(tags, tagInfo{Name: name, Count: count})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+		return tags[i].Name < tags[j].Name
+	})
+	writeJSON(w, http.StatusOK, tags)
+}
@@ -0,0 +1,290 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+)
+
+const testToken = "secret-token"
+
+// fakeBackend serves fixed projects and tasks and records mutations.
+type fakeBackend struct {
+	projects []ticktick.Project
+	tasks    map[string][]ticktick.Task
+	fail     error
+	calls    []string
+	created  *ticktick.TaskCreateRequest
+	updated  *ticktick.TaskUpdateRequest
+}
+
+func newFakeBackend() *fakeBackend {
+	return &fakeBackend{
+		projects: []ticktick.Project{{ID: "proj-1", Name: "Work"}},
+		tasks: map[string][]ticktick.Task{
+			"inbox": {{ID: "task-0", ProjectID: "inbox", Title: "Buy milk", Tags: []string{"errand"}}},
+			"proj-1": {
+				{ID: "task-1", ProjectID: "proj-1", Title: "Write report", Tags: []string{"work"}},
+				{ID: "task-2", ProjectID: "proj-1", Title: "Review PR", Priority: ticktick.PriorityHigh, Tags: []string{"work"}},
+			},
+		},
+	}
+}
+
+func (f *fakeBackend) GetProjects() ([]ticktick.Project, error) {
+	return f.projects, f.fail
+}
+
+func (f *fakeBackend) GetAllProjectIDs() ([]string, error) {
+	return []string{"inbox", "proj-1"}, f.fail
+}
+
+func (f *fakeBackend) GetProjectData(projectID string) (*ticktick.ProjectData, error) {
+	return &ticktick.ProjectData{Tasks: f.tasks[projectID]}, f.fail
+}
+
+func (f *fakeBackend) GetTask(projectID, taskID string) (*ticktick.Task, error) {
+	for _, t := range f.tasks[projectID] {
+		if t.ID == taskID {
+			return &t, nil
+		}
+	}
+	return nil, errors.New("API error 404")
+}
+
+func (f *fakeBackend) CreateTask(req *ticktick.TaskCreateRequest) (*ticktick.Task, error) {
+	if f.fail != nil {
+		return nil, f.fail
+	}
+	f.created = req
+	return &ticktick.Task{ID: "new-task", ProjectID: req.ProjectID, Title: req.Title}, nil
+}
+
+func (f *fakeBackend) UpdateTask(req *ticktick.TaskUpdateRequest) (*ticktick.Task, error) {
+	if f.fail != nil {
+		return nil, f.fail
+	}
+	f.updated = req
+	return &ticktick.Task{ID: req.ID, ProjectID: req.ProjectID, Title: req.Title}, nil
+}
+
+func (f *fakeBackend) CompleteTask(projectID, taskID string) error {
+	f.calls = append(f.calls, "complete "+projectID+"/"+taskID)
+	return f.fail
+}
+
+func (f *fakeBackend) ResolveProject(ref string) (string, error) {
+	switch strings.ToLower(ref) {
+	case "", "inbox":
+		return ref, nil
+	case "work", "proj-1":
+		return "proj-1", nil
+	}
+	return "", ticktick.ErrProjectNotFound
+}
+
+func (f *fakeBackend) ResolveTaskRef(ref string) (string, error) {
+	return ref, nil
+}
+
+func (f *fakeBackend) FindTaskProject(taskID string) (string, error) {
+	for pid, tasks := range f.tasks {
+		for _, t := range tasks {
+			if t.ID == taskID {
+				return pid, nil
+			}
+		}
+	}
+	return "", ticktick.ErrTaskNotFound
+}
+
+// do sends an authenticated request to a server backed by backend.
+func do(t *testing.T, backend Backend, method, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	rec := httptest.NewRecorder()
+	NewServer(backend, testToken, log.New(io.Discard, "", 0)).Handler().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandler_RequiresBearerToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+	}{
+		{"missing", ""},
+		{"wrong token", "Bearer nope"},
+		{"wrong scheme", "Basic " + testToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			req := httptest.NewRequest("GET", "/projects", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			// Act
+			NewServer(newFakeBackend(), testToken, log.New(io.Discard, "", 0)).Handler().ServeHTTP(rec, req)
+
+			// Assert
+			if rec.Code != http.StatusUnauthorized {
+				t.Errorf("status = %d, want 401", rec.Code)
+			}
+		})
+	}
+}
+
+func TestListTasks_Filters(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		want   string
+	}{
+		{"all", "/tasks", "task-0,task-1,task-2"},
+		{"project", "/tasks?project=work", "task-1,task-2"},
+		{"where", "/tasks?where=" + "priority%3E%3Dmedium", "task-2"},
+		{"text", "/tasks?text=MILK", "task-0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			rec := do(t, newFakeBackend(), "GET", tt.target, "")
+
+			// Assert
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+			}
+			var tasks []ticktick.Task
+			json.Unmarshal(rec.Body.Bytes(), &tasks)
+			var ids []string
+			for _, task := range tasks {
+				ids = append(ids, task.ID)
+			}
+			if got := strings.Join(ids, ","); got != tt.want {
+				t.Errorf("GET %s = %s, want %s", tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateTask(t *testing.T) {
+	// Arrange
+	backend := newFakeBackend()
+
+	// Act
+	rec := do(t, backend, "POST", "/tasks", `{"title":"Plan sprint","projectId":"Work","dueDate":"2026-03-01"}`)
+
+	// Assert
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: %s", rec.Code, rec.Body)
+	}
+	if backend.created.ProjectID != "proj-1" || !strings.HasPrefix(backend.created.DueDate, "2026-03-01T") {
+		t.Errorf("create request = %+v, want project proj-1 and due 2026-03-01", backend.created)
+	}
+}
+
+func TestUpdateTask_ChangesOnlyGivenFields(t *testing.T) {
+	// Arrange
+	backend := newFakeBackend()
+
+	// Act
+	rec := do(t, backend, "PATCH", "/tasks/task-2", `{"title":"Review PR #42"}`)
+
+	// Assert
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	req := backend.updated
+	if req.ID != "task-2" || req.ProjectID != "proj-1" || req.Title != "Review PR #42" {
+		t.Errorf("update request = %+v, want task-2 in proj-1 with the new title", req)
+	}
+	if *req.Priority != ticktick.PriorityHigh || len(req.Tags) != 1 {
+		t.Errorf("update request = %+v, want priority and tags unchanged", req)
+	}
+}
+
+func TestCompleteTask(t *testing.T) {
+	// Arrange
+	backend := newFakeBackend()
+
+	// Act
+	rec := do(t, backend, "POST", "/tasks/task-1/complete", "")
+
+	// Assert
+	if rec.Code != http.StatusOK || strings.Join(backend.calls, ",") != "complete proj-1/task-1" {
+		t.Errorf("status = %d, calls = %v, want 200 and complete proj-1/task-1", rec.Code, backend.calls)
+	}
+}
+
+func TestHandler_ErrorStatuses(t *testing.T) {
+	tests := []struct {
+		name   string
+		fail   error
+		method string
+		target string
+		body   string
+		want   int
+	}{
+		{"invalid JSON", nil, "POST", "/tasks", `{"title":`, http.StatusBadRequest},
+		{"unknown field", nil, "POST", "/tasks", `{"title":"x","prio":1}`, http.StatusBadRequest},
+		{"missing title", nil, "POST", "/tasks", `{}`, http.StatusBadRequest},
+		{"invalid filter", nil, "GET", "/tasks?where=bogus", "", http.StatusBadRequest},
+		{"unknown project", nil, "GET", "/tasks?project=nope", "", http.StatusNotFound},
+		{"unknown task", nil, "POST", "/tasks/missing/complete", "", http.StatusNotFound},
+		{"upstream failure", errors.New("API error 500"), "GET", "/projects", "", http.StatusBadGateway},
+		{"rejected by hook", &ticktick.HookError{Event: ticktick.HookPreCreate, Message: "no"}, "POST", "/tasks", `{"title":"x"}`, http.StatusUnprocessableEntity},
+		{"wrong method", nil, "DELETE", "/projects", "", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			backend := newFakeBackend()
+			backend.fail = tt.fail
+
+			// Act
+			rec := do(t, backend, tt.method, tt.target, tt.body)
+
+			// Assert
+			if rec.Code != tt.want {
+				t.Errorf("%s %s status = %d, want %d: %s", tt.method, tt.target, rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+}
+
+func TestHandler_LogsRequests(t *testing.T) {
+	// Arrange
+	var logs strings.Builder
+	req := httptest.NewRequest("GET", "/tags", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	rec := httptest.NewRecorder()
+
+	// Act
+	NewServer(newFakeBackend(), testToken, log.New(&logs, "", 0)).Handler().ServeHTTP(rec, req)
+
+	// Assert
+	if !strings.HasPrefix(logs.String(), "GET /tags 200 ") {
+		t.Errorf("log = %q, want method, path and status", logs.String())
+	}
+	var tags []struct {
+		Name  string
+		Count int
+	}
+	json.Unmarshal(rec.Body.Bytes(), &tags)
+	if len(tags) != 2 || tags[0].Name != "work" || tags[0].Count != 2 {
+		t.Errorf("GET /tags = %+v, want work (2) first", tags)
+	}
+}
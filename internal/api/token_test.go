@@ -0,0 +1,37 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestLoadOrCreateToken(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "profile", "api_token")
+
+	// Act
+	first, err := LoadOrCreateToken(path, false)
+	if err != nil {
+		t.Fatalf("LoadOrCreateToken() returned unexpected error: %v", err)
+	}
+	again, _ := LoadOrCreateToken(path, false)
+	rotated, _ := LoadOrCreateToken(path, true)
+
+	// Assert
+	if len(first) != 64 {
+		t.Errorf("token length = %d, want 64 hex characters", len(first))
+	}
+	if again != first {
+		t.Error("LoadOrCreateToken() generated a new token although one was saved")
+	}
+	if rotated == first {
+		t.Error("LoadOrCreateToken(rotate) kept the old token")
+	}
+	if runtime.GOOS != "windows" {
+		if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+			t.Errorf("token file permissions = %o, want 600", info.Mode().Perm())
+		}
+	}
+}
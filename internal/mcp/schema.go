@@ -0,0 +1,68 @@
+package mcp
+
+import (
+	"reflect"
+	"strings"
+)
+
+// schemaFor derives a JSON Schema for values of type t from the Go type and
+// its json tags. Struct fields that are neither pointers nor tagged
+// omitempty are required.
+func schemaFor(t reflect.Type) map[string]any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaFor(t.Elem())
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaFor(t.Elem())}
+	case reflect.Struct:
+		properties := map[string]any{}
+		required := []string{}
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			properties[name] = schemaFor(f.Type)
+			if f.Type.Kind() != reflect.Pointer && !strings.Contains(opts, "omitempty") {
+				required = append(required, name)
+			}
+		}
+		schema := map[string]any{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	}
+	return map[string]any{}
+}
+
+// inputSchema derives the input schema of a tool from its argument type and
+// adds descriptions to the top-level properties.
+func inputSchema(args any, descriptions map[string]string) map[string]any {
+	schema := schemaFor(reflect.TypeOf(args))
+	properties := schema["properties"].(map[string]any)
+	for name, desc := range descriptions {
+		if prop, ok := properties[name].(map[string]any); ok {
+			prop["description"] = desc
+		}
+	}
+	return schema
+}
@@ -0,0 +1,58 @@
+package mcp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+)
+
+func TestSchemaFor_TaskCreateRequest(t *testing.T) {
+	// Act
+	schema := schemaFor(reflect.TypeOf(ticktick.TaskCreateRequest{}))
+
+	// Assert
+	if got := schema["required"]; !reflect.DeepEqual(got, []string{"title"}) {
+		t.Errorf("required = %v, want [title]", got)
+	}
+	props := schema["properties"].(map[string]any)
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{"title", "string"},
+		{"priority", "integer"},
+		{"isAllDay", "boolean"},
+		{"tags", "array"},
+		{"items", "array"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			prop, ok := props[tt.field].(map[string]any)
+			if !ok {
+				t.Fatalf("property %s missing", tt.field)
+			}
+			if prop["type"] != tt.want {
+				t.Errorf("%s type = %v, want %s", tt.field, prop["type"], tt.want)
+			}
+		})
+	}
+
+	item := props["items"].(map[string]any)["items"].(map[string]any)
+	if item["type"] != "object" || item["properties"].(map[string]any)["title"] == nil {
+		t.Errorf("items schema = %v, want checklist item objects", item)
+	}
+}
+
+func TestSchemaFor_PointersAreOptional(t *testing.T) {
+	// Act
+	schema := schemaFor(reflect.TypeOf(ticktick.TaskUpdateRequest{}))
+
+	// Assert — dueDate has no omitempty but is a pointer
+	if got := schema["required"]; !reflect.DeepEqual(got, []string{"id", "projectId"}) {
+		t.Errorf("required = %v, want [id projectId]", got)
+	}
+	if prop := schema["properties"].(map[string]any)["dueDate"].(map[string]any); prop["type"] != "string" {
+		t.Errorf("dueDate type = %v, want string", prop["type"])
+	}
+}
@@ -0,0 +1,201 @@
+// Package mcp implements the Model Context Protocol server started by
+// "ticky mcp serve". It speaks JSON-RPC 2.0 over newline-delimited stdio and
+// exposes TickTick operations as tools.
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+)
+
+// protocolVersions are the MCP revisions the server speaks, newest first.
+var protocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// JSON-RPC 2.0 error codes.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+)
+
+// Backend is the subset of the TickTick client used by the tools.
+type Backend interface {
+	GetProjects() ([]ticktick.Project, error)
+	GetAllProjectIDs() ([]string, error)
+	GetProjectData(projectID string) (*ticktick.ProjectData, error)
+	GetTask(projectID, taskID string) (*ticktick.Task, error)
+	CreateTask(req *ticktick.TaskCreateRequest) (*ticktick.Task, error)
+	UpdateTask(req *ticktick.TaskUpdateRequest) (*ticktick.Task, error)
+	CompleteTask(projectID, taskID string) error
+	DeleteTask(projectID, taskID string) error
+	ResolveProject(ref string) (string, error)
+	ResolveTaskRef(ref string) (string, error)
+	FindTaskProject(taskID string) (string, error)
+}
+
+// Server answers MCP requests.
+type Server struct {
+	backend Backend
+	version string
+	tools   []tool
+}
+
+// NewServer returns a server whose tools act on backend. version is reported
+// to clients as the server version.
+func NewServer(backend Backend, version string) *Server {
+	return &Server{backend: backend, version: version, tools: tools()}
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+// Serve reads one JSON-RPC message per line from in and writes responses to
+// out until in is exhausted. Notifications are not answered.
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	reader := bufio.NewReader(in)
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			if resp := s.handle(line); resp != nil {
+				if err := enc.Encode(resp); err != nil {
+					return fmt.Errorf("failed to write response: %w", err)
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read request: %w", err)
+		}
+	}
+}
+
+// handle answers one message. It returns nil for notifications.
+func (s *Server) handle(msg []byte) *response {
+	var req request
+	if err := json.Unmarshal(msg, &req); err != nil {
+		return &response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{codeParseError, "parse error: " + err.Error()}}
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		id := req.ID
+		if id == nil {
+			id = json.RawMessage("null")
+		}
+		return &response{JSONRPC: "2.0", ID: id, Error: &rpcError{codeInvalidRequest, "invalid request"}}
+	}
+
+	result, err := s.dispatch(req.Method, req.Params)
+	if req.ID == nil {
+		return nil
+	}
+	if err != nil {
+		rpcErr, ok := err.(*rpcError)
+		if !ok {
+			rpcErr = &rpcError{codeInternalError, err.Error()}
+		}
+		return &response{JSONRPC: "2.0", ID: req.ID, Error: rpcErr}
+	}
+	if result == nil {
+		result = map[string]any{}
+	}
+	return &response{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+func (s *Server) dispatch(method string, params json.RawMessage) (any, error) {
+	switch method {
+	case "initialize":
+		var p struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		_ = json.Unmarshal(params, &p)
+		version := protocolVersions[0]
+		if slices.Contains(protocolVersions, p.ProtocolVersion) {
+			version = p.ProtocolVersion
+		}
+		return map[string]any{
+			"protocolVersion": version,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": "ticky", "version": s.version},
+		}, nil
+	case "ping":
+		return map[string]any{}, nil
+	case "notifications/initialized", "notifications/cancelled":
+		return nil, nil
+	case "tools/list":
+		return map[string]any{"tools": s.tools}, nil
+	case "tools/call":
+		var p struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &rpcError{codeInvalidParams, "invalid params: " + err.Error()}
+		}
+		return s.callTool(p.Name, p.Arguments)
+	}
+	return nil, &rpcError{codeMethodNotFound, "method not found: " + method}
+}
+
+// toolResult is the result of tools/call. Failures of the tool itself are
+// reported with IsError so the model can see and react to them.
+type toolResult struct {
+	Content           []textContent `json:"content"`
+	StructuredContent any           `json:"structuredContent,omitempty"`
+	IsError           bool          `json:"isError,omitempty"`
+}
+
+type textContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func (s *Server) callTool(name string, args json.RawMessage) (any, error) {
+	i := slices.IndexFunc(s.tools, func(t tool) bool { return t.Name == name })
+	if i < 0 {
+		return nil, &rpcError{codeInvalidParams, "unknown tool: " + name}
+	}
+	if len(args) == 0 || string(args) == "null" {
+		args = json.RawMessage("{}")
+	}
+
+	result, err := s.tools[i].call(s, args)
+	if err != nil {
+		return toolResult{Content: []textContent{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+	}
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return toolResult{Content: []textContent{{Type: "text", Text: string(data)}}, StructuredContent: result}, nil
+}
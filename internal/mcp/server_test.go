@@ -0,0 +1,219 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+)
+
+// fakeBackend serves fixed projects and tasks and records mutations.
+type fakeBackend struct {
+	projects []ticktick.Project
+	tasks    map[string][]ticktick.Task
+	fail     error
+	calls    []string
+	created  *ticktick.TaskCreateRequest
+	updated  *ticktick.TaskUpdateRequest
+}
+
+func newFakeBackend() *fakeBackend {
+	return &fakeBackend{
+		projects: []ticktick.Project{{ID: "proj-1", Name: "Work"}, {ID: "proj-2", Name: "Home"}},
+		tasks: map[string][]ticktick.Task{
+			"inbox": {{ID: "task-0", ProjectID: "inbox", Title: "Buy milk", Tags: []string{"errand"}}},
+			"proj-1": {
+				{ID: "task-1", ProjectID: "proj-1", Title: "Write report", Content: "quarterly numbers", Tags: []string{"work"}},
+				{ID: "task-2", ProjectID: "proj-1", Title: "Review PR", Priority: ticktick.PriorityHigh, Tags: []string{"work", "code"}},
+			},
+		},
+	}
+}
+
+func (f *fakeBackend) GetProjects() ([]ticktick.Project, error) {
+	return f.projects, f.fail
+}
+
+func (f *fakeBackend) GetAllProjectIDs() ([]string, error) {
+	return []string{"inbox", "proj-1", "proj-2"}, f.fail
+}
+
+func (f *fakeBackend) GetProjectData(projectID string) (*ticktick.ProjectData, error) {
+	return &ticktick.ProjectData{Tasks: f.tasks[projectID]}, f.fail
+}
+
+func (f *fakeBackend) GetTask(projectID, taskID string) (*ticktick.Task, error) {
+	for _, t := range f.tasks[projectID] {
+		if t.ID == taskID {
+			return &t, nil
+		}
+	}
+	return nil, errors.New("task not found")
+}
+
+func (f *fakeBackend) CreateTask(req *ticktick.TaskCreateRequest) (*ticktick.Task, error) {
+	if f.fail != nil {
+		return nil, f.fail
+	}
+	f.created = req
+	return &ticktick.Task{ID: "new-task", ProjectID: req.ProjectID, Title: req.Title, DueDate: req.DueDate}, nil
+}
+
+func (f *fakeBackend) UpdateTask(req *ticktick.TaskUpdateRequest) (*ticktick.Task, error) {
+	if f.fail != nil {
+		return nil, f.fail
+	}
+	f.updated = req
+	return &ticktick.Task{ID: req.ID, ProjectID: req.ProjectID, Title: req.Title, Tags: req.Tags}, nil
+}
+
+func (f *fakeBackend) CompleteTask(projectID, taskID string) error {
+	f.calls = append(f.calls, "complete "+projectID+"/"+taskID)
+	return f.fail
+}
+
+func (f *fakeBackend) DeleteTask(projectID, taskID string) error {
+	f.calls = append(f.calls, "delete "+projectID+"/"+taskID)
+	return f.fail
+}
+
+func (f *fakeBackend) ResolveProject(ref string) (string, error) {
+	for _, p := range f.projects {
+		if strings.EqualFold(p.Name, ref) || p.ID == ref {
+			return p.ID, nil
+		}
+	}
+	if ref == "" || ref == "inbox" {
+		return ref, nil
+	}
+	return "", errors.New("project not found: " + ref)
+}
+
+func (f *fakeBackend) ResolveTaskRef(ref string) (string, error) {
+	if ref == "#1" {
+		return "task-1", nil
+	}
+	return ref, nil
+}
+
+func (f *fakeBackend) FindTaskProject(taskID string) (string, error) {
+	for pid, tasks := range f.tasks {
+		for _, t := range tasks {
+			if t.ID == taskID {
+				return pid, nil
+			}
+		}
+	}
+	return "", ticktick.ErrTaskNotFound
+}
+
+// serve runs the server over the given messages, one per line, and returns
+// the decoded responses.
+func serve(t *testing.T, backend Backend, messages ...string) []map[string]any {
+	t.Helper()
+	var out strings.Builder
+	if err := NewServer(backend, "test").Serve(strings.NewReader(strings.Join(messages, "\n")), &out); err != nil {
+		t.Fatalf("Serve() returned unexpected error: %v", err)
+	}
+	var responses []map[string]any
+	dec := json.NewDecoder(strings.NewReader(out.String()))
+	for dec.More() {
+		var resp map[string]any
+		if err := dec.Decode(&resp); err != nil {
+			t.Fatalf("invalid response JSON: %v\n%s", err, out.String())
+		}
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+func TestServe_Initialize(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested string
+		want      string
+	}{
+		{"supported version", "2025-03-26", "2025-03-26"},
+		{"unknown version", "1999-01-01", protocolVersions[0]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			responses := serve(t, newFakeBackend(),
+				`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"`+tt.requested+`","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`,
+				`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+				`{"jsonrpc":"2.0","id":2,"method":"ping"}`,
+			)
+
+			// Assert
+			if len(responses) != 2 {
+				t.Fatalf("got %d responses, want 2 (notifications are not answered)", len(responses))
+			}
+			result := responses[0]["result"].(map[string]any)
+			if result["protocolVersion"] != tt.want {
+				t.Errorf("protocolVersion = %v, want %s", result["protocolVersion"], tt.want)
+			}
+			if _, ok := result["capabilities"].(map[string]any)["tools"]; !ok {
+				t.Errorf("capabilities = %v, want tools", result["capabilities"])
+			}
+			if responses[1]["id"] != float64(2) || responses[1]["result"] == nil {
+				t.Errorf("ping response = %v, want empty result for id 2", responses[1])
+			}
+		})
+	}
+}
+
+func TestServe_ProtocolErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		wantCode float64
+	}{
+		{"parse error", `{"jsonrpc":"2.0","id":1,`, codeParseError},
+		{"missing method", `{"jsonrpc":"2.0","id":1}`, codeInvalidRequest},
+		{"unknown method", `{"jsonrpc":"2.0","id":1,"method":"resources/list"}`, codeMethodNotFound},
+		{"unknown tool", `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"nope","arguments":{}}}`, codeInvalidParams},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			responses := serve(t, newFakeBackend(), tt.message)
+
+			// Assert
+			if len(responses) != 1 {
+				t.Fatalf("got %d responses, want 1", len(responses))
+			}
+			rpcErr, ok := responses[0]["error"].(map[string]any)
+			if !ok {
+				t.Fatalf("response = %v, want an error", responses[0])
+			}
+			if rpcErr["code"] != tt.wantCode {
+				t.Errorf("error code = %v, want %v", rpcErr["code"], tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestServe_ToolsList(t *testing.T) {
+	// Act
+	responses := serve(t, newFakeBackend(), `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+
+	// Assert
+	tools := responses[0]["result"].(map[string]any)["tools"].([]any)
+	var names []string
+	for _, tool := range tools {
+		tool := tool.(map[string]any)
+		names = append(names, tool["name"].(string))
+		if tool["inputSchema"].(map[string]any)["type"] != "object" {
+			t.Errorf("tool %s inputSchema is not an object schema", tool["name"])
+		}
+	}
+	want := "list_projects list_tasks search_tasks create_task update_task complete_task delete_task list_tags"
+	if got := strings.Join(names, " "); got != want {
+		t.Errorf("tools = %s, want %s", got, want)
+	}
+}
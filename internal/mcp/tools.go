@@ -0,0 +1,348 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+)
+
+// tool is an MCP tool. Its input schema is derived from the Go type its
+// arguments are decoded into.
+type tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+
+	call func(s *Server, args json.RawMessage) (any, error)
+}
+
+type noArgs struct{}
+
+type listTasksArgs struct {
+	Project string `json:"project,omitempty"`
+}
+
+type searchTasksArgs struct {
+	Filter  string `json:"filter,omitempty"`
+	Text    string `json:"text,omitempty"`
+	Project string `json:"project,omitempty"`
+}
+
+type taskRefArgs struct {
+	ID        string `json:"id"`
+	ProjectID string `json:"projectId,omitempty"`
+}
+
+const (
+	taskRefDesc    = "Task ID, unique ID prefix, #handle or alias"
+	projectRefDesc = "Project name or ID; \"inbox\" means the Inbox"
+	dueDesc        = "Due date: today, tomorrow, +3d, YYYY-MM-DD or RFC 3339"
+	priorityDesc   = "Priority: 0 none, 1 low, 3 medium, 5 high"
+)
+
+func tools() []tool {
+	updateSchema := inputSchema(ticktick.TaskUpdateRequest{}, map[string]string{
+		"id":        taskRefDesc,
+		"projectId": "Project name or ID of the task (looked up when omitted)",
+		"dueDate":   dueDesc + "; an empty string clears it",
+		"priority":  priorityDesc,
+		"tags":      "Replaces all tags of the task",
+	})
+	// The project is looked up from the task when it is not given
+	updateSchema["required"] = []string{"id"}
+
+	return []tool{
+		{
+			Name:        "list_projects",
+			Description: "List all projects (lists) of the account.",
+			InputSchema: inputSchema(noArgs{}, nil),
+			call:        (*Server).listProjects,
+		},
+		{
+			Name:        "list_tasks",
+			Description: "List the open tasks of a project, or of all projects when no project is given.",
+			InputSchema: inputSchema(listTasksArgs{}, map[string]string{"project": projectRefDesc}),
+			call:        (*Server).listTasks,
+		},
+		{
+			Name:        "search_tasks",
+			Description: "Find open tasks by filter expression and/or text, in one project or in all projects.",
+			InputSchema: inputSchema(searchTasksArgs{}, map[string]string{
+				"filter":  "Filter expression whose clauses must all match, e.g. \"tag=work priority>=medium due<today\". Fields: title, content, tag, priority, due, project; operators: = != ~ < <= > >=",
+				"text":    "Case-insensitive text to find in the title or content",
+				"project": projectRefDesc + " (default: all projects)",
+			}),
+			call: (*Server).searchTasks,
+		},
+		{
+			Name:        "create_task",
+			Description: "Create a task. Without a project, the task goes to the Inbox.",
+			InputSchema: inputSchema(ticktick.TaskCreateRequest{}, map[string]string{
+				"projectId": projectRefDesc,
+				"dueDate":   dueDesc,
+				"priority":  priorityDesc,
+			}),
+			call: (*Server).createTask,
+		},
+		{
+			Name:        "update_task",
+			Description: "Update a task. Only the given fields change; the others keep their current values.",
+			InputSchema: updateSchema,
+			call:        (*Server).updateTask,
+		},
+		{
+			Name:        "complete_task",
+			Description: "Mark a task as complete.",
+			InputSchema: inputSchema(taskRefArgs{}, map[string]string{"id": taskRefDesc, "projectId": "Project name or ID of the task (looked up when omitted)"}),
+			call:        (*Server).completeTask,
+		},
+		{
+			Name:        "delete_task",
+			Description: "Delete a task permanently.",
+			InputSchema: inputSchema(taskRefArgs{}, map[string]string{"id": taskRefDesc, "projectId": "Project name or ID of the task (looked up when omitted)"}),
+			call:        (*Server).deleteTask,
+		},
+		{
+			Name:        "list_tags",
+			Description: "List all tags in use with the number of open tasks carrying each.",
+			InputSchema: inputSchema(noArgs{}, nil),
+			call:        (*Server).listTags,
+		},
+	}
+}
+
+// decodeArgs decodes tool arguments into v, rejecting unknown fields so that
+// misspelled arguments are reported instead of ignored.
+func decodeArgs(args json.RawMessage, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(args))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	return nil
+}
+
+func (s *Server) listProjects(args json.RawMessage) (any, error) {
+	if err := decodeArgs(args, &noArgs{}); err != nil {
+		return nil, err
+	}
+	projects, err := s.backend.GetProjects()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+	return map[string]any{"projects": projects}, nil
+}
+
+// tasksIn returns the open tasks of the referenced project, or of all
+// projects when ref is empty.
+func (s *Server) tasksIn(ref string) ([]ticktick.Task, error) {
+	projectID, err := s.backend.ResolveProject(ref)
+	if err != nil {
+		return nil, err
+	}
+	projectIDs := []string{projectID}
+	if projectID == "" {
+		if projectIDs, err = s.backend.GetAllProjectIDs(); err != nil {
+			return nil, fmt.Errorf("failed to list projects: %w", err)
+		}
+	}
+
+	tasks := []ticktick.Task{}
+	for _, pid := range projectIDs {
+		pd, err := s.backend.GetProjectData(pid)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tasks: %w", err)
+		}
+		tasks = append(tasks, pd.Tasks...)
+	}
+	return tasks, nil
+}
+
+func (s *Server) listTasks(args json.RawMessage) (any, error) {
+	var a listTasksArgs
+	if err := decodeArgs(args, &a); err != nil {
+		return nil, err
+	}
+	tasks, err := s.tasksIn(a.Project)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"tasks": tasks}, nil
+}
+
+func (s *Server) searchTasks(args json.RawMessage) (any, error) {
+	var a searchTasksArgs
+	if err := decodeArgs(args, &a); err != nil {
+		return nil, err
+	}
+	filter, err := ticktick.ParseFilter(a.Filter)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := s.tasksIn(a.Project)
+	if err != nil {
+		return nil, err
+	}
+
+	text := strings.ToLower(a.Text)
+	matched := []ticktick.Task{}
+	for _, t := range tasks {
+		if !filter.Match(t) {
+			continue
+		}
+		if text != "" && !strings.Contains(strings.ToLower(t.Title+"\n"+t.Content), text) {
+			continue
+		}
+		matched = append(matched, t)
+	}
+	return map[string]any{"tasks": matched}, nil
+}
+
+func (s *Server) createTask(args json.RawMessage) (any, error) {
+	var req ticktick.TaskCreateRequest
+	if err := decodeArgs(args, &req); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.Title) == "" {
+		return nil, fmt.Errorf("title is required")
+	}
+	projectID, err := s.backend.ResolveProject(req.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	req.ProjectID = projectID
+	if req.DueDate != "" {
+		if req.DueDate, err = ticktick.ParseDate(req.DueDate); err != nil {
+			return nil, err
+		}
+	}
+
+	task, err := s.backend.CreateTask(&req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create task: %w", err)
+	}
+	return task, nil
+}
+
+func (s *Server) updateTask(args json.RawMessage) (any, error) {
+	var ref taskRefArgs
+	if err := json.Unmarshal(args, &ref); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	taskRef, err := s.resolveTask(ref)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.backend.GetTask(taskRef.ProjectID, taskRef.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get existing task: %w", err)
+	}
+
+	// Decoding over the current values changes only the given fields
+	req := existing.UpdateRequest()
+	if err := decodeArgs(args, req); err != nil {
+		return nil, err
+	}
+	req.ID, req.ProjectID = existing.ID, existing.ProjectID
+	if req.DueDate != nil && *req.DueDate != "" {
+		due, err := ticktick.ParseDate(*req.DueDate)
+		if err != nil {
+			return nil, err
+		}
+		req.DueDate = &due
+	}
+
+	task, err := s.backend.UpdateTask(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update task: %w", err)
+	}
+	return task, nil
+}
+
+func (s *Server) completeTask(args json.RawMessage) (any, error) {
+	ref, err := s.decodeTaskRef(args)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.backend.CompleteTask(ref.ProjectID, ref.ID); err != nil {
+		return nil, fmt.Errorf("failed to complete task: %w", err)
+	}
+	return map[string]any{"id": ref.ID, "projectId": ref.ProjectID, "status": "completed"}, nil
+}
+
+func (s *Server) deleteTask(args json.RawMessage) (any, error) {
+	ref, err := s.decodeTaskRef(args)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.backend.DeleteTask(ref.ProjectID, ref.ID); err != nil {
+		return nil, fmt.Errorf("failed to delete task: %w", err)
+	}
+	return map[string]any{"id": ref.ID, "projectId": ref.ProjectID, "status": "deleted"}, nil
+}
+
+func (s *Server) decodeTaskRef(args json.RawMessage) (ticktick.TaskRef, error) {
+	var a taskRefArgs
+	if err := decodeArgs(args, &a); err != nil {
+		return ticktick.TaskRef{}, err
+	}
+	return s.resolveTask(a)
+}
+
+// resolveTask turns a task reference and optional project reference into
+// IDs, looking up the project of the task when none is given.
+func (s *Server) resolveTask(a taskRefArgs) (ticktick.TaskRef, error) {
+	if strings.TrimSpace(a.ID) == "" {
+		return ticktick.TaskRef{}, fmt.Errorf("id is required")
+	}
+	id, err := s.backend.ResolveTaskRef(a.ID)
+	if err != nil {
+		return ticktick.TaskRef{}, err
+	}
+	projectID, err := s.backend.ResolveProject(a.ProjectID)
+	if err != nil {
+		return ticktick.TaskRef{}, err
+	}
+	if projectID == "" {
+		if projectID, err = s.backend.FindTaskProject(id); err != nil {
+			return ticktick.TaskRef{}, err
+		}
+	}
+	return ticktick.TaskRef{ID: id, ProjectID: projectID}, nil
+}
+
+func (s *Server) listTags(args json.RawMessage) (any, error) {
+	if err := decodeArgs(args, &noArgs{}); err != nil {
+		return nil, err
+	}
+	tasks, err := s.tasksIn("")
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for _, t := range tasks {
+		for _, tag := range t.Tags {
+			counts[tag]++
+		}
+	}
+	type tagInfo struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+	tags := []tagInfo{}
+	for name, count := range counts {
+		tags = append(tags, tagInfo{Name: name, Count: count})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+		return tags[i].Name < tags[j].Name
+	})
+	return map[string]any{"tags": tags}, nil
+}
@@ -0,0 +1,159 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+)
+
+// callTool invokes a tool through the protocol and returns its result.
+func callTool(t *testing.T, backend Backend, name, args string) map[string]any {
+	t.Helper()
+	responses := serve(t, backend, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"`+name+`","arguments":`+args+`}}`)
+	result, ok := responses[0]["result"].(map[string]any)
+	if !ok {
+		t.Fatalf("tools/call %s response = %v, want a result", name, responses[0])
+	}
+	return result
+}
+
+// structured decodes the structured content of a successful tool result.
+func structured(t *testing.T, result map[string]any, v any) {
+	t.Helper()
+	if result["isError"] == true {
+		t.Fatalf("tool failed: %v", result["content"])
+	}
+	data, _ := json.Marshal(result["structuredContent"])
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("invalid structured content: %v", err)
+	}
+}
+
+func TestSearchTasks(t *testing.T) {
+	tests := []struct {
+		name string
+		args string
+		want []string
+	}{
+		{"all tasks", `{}`, []string{"task-0", "task-1", "task-2"}},
+		{"filter", `{"filter":"tag=work priority>=medium"}`, []string{"task-2"}},
+		{"text in content", `{"text":"QUARTERLY"}`, []string{"task-1"}},
+		{"project by name", `{"project":"work","text":"r"}`, []string{"task-1", "task-2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			result := callTool(t, newFakeBackend(), "search_tasks", tt.args)
+
+			// Assert
+			var got struct{ Tasks []ticktick.Task }
+			structured(t, result, &got)
+			var ids []string
+			for _, task := range got.Tasks {
+				ids = append(ids, task.ID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("search_tasks(%s) = %v, want %v", tt.args, ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateTask_ResolvesProjectAndDue(t *testing.T) {
+	// Arrange
+	backend := newFakeBackend()
+
+	// Act
+	result := callTool(t, backend, "create_task", `{"title":"Plan sprint","projectId":"work","dueDate":"2026-03-01","tags":["planning"]}`)
+
+	// Assert
+	var task ticktick.Task
+	structured(t, result, &task)
+	if task.ID != "new-task" {
+		t.Errorf("created task = %+v, want new-task", task)
+	}
+	req := backend.created
+	if req.ProjectID != "proj-1" || !strings.HasPrefix(req.DueDate, "2026-03-01T") || len(req.Tags) != 1 {
+		t.Errorf("create request = %+v, want project proj-1, due 2026-03-01 and one tag", req)
+	}
+}
+
+func TestUpdateTask_ChangesOnlyGivenFields(t *testing.T) {
+	// Arrange
+	backend := newFakeBackend()
+
+	// Act — the task is referenced by handle and its project is looked up
+	result := callTool(t, backend, "update_task", `{"id":"#1","priority":5}`)
+
+	// Assert
+	var task ticktick.Task
+	structured(t, result, &task)
+	req := backend.updated
+	if req == nil {
+		t.Fatal("UpdateTask was not called")
+	}
+	if req.ID != "task-1" || req.ProjectID != "proj-1" {
+		t.Errorf("update request targets %s/%s, want proj-1/task-1", req.ProjectID, req.ID)
+	}
+	if *req.Priority != ticktick.PriorityHigh {
+		t.Errorf("priority = %d, want %d", *req.Priority, ticktick.PriorityHigh)
+	}
+	if req.Title != "Write report" || req.Content != "quarterly numbers" || len(req.Tags) != 1 {
+		t.Errorf("update request = %+v, want the other fields unchanged", req)
+	}
+}
+
+func TestCompleteAndDeleteTask(t *testing.T) {
+	// Arrange
+	backend := newFakeBackend()
+
+	// Act
+	callTool(t, backend, "complete_task", `{"id":"task-2"}`)
+	callTool(t, backend, "delete_task", `{"id":"task-0","projectId":"inbox"}`)
+
+	// Assert
+	want := "complete proj-1/task-2,delete inbox/task-0"
+	if got := strings.Join(backend.calls, ","); got != want {
+		t.Errorf("calls = %s, want %s", got, want)
+	}
+}
+
+func TestCallTool_ErrorResults(t *testing.T) {
+	tests := []struct {
+		name    string
+		fail    error
+		tool    string
+		args    string
+		wantMsg string
+	}{
+		{"unknown argument", nil, "list_tasks", `{"projet":"work"}`, "unknown field"},
+		{"missing title", nil, "create_task", `{"title":" "}`, "title is required"},
+		{"invalid filter", nil, "search_tasks", `{"filter":"size>3"}`, "filter"},
+		{"unknown task", nil, "complete_task", `{"id":"missing"}`, "task not found"},
+		{"backend failure", errors.New("API error 500"), "create_task", `{"title":"x"}`, "API error 500"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			backend := newFakeBackend()
+			backend.fail = tt.fail
+
+			// Act
+			result := callTool(t, backend, tt.tool, tt.args)
+
+			// Assert
+			if result["isError"] != true {
+				t.Fatalf("result = %v, want isError", result)
+			}
+			text := result["content"].([]any)[0].(map[string]any)["text"].(string)
+			if !strings.Contains(text, tt.wantMsg) {
+				t.Errorf("error text = %q, want it to contain %q", text, tt.wantMsg)
+			}
+		})
+	}
+}
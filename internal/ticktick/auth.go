@@ -1,8 +1,10 @@
 package ticktick
 
 import (
+	"bufio"
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -13,59 +15,127 @@ import (
 	"os"
 	"os/exec"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 )
 
-const (
-	authURL     = "https://ticktick.com/oauth/authorize"
-	redirectURI = "http://localhost:18080/callback"
-	listenAddr  = "localhost:18080"
-)
+const authURL = "https://ticktick.com/oauth/authorize"
+
+// DefaultRedirectURI is used unless another redirect URI is configured. It
+// must match the redirect URI registered for the TickTick app.
+const DefaultRedirectURI = "http://localhost:18080/callback"
 
 var tokenURL = "https://ticktick.com/oauth/token"
 
-// OAuthLogin performs the OAuth 2.0 Authorization Code flow.
-func OAuthLogin() (*OAuthToken, error) {
+// clientCredentials returns the OAuth client ID and secret. The environment
+// variables take precedence over client_id and client_secret in the config
+// file of the active profile.
+func clientCredentials() (string, string, error) {
 	clientID := os.Getenv("TICKTICK_CLIENT_ID")
 	clientSecret := os.Getenv("TICKTICK_CLIENT_SECRET")
 	if clientID == "" || clientSecret == "" {
-		return nil, fmt.Errorf("TICKTICK_CLIENT_ID and TICKTICK_CLIENT_SECRET must be set")
+		if cfg, err := LoadConfig(); err == nil {
+			if clientID == "" {
+				clientID = cfg.ClientID
+			}
+			if clientSecret == "" {
+				clientSecret = cfg.ClientSecret
+			}
+		}
+	}
+	if clientID == "" || clientSecret == "" {
+		return "", "", fmt.Errorf("TICKTICK_CLIENT_ID and TICKTICK_CLIENT_SECRET must be set (or client_id and client_secret in the profile config)")
+	}
+	return clientID, clientSecret, nil
+}
+
+// LoginOptions configures an OAuth login.
+type LoginOptions struct {
+	// RedirectURI must match the redirect URI registered for the TickTick
+	// app. The local callback server listens on its host and port. Empty
+	// means DefaultRedirectURI.
+	RedirectURI string
+}
+
+// oauthFlow holds the parameters of one authorization attempt.
+type oauthFlow struct {
+	clientID     string
+	clientSecret string
+	redirect     *url.URL
+	state        string
+	verifier     string // PKCE code verifier
+}
+
+func newOAuthFlow(opts LoginOptions) (*oauthFlow, error) {
+	clientID, clientSecret, err := clientCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	redirectURI := opts.RedirectURI
+	if redirectURI == "" {
+		redirectURI = DefaultRedirectURI
+	}
+	redirect, err := parseRedirectURI(redirectURI)
+	if err != nil {
+		return nil, err
 	}
 
 	state, err := generateState()
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate state: %w", err)
 	}
+	verifier, err := generateCodeVerifier()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate PKCE verifier: %w", err)
+	}
+
+	return &oauthFlow{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		redirect:     redirect,
+		state:        state,
+		verifier:     verifier,
+	}, nil
+}
+
+// authorizationURL returns the URL that asks the user to authorize ticky.
+func (f *oauthFlow) authorizationURL() string {
+	return fmt.Sprintf("%s?client_id=%s&scope=tasks:read+tasks:write&redirect_uri=%s&state=%s&response_type=code&code_challenge=%s&code_challenge_method=S256",
+		authURL, url.QueryEscape(f.clientID), url.QueryEscape(f.redirect.String()), url.QueryEscape(f.state), codeChallenge(f.verifier))
+}
+
+func (f *oauthFlow) exchange(code string) (*OAuthToken, error) {
+	return exchangeToken(f.clientID, f.clientSecret, code, f.redirect.String(), f.verifier)
+}
+
+// OAuthLogin performs the OAuth 2.0 Authorization Code flow with PKCE,
+// receiving the code on a local callback server.
+func OAuthLogin(opts LoginOptions) (*OAuthToken, error) {
+	flow, err := newOAuthFlow(opts)
+	if err != nil {
+		return nil, err
+	}
 
 	codeCh := make(chan string, 1)
 	errCh := make(chan error, 1)
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Query().Get("state") != state {
-			errCh <- fmt.Errorf("state mismatch")
-			http.Error(w, "State mismatch", http.StatusBadRequest)
-			return
-		}
-		if errParam := r.URL.Query().Get("error"); errParam != "" {
-			errCh <- fmt.Errorf("OAuth error: %s", errParam)
-			http.Error(w, "Authorization failed", http.StatusBadRequest)
-			return
-		}
-		code := r.URL.Query().Get("code")
-		if code == "" {
-			errCh <- fmt.Errorf("no code in callback")
-			http.Error(w, "No code", http.StatusBadRequest)
+	mux.HandleFunc(flow.redirect.Path, func(w http.ResponseWriter, r *http.Request) {
+		code, err := parseCallback(r.URL.Query(), flow.state)
+		if err != nil {
+			errCh <- err
+			http.Error(w, "Authorization failed: "+err.Error(), http.StatusBadRequest)
 			return
 		}
 		fmt.Fprint(w, "<html><body><h2>Authentication successful!</h2><p>You can close this window.</p></body></html>")
 		codeCh <- code
 	})
 
-	listener, err := net.Listen("tcp", listenAddr)
+	listener, err := net.Listen("tcp", flow.redirect.Host)
 	if err != nil {
-		return nil, fmt.Errorf("failed to start local server: %w", err)
+		return nil, fmt.Errorf("failed to start local server on %s: %w", flow.redirect.Host, err)
 	}
 
 	server := &http.Server{Handler: mux}
@@ -76,8 +146,7 @@ func OAuthLogin() (*OAuthToken, error) {
 		server.Shutdown(ctx)
 	}()
 
-	authURLFull := fmt.Sprintf("%s?client_id=%s&scope=tasks:read+tasks:write&redirect_uri=%s&state=%s&response_type=code",
-		authURL, url.QueryEscape(clientID), url.QueryEscape(redirectURI), url.QueryEscape(state))
+	authURLFull := flow.authorizationURL()
 
 	fmt.Println("Opening browser for authentication...")
 	fmt.Printf("If browser doesn't open, visit: %s\n", authURLFull)
@@ -85,7 +154,7 @@ func OAuthLogin() (*OAuthToken, error) {
 
 	select {
 	case code := <-codeCh:
-		return exchangeToken(clientID, clientSecret, code)
+		return flow.exchange(code)
 	case err := <-errCh:
 		return nil, err
 	case <-time.After(5 * time.Minute):
@@ -93,11 +162,138 @@ func OAuthLogin() (*OAuthToken, error) {
 	}
 }
 
-func exchangeToken(clientID, clientSecret, code string) (*OAuthToken, error) {
+// OAuthLoginNoBrowser performs the Authorization Code flow without a local
+// browser or callback server. The authorization URL is written to out, and
+// the redirect URL (or just the code) the browser ended up at is read from in.
+func OAuthLoginNoBrowser(opts LoginOptions, in io.Reader, out io.Writer) (*OAuthToken, error) {
+	flow, err := newOAuthFlow(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintln(out, "Open this URL in a browser on any machine and authorize ticky:")
+	fmt.Fprintf(out, "\n  %s\n\n", flow.authorizationURL())
+	fmt.Fprintf(out, "The browser is then sent to %s, which will fail to load.\n", flow.redirect)
+	fmt.Fprint(out, "Paste the full URL from the address bar (or just the code): ")
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && line == "" {
+		return nil, fmt.Errorf("failed to read redirect URL: %w", err)
+	}
+	code, err := ParseRedirectInput(line, flow.state)
+	if err != nil {
+		return nil, err
+	}
+	return flow.exchange(code)
+}
+
+// RedirectURI builds the OAuth redirect URI from a host, port and path.
+// Empty parts are taken from redirect_host, redirect_port and redirect_path.
+func RedirectURI(host, port, path string) (string, error) {
+	parts := []struct {
+		value *string
+		key   string
+	}{{&host, "redirect_host"}, {&port, "redirect_port"}, {&path, "redirect_path"}}
+	for _, p := range parts {
+		if *p.value != "" {
+			continue
+		}
+		v, _, err := ConfigValue(p.key)
+		if err != nil {
+			return "", err
+		}
+		*p.value = v
+	}
+	if err := validateRedirectPath(path); err != nil {
+		return "", err
+	}
+
+	uri := (&url.URL{Scheme: "http", Host: net.JoinHostPort(host, port), Path: path}).String()
+	if _, err := parseRedirectURI(uri); err != nil {
+		return "", err
+	}
+	return uri, nil
+}
+
+// parseRedirectURI checks that uri can be served by the local callback
+// server: plain http with an explicit port and a non-root path.
+func parseRedirectURI(uri string) (*url.URL, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redirect URI %q: %w", uri, err)
+	}
+	if u.Scheme != "http" || u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid redirect URI %q: must be http://host:port/path", uri)
+	}
+	if err := validatePort(u.Port()); err != nil {
+		return nil, fmt.Errorf("invalid redirect URI %q: %w", uri, err)
+	}
+	if err := validateRedirectPath(u.Path); err != nil {
+		return nil, fmt.Errorf("invalid redirect URI %q: %w", uri, err)
+	}
+	return u, nil
+}
+
+func validatePort(port string) error {
+	n, err := strconv.Atoi(port)
+	if err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("invalid port %q: must be 1-65535", port)
+	}
+	return nil
+}
+
+func validateRedirectPath(path string) error {
+	if !strings.HasPrefix(path, "/") || path == "/" {
+		return fmt.Errorf("invalid path %q: must start with / and not be /", path)
+	}
+	return nil
+}
+
+// parseCallback validates the query of an OAuth redirect and returns the
+// authorization code.
+func parseCallback(query url.Values, state string) (string, error) {
+	if query.Get("state") != state {
+		return "", fmt.Errorf("state mismatch")
+	}
+	if errParam := query.Get("error"); errParam != "" {
+		return "", fmt.Errorf("OAuth error: %s", errParam)
+	}
+	code := query.Get("code")
+	if code == "" {
+		return "", fmt.Errorf("no code in callback")
+	}
+	return code, nil
+}
+
+// ParseRedirectInput extracts the authorization code from what the user
+// pasted: the full redirect URL, its query string, or the bare code. When
+// the input carries a query, it is validated like the callback handler does.
+func ParseRedirectInput(input, state string) (string, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", fmt.Errorf("no redirect URL or code given")
+	}
+	if !strings.ContainsAny(input, "?=&") {
+		return input, nil
+	}
+
+	rawQuery := input
+	if u, err := url.Parse(input); err == nil && u.RawQuery != "" {
+		rawQuery = u.RawQuery
+	}
+	query, err := url.ParseQuery(strings.TrimPrefix(rawQuery, "?"))
+	if err != nil {
+		return "", fmt.Errorf("invalid redirect URL: %w", err)
+	}
+	return parseCallback(query, state)
+}
+
+func exchangeToken(clientID, clientSecret, code, redirectURI, verifier string) (*OAuthToken, error) {
 	data := url.Values{
-		"code":         {code},
-		"grant_type":   {"authorization_code"},
-		"redirect_uri": {redirectURI},
+		"code":          {code},
+		"grant_type":    {"authorization_code"},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {verifier},
 	}
 
 	req, err := http.NewRequest("POST", tokenURL, strings.NewReader(data.Encode()))
@@ -132,10 +328,9 @@ func exchangeToken(clientID, clientSecret, code string) (*OAuthToken, error) {
 
 // RefreshAccessToken refreshes the access token using the refresh token.
 func RefreshAccessToken(refreshToken string) (*OAuthToken, error) {
-	clientID := os.Getenv("TICKTICK_CLIENT_ID")
-	clientSecret := os.Getenv("TICKTICK_CLIENT_SECRET")
-	if clientID == "" || clientSecret == "" {
-		return nil, fmt.Errorf("TICKTICK_CLIENT_ID and TICKTICK_CLIENT_SECRET must be set")
+	clientID, clientSecret, err := clientCredentials()
+	if err != nil {
+		return nil, err
 	}
 
 	data := url.Values{
@@ -181,6 +376,22 @@ func generateState() (string, error) {
 	return base64.URLEncoding.EncodeToString(b), nil
 }
 
+// generateCodeVerifier returns a PKCE code verifier (RFC 7636): 43
+// characters of unpadded base64url.
+func generateCodeVerifier() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// codeChallenge returns the S256 PKCE challenge for a verifier.
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
 func openBrowser(url string) {
 	var cmd *exec.Cmd
 	switch runtime.GOOS {
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"strings"
 	"testing"
@@ -68,6 +69,9 @@ func TestExchangeToken_Success(t *testing.T) {
 		if !strings.Contains(bodyStr, "redirect_uri=") {
 			t.Errorf("body missing redirect_uri, got %q", bodyStr)
 		}
+		if !strings.Contains(bodyStr, "code_verifier=test-verifier") {
+			t.Errorf("body missing code_verifier=test-verifier, got %q", bodyStr)
+		}
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(wantToken)
@@ -78,7 +82,7 @@ func TestExchangeToken_Success(t *testing.T) {
 	defer restore()
 
 	// Act
-	got, err := ticktick.ExchangeToken("test-client-id", "test-client-secret", "test-code")
+	got, err := ticktick.ExchangeToken("test-client-id", "test-client-secret", "test-code", "test-verifier")
 
 	// Assert
 	if err != nil {
@@ -110,7 +114,7 @@ func TestExchangeToken_HTTPError(t *testing.T) {
 	defer restore()
 
 	// Act
-	_, err := ticktick.ExchangeToken("bad-id", "bad-secret", "test-code")
+	_, err := ticktick.ExchangeToken("bad-id", "bad-secret", "test-code", "test-verifier")
 
 	// Assert
 	if err == nil {
@@ -133,7 +137,7 @@ func TestExchangeToken_InvalidJSON(t *testing.T) {
 	defer restore()
 
 	// Act
-	_, err := ticktick.ExchangeToken("test-client-id", "test-client-secret", "test-code")
+	_, err := ticktick.ExchangeToken("test-client-id", "test-client-secret", "test-code", "test-verifier")
 
 	// Assert
 	if err == nil {
@@ -163,7 +167,7 @@ func TestExchangeToken_ExpiresAtCalculated(t *testing.T) {
 	before := time.Now().Unix()
 
 	// Act
-	got, err := ticktick.ExchangeToken("test-client-id", "test-client-secret", "test-code")
+	got, err := ticktick.ExchangeToken("test-client-id", "test-client-secret", "test-code", "test-verifier")
 
 	// Assert
 	after := time.Now().Unix()
@@ -251,7 +255,8 @@ func TestRefreshAccessToken_Success(t *testing.T) {
 }
 
 func TestRefreshAccessToken_MissingEnvVars(t *testing.T) {
-	// Arrange: ensure env vars are not set
+	// Arrange: ensure env vars are not set and no profile config provides them
+	t.Setenv("HOME", t.TempDir())
 	os.Unsetenv("TICKTICK_CLIENT_ID")
 	os.Unsetenv("TICKTICK_CLIENT_SECRET")
 
@@ -342,3 +347,160 @@ func TestGenerateState_Base64(t *testing.T) {
 		t.Errorf("decoded bytes length = %d, want 16", len(decoded))
 	}
 }
+
+// --- no-browser login ---
+
+func TestParseRedirectInput(t *testing.T) {
+	const state = "st8=="
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{"full URL", "http://localhost:18080/callback?code=abc&state=st8%3D%3D\n", "abc", ""},
+		{"query string", "?code=abc&state=st8%3D%3D", "abc", ""},
+		{"bare code", "  abc \n", "abc", ""},
+		{"state mismatch", "http://localhost:18080/callback?code=abc&state=other", "", "state mismatch"},
+		{"missing state", "http://localhost:18080/callback?code=abc", "", "state mismatch"},
+		{"oauth error", "http://localhost:18080/callback?error=access_denied&state=st8%3D%3D", "", "access_denied"},
+		{"no code", "http://localhost:18080/callback?state=st8%3D%3D", "", "no code"},
+		{"empty", "\n", "", "no redirect URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ticktick.ParseRedirectInput(tt.input, state)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseRedirectInput() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParseRedirectInput() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+// pasteReader answers the login prompt with the redirect URL built from the
+// authorization URL printed so far.
+type pasteReader struct {
+	out  *strings.Builder
+	done bool
+}
+
+func (r *pasteReader) Read(p []byte) (int, error) {
+	if r.done {
+		return 0, io.EOF
+	}
+	r.done = true
+	var state string
+	for _, field := range strings.Fields(r.out.String()) {
+		if u, err := url.Parse(field); err == nil && u.Query().Get("state") != "" {
+			state = u.Query().Get("state")
+		}
+	}
+	redirect := "http://127.0.0.1:9999/oauth?code=pasted-code&state=" + url.QueryEscape(state) + "\n"
+	return copy(p, redirect), nil
+}
+
+func TestOAuthLoginNoBrowser_ExchangesPastedCode(t *testing.T) {
+	// Arrange
+	setupAuthEnv(t)
+	var gotCode, gotRedirect, gotVerifier string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		gotCode = r.PostForm.Get("code")
+		gotRedirect = r.PostForm.Get("redirect_uri")
+		gotVerifier = r.PostForm.Get("code_verifier")
+		json.NewEncoder(w).Encode(ticktick.OAuthToken{AccessToken: "headless-token"})
+	}))
+	defer server.Close()
+	defer ticktick.SetTokenURL(server.URL)()
+	var out strings.Builder
+	opts := ticktick.LoginOptions{RedirectURI: "http://127.0.0.1:9999/oauth"}
+
+	// Act
+	token, err := ticktick.OAuthLoginNoBrowser(opts, &pasteReader{out: &out}, &out)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("OAuthLoginNoBrowser() returned unexpected error: %v", err)
+	}
+	if gotCode != "pasted-code" || token.AccessToken != "headless-token" {
+		t.Errorf("exchanged code %q for token %q, want pasted-code and headless-token", gotCode, token.AccessToken)
+	}
+	if !strings.Contains(out.String(), "https://ticktick.com/oauth/authorize?client_id=test-client-id") {
+		t.Errorf("output does not contain the authorization URL: %q", out.String())
+	}
+	if gotRedirect != opts.RedirectURI {
+		t.Errorf("redirect_uri = %q, want %q", gotRedirect, opts.RedirectURI)
+	}
+	challenge := "code_challenge=" + ticktick.CodeChallenge(gotVerifier) + "&code_challenge_method=S256"
+	if gotVerifier == "" || !strings.Contains(out.String(), challenge) {
+		t.Errorf("authorization URL does not carry the S256 challenge of verifier %q: %q", gotVerifier, out.String())
+	}
+}
+
+func TestCodeChallenge_S256(t *testing.T) {
+	// Arrange — BASE64URL(SHA256(verifier)) without padding
+	verifier := "test-verifier"
+
+	// Act
+	got := ticktick.CodeChallenge(verifier)
+
+	// Assert
+	if want := "JBbiqONGWPaAmwXk_8bT6UnlPfrn65D32eZlJS-zGG0"; got != want {
+		t.Errorf("CodeChallenge() = %q, want %q", got, want)
+	}
+}
+
+func TestRedirectURI(t *testing.T) {
+	tests := []struct {
+		name             string
+		host, port, path string
+		env              map[string]string
+		want             string
+		wantErr          bool
+	}{
+		{name: "defaults", want: "http://localhost:18080/callback"},
+		{name: "flags", host: "127.0.0.1", port: "8085", path: "/oauth/cb", want: "http://127.0.0.1:8085/oauth/cb"},
+		{name: "config", env: map[string]string{"TICKY_REDIRECT_PORT": "9000"}, want: "http://localhost:9000/callback"},
+		{name: "flag overrides config", port: "9100", env: map[string]string{"TICKY_REDIRECT_PORT": "9000"}, want: "http://localhost:9100/callback"},
+		{name: "ipv6 host", host: "::1", want: "http://[::1]:18080/callback"},
+		{name: "port out of range", port: "70000", wantErr: true},
+		{name: "port not a number", port: "http", wantErr: true},
+		{name: "relative path", path: "callback", wantErr: true},
+		{name: "root path", path: "/", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			t.Setenv("HOME", t.TempDir())
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			// Act
+			got, err := ticktick.RedirectURI(tt.host, tt.port, tt.path)
+
+			// Assert
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("RedirectURI() = %q, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RedirectURI() returned unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("RedirectURI() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,307 @@
+package ticktick
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// BackupVersion is the current backup archive format version.
+const BackupVersion = 1
+
+// restoredTasksFile maps backup task IDs to the IDs of the tasks restored
+// from them.
+const restoredTasksFile = "restored_tasks.json"
+
+// Backup is a point-in-time snapshot of all projects and their tasks.
+type Backup struct {
+	Version   int           `json:"version"`
+	CreatedAt time.Time     `json:"createdAt"`
+	InboxID   string        `json:"inboxId"`
+	Projects  []ProjectData `json:"projects"`
+}
+
+// Snapshot fetches every project (Inbox first) together with its tasks.
+// Project names are filled in, with the Inbox named "Inbox".
+func (c *Client) Snapshot() (*Backup, error) {
+	projects, err := c.GetProjects()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+	inboxID, err := c.DiscoverInboxID()
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Backup{
+		Version:   BackupVersion,
+		CreatedAt: time.Now().UTC(),
+		InboxID:   inboxID,
+	}
+	all := []Project{{ID: inboxID, Name: "Inbox"}}
+	for _, p := range projects {
+		if p.ID != inboxID {
+			all = append(all, p)
+		}
+	}
+	for _, p := range all {
+		pd, err := c.GetProjectData(p.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get project %s: %w", p.ID, err)
+		}
+		pd.Project = p
+		b.Projects = append(b.Projects, *pd)
+	}
+	return b, nil
+}
+
+// BackupFileName returns the default archive name for a backup taken at t.
+func BackupFileName(t time.Time, compress bool) string {
+	name := "ticky-backup-" + t.UTC().Format("20060102T150405Z") + ".json"
+	if compress {
+		name += ".gz"
+	}
+	return name
+}
+
+// WriteBackup encodes b as indented JSON, gzip-compressed when compress is set.
+func WriteBackup(w io.Writer, b *Backup, compress bool) error {
+	if !compress {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(b)
+	}
+
+	zw := gzip.NewWriter(w)
+	enc := json.NewEncoder(zw)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(b); err != nil {
+		zw.Close()
+		return err
+	}
+	return zw.Close()
+}
+
+// ReadBackup decodes a backup archive, transparently handling gzip compression.
+func ReadBackup(r io.Reader) (*Backup, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open gzip archive: %w", err)
+		}
+		defer zr.Close()
+		r = zr
+	} else {
+		r = br
+	}
+
+	var b Backup
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return nil, fmt.Errorf("failed to parse backup: %w", err)
+	}
+	if b.Version < 1 || b.Version > BackupVersion {
+		return nil, fmt.Errorf("unsupported backup version %d (supported: 1-%d)", b.Version, BackupVersion)
+	}
+	return &b, nil
+}
+
+// RestoreOptions selects what Restore recreates.
+type RestoreOptions struct {
+	Projects []string // project IDs or names (case-insensitive); empty restores all
+	Tags     []string // restore only tasks carrying at least one of these tags
+	DryRun   bool
+}
+
+// RestoreAction records what Restore did (or would do) for one project or task.
+type RestoreAction struct {
+	Kind      string `json:"kind"` // "project" or "task"
+	OldID     string `json:"old_id"`
+	NewID     string `json:"new_id,omitempty"`
+	Name      string `json:"name"`
+	ProjectID string `json:"project_id,omitempty"`
+	Status    string `json:"status"` // "exists", "created", "would-create" or "failed"
+	Error     string `json:"error,omitempty"`
+}
+
+// Restore recreates projects and tasks from b that are missing in live.
+// Projects are matched by ID, then by name; the backup's Inbox maps to the
+// live Inbox. Tasks are matched by ID, or by the ID of the task an earlier
+// restore created from them, so restoring the same archive twice does not
+// duplicate tasks. The returned actions form an ID mapping report; API
+// failures are recorded per action rather than aborting the restore.
+func (c *Client) Restore(b, live *Backup, opts RestoreOptions) ([]RestoreAction, error) {
+	restored, err := loadRestoredTasks()
+	if err != nil {
+		return nil, err
+	}
+
+	liveByID := make(map[string]bool)
+	liveByName := make(map[string]string)
+	liveTasks := make(map[string]bool)
+	for _, pd := range live.Projects {
+		liveByID[pd.Project.ID] = true
+		liveByName[strings.ToLower(pd.Project.Name)] = pd.Project.ID
+		for _, t := range pd.Tasks {
+			liveTasks[t.ID] = true
+		}
+	}
+
+	var actions []RestoreAction
+	for _, pd := range b.Projects {
+		if !matchesProject(pd.Project, opts.Projects) {
+			continue
+		}
+
+		pa := RestoreAction{Kind: "project", OldID: pd.Project.ID, Name: pd.Project.Name, Status: "exists"}
+		switch {
+		case pd.Project.ID == b.InboxID:
+			pa.NewID = live.InboxID
+		case liveByID[pd.Project.ID]:
+			pa.NewID = pd.Project.ID
+		case liveByName[strings.ToLower(pd.Project.Name)] != "":
+			pa.NewID = liveByName[strings.ToLower(pd.Project.Name)]
+		case opts.DryRun:
+			pa.Status = "would-create"
+		default:
+			p, err := c.CreateProject(&ProjectCreateRequest{
+				Name:     pd.Project.Name,
+				Color:    pd.Project.Color,
+				ViewMode: pd.Project.ViewMode,
+				Kind:     pd.Project.Kind,
+			})
+			if err != nil {
+				pa.Status = "failed"
+				pa.Error = err.Error()
+				actions = append(actions, pa)
+				continue
+			}
+			pa.NewID = p.ID
+			pa.Status = "created"
+		}
+		actions = append(actions, pa)
+
+		for _, t := range pd.Tasks {
+			if !hasAnyTag(t.Tags, opts.Tags) {
+				continue
+			}
+
+			ta := RestoreAction{Kind: "task", OldID: t.ID, Name: t.Title, ProjectID: pa.NewID}
+			switch {
+			case liveTasks[t.ID]:
+				ta.NewID = t.ID
+				ta.Status = "exists"
+			case liveTasks[restored[t.ID]]:
+				ta.NewID = restored[t.ID]
+				ta.Status = "exists"
+			case opts.DryRun:
+				ta.Status = "would-create"
+			default:
+				created, err := c.CreateTask(restoreRequest(t, pa.NewID))
+				if err == nil {
+					ta.NewID = created.ID
+					restored[t.ID] = created.ID
+					if err := saveRestoredTasks(restored); err != nil {
+						ta.Status = "created"
+						return append(actions, ta), err
+					}
+					if t.Status == TaskStatusCompleted {
+						err = c.CompleteTask(created.ProjectID, created.ID)
+					}
+				}
+				if err != nil {
+					ta.Status = "failed"
+					ta.Error = err.Error()
+					break
+				}
+				ta.Status = "created"
+			}
+			actions = append(actions, ta)
+		}
+	}
+	return actions, nil
+}
+
+func loadRestoredTasks() (map[string]string, error) {
+	restored := make(map[string]string)
+	data, err := os.ReadFile(filepath.Join(configDir(), restoredTasksFile))
+	if os.IsNotExist(err) {
+		return restored, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", restoredTasksFile, err)
+	}
+	if err := json.Unmarshal(data, &restored); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", restoredTasksFile, err)
+	}
+	return restored, nil
+}
+
+func saveRestoredTasks(restored map[string]string) error {
+	dir := configDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to save restored task IDs: %w", err)
+	}
+	data, err := json.MarshalIndent(restored, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode restored task IDs: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(dir, restoredTasksFile), data, 0600); err != nil {
+		return fmt.Errorf("failed to save restored task IDs: %w", err)
+	}
+	return nil
+}
+
+// restoreRequest builds a creation request that reproduces t in projectID.
+func restoreRequest(t Task, projectID string) *TaskCreateRequest {
+	req := &TaskCreateRequest{
+		Title:      t.Title,
+		ProjectID:  projectID,
+		Content:    t.Content,
+		Desc:       t.Desc,
+		Priority:   t.Priority,
+		DueDate:    t.DueDate,
+		StartDate:  t.StartDate,
+		Tags:       t.Tags,
+		TimeZone:   t.TimeZone,
+		IsAllDay:   t.IsAllDay,
+		RepeatFlag: t.RepeatFlag,
+	}
+	for _, item := range t.Items {
+		item.ID = ""
+		req.Items = append(req.Items, item)
+	}
+	return req
+}
+
+func matchesProject(p Project, selectors []string) bool {
+	if len(selectors) == 0 {
+		return true
+	}
+	for _, s := range selectors {
+		if s == p.ID || strings.EqualFold(s, p.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnyTag(tags, wanted []string) bool {
+	if len(wanted) == 0 {
+		return true
+	}
+	for _, w := range wanted {
+		for _, t := range tags {
+			if strings.EqualFold(t, w) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,282 @@
+package ticktick_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+)
+
+func sampleBackup() *ticktick.Backup {
+	return &ticktick.Backup{
+		Version:   ticktick.BackupVersion,
+		CreatedAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+		InboxID:   "inbox-old",
+		Projects: []ticktick.ProjectData{
+			{
+				Project: ticktick.Project{ID: "inbox-old", Name: "Inbox"},
+				Tasks: []ticktick.Task{
+					{ID: "task-1", ProjectID: "inbox-old", Title: "Kept", Tags: []string{"home"}},
+				},
+			},
+			{
+				Project: ticktick.Project{ID: "proj-work", Name: "Work", Color: "#00ff00"},
+				Tasks: []ticktick.Task{
+					{ID: "task-2", ProjectID: "proj-work", Title: "Deleted", Tags: []string{"urgent"}, Priority: ticktick.PriorityHigh},
+					{ID: "task-3", ProjectID: "proj-work", Title: "Also deleted"},
+				},
+			},
+		},
+	}
+}
+
+func TestWriteAndReadBackup_RoundTrip(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		name := "plain"
+		if compress {
+			name = "gzip"
+		}
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			want := sampleBackup()
+			var buf bytes.Buffer
+
+			// Act
+			if err := ticktick.WriteBackup(&buf, want, compress); err != nil {
+				t.Fatalf("WriteBackup() returned unexpected error: %v", err)
+			}
+			got, err := ticktick.ReadBackup(&buf)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("ReadBackup() returned unexpected error: %v", err)
+			}
+			if got.InboxID != want.InboxID || !got.CreatedAt.Equal(want.CreatedAt) {
+				t.Errorf("ReadBackup() = %+v, want InboxID=%s, CreatedAt=%v", got, want.InboxID, want.CreatedAt)
+			}
+			if len(got.Projects) != 2 || len(got.Projects[1].Tasks) != 2 {
+				t.Fatalf("ReadBackup() projects = %+v, want 2 projects with 2 tasks in Work", got.Projects)
+			}
+		})
+	}
+}
+
+func TestReadBackup_UnsupportedVersion(t *testing.T) {
+	// Act
+	_, err := ticktick.ReadBackup(strings.NewReader(`{"version": 99, "projects": []}`))
+
+	// Assert
+	if err == nil {
+		t.Fatal("ReadBackup() expected error for unsupported version, got nil")
+	}
+	if !strings.Contains(err.Error(), "unsupported backup version 99") {
+		t.Errorf("error = %q, want to contain 'unsupported backup version 99'", err.Error())
+	}
+}
+
+func TestReadBackup_InvalidJSON(t *testing.T) {
+	// Act
+	_, err := ticktick.ReadBackup(strings.NewReader("not json"))
+
+	// Assert
+	if err == nil {
+		t.Fatal("ReadBackup() expected error for invalid JSON, got nil")
+	}
+}
+
+func TestBackupFileName(t *testing.T) {
+	ts := time.Date(2026, 10, 18, 9, 5, 3, 0, time.UTC)
+	if got := ticktick.BackupFileName(ts, false); got != "ticky-backup-20261018T090503Z.json" {
+		t.Errorf("BackupFileName(false) = %q", got)
+	}
+	if got := ticktick.BackupFileName(ts, true); got != "ticky-backup-20261018T090503Z.json.gz" {
+		t.Errorf("BackupFileName(true) = %q", got)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	// Arrange
+	t.Setenv("HOME", t.TempDir())
+	if err := ticktick.SaveInboxID("inbox-1"); err != nil {
+		t.Fatalf("SaveInboxID() returned unexpected error: %v", err)
+	}
+	client, cleanup := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/project":
+			json.NewEncoder(w).Encode([]ticktick.Project{{ID: "proj-1", Name: "Work"}})
+		case "/project/inbox-1/data":
+			json.NewEncoder(w).Encode(ticktick.ProjectData{Tasks: []ticktick.Task{{ID: "t1"}}})
+		case "/project/proj-1/data":
+			json.NewEncoder(w).Encode(ticktick.ProjectData{Project: ticktick.Project{ID: "proj-1"}, Tasks: []ticktick.Task{{ID: "t2"}}})
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer cleanup()
+
+	// Act
+	got, err := client.Snapshot()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Snapshot() returned unexpected error: %v", err)
+	}
+	if got.Version != ticktick.BackupVersion || got.InboxID != "inbox-1" {
+		t.Errorf("Snapshot() = %+v, want version %d and InboxID inbox-1", got, ticktick.BackupVersion)
+	}
+	if len(got.Projects) != 2 {
+		t.Fatalf("Snapshot() returned %d projects, want 2", len(got.Projects))
+	}
+	if got.Projects[0].Project.Name != "Inbox" || got.Projects[0].Project.ID != "inbox-1" {
+		t.Errorf("Projects[0].Project = %+v, want Inbox/inbox-1", got.Projects[0].Project)
+	}
+	if got.Projects[1].Project.Name != "Work" {
+		t.Errorf("Projects[1].Project.Name = %q, want Work", got.Projects[1].Project.Name)
+	}
+}
+
+func TestRestore_RecreatesMissing(t *testing.T) {
+	// Arrange — live account has the Inbox task but lost the Work project
+	t.Setenv("HOME", t.TempDir())
+	live := &ticktick.Backup{
+		InboxID: "inbox-new",
+		Projects: []ticktick.ProjectData{
+			{Project: ticktick.Project{ID: "inbox-new", Name: "Inbox"}, Tasks: []ticktick.Task{{ID: "task-1"}}},
+		},
+	}
+	var createdTasks []ticktick.TaskCreateRequest
+	client, cleanup := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/project":
+			var req ticktick.ProjectCreateRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if req.Name != "Work" || req.Color != "#00ff00" {
+				t.Errorf("CreateProject request = %+v, want Work/#00ff00", req)
+			}
+			json.NewEncoder(w).Encode(ticktick.Project{ID: "proj-new", Name: req.Name})
+		case r.Method == http.MethodPost && r.URL.Path == "/task":
+			var req ticktick.TaskCreateRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			createdTasks = append(createdTasks, req)
+			json.NewEncoder(w).Encode(ticktick.Task{ID: "new-" + req.Title, ProjectID: req.ProjectID, Title: req.Title})
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer cleanup()
+
+	// Act
+	got, err := client.Restore(sampleBackup(), live, ticktick.RestoreOptions{})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Restore() returned unexpected error: %v", err)
+	}
+	want := []ticktick.RestoreAction{
+		{Kind: "project", OldID: "inbox-old", NewID: "inbox-new", Name: "Inbox", Status: "exists"},
+		{Kind: "task", OldID: "task-1", NewID: "task-1", Name: "Kept", ProjectID: "inbox-new", Status: "exists"},
+		{Kind: "project", OldID: "proj-work", NewID: "proj-new", Name: "Work", Status: "created"},
+		{Kind: "task", OldID: "task-2", NewID: "new-Deleted", Name: "Deleted", ProjectID: "proj-new", Status: "created"},
+		{Kind: "task", OldID: "task-3", NewID: "new-Also deleted", Name: "Also deleted", ProjectID: "proj-new", Status: "created"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Restore() returned %d actions, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("action[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if len(createdTasks) != 2 || createdTasks[0].Priority != ticktick.PriorityHigh || createdTasks[0].ProjectID != "proj-new" {
+		t.Errorf("created tasks = %+v, want 2 tasks in proj-new with the first at high priority", createdTasks)
+	}
+}
+
+func TestRestore_DryRunWithFilters(t *testing.T) {
+	// Arrange
+	t.Setenv("HOME", t.TempDir())
+	live := &ticktick.Backup{InboxID: "inbox-new"}
+	client, cleanup := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("dry run must not call the API: %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	defer cleanup()
+
+	// Act
+	got, err := client.Restore(sampleBackup(), live, ticktick.RestoreOptions{
+		Projects: []string{"work"},
+		Tags:     []string{"URGENT"},
+		DryRun:   true,
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Restore() returned unexpected error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("Restore() returned %d actions, want 2: %+v", len(got), got)
+	}
+	if got[0].Kind != "project" || got[0].Status != "would-create" {
+		t.Errorf("action[0] = %+v, want project would-create", got[0])
+	}
+	if got[1].OldID != "task-2" || got[1].Status != "would-create" {
+		t.Errorf("action[1] = %+v, want task-2 would-create", got[1])
+	}
+}
+
+func TestRestore_TwiceDoesNotDuplicate(t *testing.T) {
+	// Arrange — the Work project still exists, its tasks were deleted
+	t.Setenv("HOME", t.TempDir())
+	live := &ticktick.Backup{
+		InboxID: "inbox-new",
+		Projects: []ticktick.ProjectData{
+			{Project: ticktick.Project{ID: "inbox-new", Name: "Inbox"}, Tasks: []ticktick.Task{{ID: "task-1"}}},
+			{Project: ticktick.Project{ID: "proj-work", Name: "Work"}},
+		},
+	}
+	var creates int
+	client, cleanup := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var req ticktick.TaskCreateRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		creates++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ticktick.Task{ID: "new-" + req.Title, ProjectID: req.ProjectID, Title: req.Title})
+	})
+	defer cleanup()
+
+	// Act — the second restore sees the live account after the first
+	first, err := client.Restore(sampleBackup(), live, ticktick.RestoreOptions{})
+	if err != nil {
+		t.Fatalf("first Restore() returned unexpected error: %v", err)
+	}
+	for _, a := range first {
+		if a.Kind == "task" && a.Status == "created" {
+			live.Projects[1].Tasks = append(live.Projects[1].Tasks, ticktick.Task{ID: a.NewID})
+		}
+	}
+	second, err := client.Restore(sampleBackup(), live, ticktick.RestoreOptions{})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("second Restore() returned unexpected error: %v", err)
+	}
+	if creates != 2 {
+		t.Errorf("tasks created = %d, want 2", creates)
+	}
+	for _, a := range second {
+		if a.Status != "exists" {
+			t.Errorf("second restore action = %+v, want exists", a)
+		}
+	}
+	if second[3].NewID != "new-Deleted" {
+		t.Errorf("second restore mapped task-2 to %q, want new-Deleted", second[3].NewID)
+	}
+}
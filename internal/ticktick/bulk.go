@@ -0,0 +1,70 @@
+package ticktick
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// TaskRef identifies a task by its ID and the ID of the project it belongs to.
+type TaskRef struct {
+	ID        string `json:"task_id"`
+	ProjectID string `json:"project_id"`
+}
+
+// BulkResult is the outcome of one task in a bulk operation.
+type BulkResult struct {
+	TaskRef
+	Task *Task
+	Err  error
+}
+
+// ParseTaskRefs reads one task per line in the "id<TAB>projectId" layout
+// produced by the --plain output of "tasks list" and "tasks create". Extra
+// columns are ignored, and the project ID may be omitted.
+func ParseTaskRefs(r io.Reader) ([]TaskRef, error) {
+	var refs []TaskRef
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		cols := strings.Split(line, "\t")
+		ref := TaskRef{ID: strings.TrimSpace(cols[0])}
+		if len(cols) > 1 {
+			ref.ProjectID = strings.TrimSpace(cols[1])
+		}
+		refs = append(refs, ref)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read task IDs: %w", err)
+	}
+	return refs, nil
+}
+
+// RunBulk calls fn for every ref using at most concurrency goroutines.
+// Results are returned in the same order as refs.
+func RunBulk(refs []TaskRef, concurrency int, fn func(TaskRef) (*Task, error)) []BulkResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]BulkResult, len(refs))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, ref := range refs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			task, err := fn(ref)
+			results[i] = BulkResult{TaskRef: ref, Task: task, Err: err}
+		}()
+	}
+	wg.Wait()
+	return results
+}
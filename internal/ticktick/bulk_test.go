@@ -0,0 +1,85 @@
+package ticktick
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseTaskRefs(t *testing.T) {
+	// Arrange — mix of "tasks list --plain" lines, bare IDs and blank lines
+	input := "task-1\tproj-1\tReview PR\thigh\t\twork\n\ntask-2\tproj-2\ntask-3\n"
+
+	// Act
+	got, err := ParseTaskRefs(strings.NewReader(input))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("ParseTaskRefs() returned unexpected error: %v", err)
+	}
+	want := []TaskRef{
+		{ID: "task-1", ProjectID: "proj-1"},
+		{ID: "task-2", ProjectID: "proj-2"},
+		{ID: "task-3"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseTaskRefs() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRunBulk_PreservesOrderAndErrors(t *testing.T) {
+	// Arrange
+	refs := []TaskRef{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}}
+
+	// Act
+	got := RunBulk(refs, 2, func(ref TaskRef) (*Task, error) {
+		if ref.ID == "c" {
+			return nil, fmt.Errorf("boom")
+		}
+		return &Task{ID: ref.ID}, nil
+	})
+
+	// Assert
+	if len(got) != len(refs) {
+		t.Fatalf("RunBulk() returned %d results, want %d", len(got), len(refs))
+	}
+	for i, r := range got {
+		if r.ID != refs[i].ID {
+			t.Errorf("result[%d].ID = %q, want %q", i, r.ID, refs[i].ID)
+		}
+	}
+	if got[2].Err == nil {
+		t.Error("result[2].Err = nil, want error")
+	}
+	if got[0].Err != nil || got[0].Task == nil || got[0].Task.ID != "a" {
+		t.Errorf("result[0] = %+v, want task a without error", got[0])
+	}
+}
+
+func TestRunBulk_BoundsConcurrency(t *testing.T) {
+	// Arrange
+	refs := make([]TaskRef, 20)
+	var running, peak int32
+
+	// Act
+	RunBulk(refs, 3, func(TaskRef) (*Task, error) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return nil, nil
+	})
+
+	// Assert
+	if peak > 3 {
+		t.Errorf("peak concurrency = %d, want <= 3", peak)
+	}
+}
@@ -3,10 +3,12 @@ package ticktick
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"sync"
 	"time"
 )
 
@@ -14,8 +16,34 @@ var baseURL = "https://api.ticktick.com/open/v1"
 
 // Client is the TickTick API client.
 type Client struct {
-	httpClient  *http.Client
+	httpClient *http.Client
+	index      *taskIndex
+	hooks      hooks
+	hookOutput io.Writer
+
+	// mu guards the access token, which a long-running client refreshes
+	// when it expires or is rejected. Only a token read from the token
+	// file or credential helper is refreshed.
+	mu          sync.Mutex
 	accessToken string
+	expiresAt   int64
+	refreshable bool
+}
+
+// APIError is returned when the API answers with an error status.
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Body)
+}
+
+// IsNotFound reports whether err is an API error with status 404.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
 }
 
 // NewClient creates a new TickTick client.
@@ -25,6 +53,8 @@ func NewClient() (*Client, error) {
 		return &Client{
 			httpClient:  &http.Client{Timeout: 30 * time.Second},
 			accessToken: token,
+			index:       newTaskIndex(),
+			hooks:       loadHooks(),
 		}, nil
 	}
 
@@ -33,27 +63,63 @@ func NewClient() (*Client, error) {
 		return nil, fmt.Errorf("not authenticated: run 'ticky auth login' first (%w)", err)
 	}
 
-	// Refresh if expired
-	if savedToken.ExpiresAt > 0 && time.Now().Unix() > savedToken.ExpiresAt {
-		if savedToken.RefreshToken == "" {
-			return nil, fmt.Errorf("token expired and no refresh token available: run 'ticky auth login'")
-		}
-		newToken, err := RefreshAccessToken(savedToken.RefreshToken)
-		if err != nil {
-			return nil, fmt.Errorf("failed to refresh token: %w (run 'ticky auth login')", err)
-		}
-		if err := SaveToken(newToken); err != nil {
-			return nil, fmt.Errorf("failed to save refreshed token: %w", err)
+	if tokenExpired(savedToken) {
+		if savedToken, err = refreshToken(""); err != nil {
+			return nil, err
 		}
-		savedToken = newToken
 	}
 
 	return &Client{
 		httpClient:  &http.Client{Timeout: 30 * time.Second},
+		index:       newTaskIndex(),
+		hooks:       loadHooks(),
 		accessToken: savedToken.AccessToken,
+		expiresAt:   savedToken.ExpiresAt,
+		refreshable: true,
 	}, nil
 }
 
+// token returns the access token to send, refreshing it first if it has
+// expired.
+func (c *Client) token() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.refreshable && c.expiresAt > 0 && time.Now().Unix() > c.expiresAt {
+		if err := c.refreshLocked(""); err != nil {
+			return "", err
+		}
+	}
+	return c.accessToken, nil
+}
+
+// refreshRejected refreshes the access token after the API rejected
+// rejected. It reports whether there is a new token to retry with.
+func (c *Client) refreshRejected(rejected string) (bool, error) {
+	if !c.refreshable {
+		return false, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.accessToken != rejected {
+		// Another request refreshed it meanwhile
+		return true, nil
+	}
+	if err := c.refreshLocked(rejected); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *Client) refreshLocked(rejected string) error {
+	token, err := refreshToken(rejected)
+	if err != nil {
+		return err
+	}
+	c.accessToken = token.AccessToken
+	c.expiresAt = token.ExpiresAt
+	return nil
+}
+
 // Get performs a GET request.
 func (c *Client) Get(path string) ([]byte, error) {
 	return c.do("GET", path, nil)
@@ -68,7 +134,7 @@ func (c *Client) Post(path string, body any) ([]byte, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
-	return c.do("POST", path, bytes.NewBuffer(data))
+	return c.do("POST", path, data)
 }
 
 // Delete performs a DELETE request.
@@ -76,33 +142,64 @@ func (c *Client) Delete(path string) ([]byte, error) {
 	return c.do("DELETE", path, nil)
 }
 
-func (c *Client) do(method, path string, body io.Reader) ([]byte, error) {
-	url := baseURL + path
-	req, err := http.NewRequest(method, url, body)
+// do sends a request. If the API rejects the access token, the token is
+// refreshed and the request is sent once more.
+func (c *Client) do(method, path string, body []byte) ([]byte, error) {
+	token, err := c.token()
+	if err != nil {
+		return nil, err
+	}
+	status, respBody, err := c.send(method, path, body, token)
+	if err == nil && status == http.StatusUnauthorized {
+		retry, refreshErr := c.refreshRejected(token)
+		if refreshErr != nil {
+			return nil, refreshErr
+		}
+		if retry {
+			if token, err = c.token(); err != nil {
+				return nil, err
+			}
+			status, respBody, err = c.send(method, path, body, token)
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+
+	if status < 200 || status >= 300 {
+		return nil, &APIError{StatusCode: status, Body: string(respBody)}
+	}
+
+	return respBody, nil
+}
+
+// send sends one request with the given access token.
+func (c *Client) send(method, path string, body []byte, token string) (int, []byte, error) {
+	url := baseURL + path
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return 0, nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return 0, nil, fmt.Errorf("failed to read response: %w", err)
 	}
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(respBody))
-	}
-
-	return respBody, nil
+	return resp.StatusCode, respBody, nil
 }
 
 // GetProjects returns all projects.
@@ -131,6 +228,20 @@ func (c *Client) GetProject(id string) (*Project, error) {
 	return &project, nil
 }
 
+// CreateProject creates a new project.
+func (c *Client) CreateProject(req *ProjectCreateRequest) (*Project, error) {
+	data, err := c.Post("/project", req)
+	if err != nil {
+		return nil, err
+	}
+	invalidateProjectCache()
+	var project Project
+	if err := json.Unmarshal(data, &project); err != nil {
+		return nil, fmt.Errorf("failed to parse project: %w", err)
+	}
+	return &project, nil
+}
+
 // GetProjectData returns a project with its tasks.
 func (c *Client) GetProjectData(projectID string) (*ProjectData, error) {
 	data, err := c.Get("/project/" + projectID + "/data")
@@ -141,11 +252,29 @@ func (c *Client) GetProjectData(projectID string) (*ProjectData, error) {
 	if err := json.Unmarshal(data, &pd); err != nil {
 		return nil, fmt.Errorf("failed to parse project data: %w", err)
 	}
+	c.index.sync(projectID, pd.Tasks)
 	return &pd, nil
 }
 
-// CreateTask creates a new task.
+// CreateTask creates a new task, running the pre-create and post-create
+// hooks. A pre-create hook may change req.
 func (c *Client) CreateTask(req *TaskCreateRequest) (*Task, error) {
+	var env []string
+	if _, ok := c.hooks[HookPreCreate]; ok {
+		env = append(env, "TICKY_PROJECT_NAME="+c.hookProjectName(req.ProjectID))
+	}
+	if err := runPreHook(c.hookStderr(), c.hooks, HookPreCreate, req, env...); err != nil {
+		return nil, err
+	}
+	task, err := c.createTask(req)
+	if err != nil {
+		return nil, err
+	}
+	c.hooks.runPostHook(c.hookStderr(), HookPostCreate, task)
+	return task, nil
+}
+
+func (c *Client) createTask(req *TaskCreateRequest) (*Task, error) {
 	data, err := c.Post("/task", req)
 	if err != nil {
 		return nil, err
@@ -154,6 +283,7 @@ func (c *Client) CreateTask(req *TaskCreateRequest) (*Task, error) {
 	if err := json.Unmarshal(data, &task); err != nil {
 		return nil, fmt.Errorf("failed to parse task: %w", err)
 	}
+	c.index.record(task)
 	return &task, nil
 }
 
@@ -167,11 +297,16 @@ func (c *Client) GetTask(projectID, taskID string) (*Task, error) {
 	if err := json.Unmarshal(data, &task); err != nil {
 		return nil, fmt.Errorf("failed to parse task: %w", err)
 	}
+	c.index.record(task)
 	return &task, nil
 }
 
-// UpdateTask updates an existing task.
+// UpdateTask updates an existing task, running the pre-update hook first.
+// A pre-update hook may change req.
 func (c *Client) UpdateTask(req *TaskUpdateRequest) (*Task, error) {
+	if err := runPreHook(c.hookStderr(), c.hooks, HookPreUpdate, req); err != nil {
+		return nil, err
+	}
 	data, err := c.Post("/task/"+req.ID, req)
 	if err != nil {
 		return nil, err
@@ -180,18 +315,38 @@ func (c *Client) UpdateTask(req *TaskUpdateRequest) (*Task, error) {
 	if err := json.Unmarshal(data, &task); err != nil {
 		return nil, fmt.Errorf("failed to parse task: %w", err)
 	}
+	c.index.record(task)
 	return &task, nil
 }
 
-// CompleteTask marks a task as complete.
+// CompleteTask marks a task as complete and runs the post-complete hook.
 func (c *Client) CompleteTask(projectID, taskID string) error {
-	_, err := c.Post("/project/"+projectID+"/task/"+taskID+"/complete", nil)
-	return err
+	hookInput := c.hookTask(HookPostComplete, projectID, taskID)
+	if _, err := c.Post("/project/"+projectID+"/task/"+taskID+"/complete", nil); err != nil {
+		return err
+	}
+	if task, ok := hookInput.(*Task); ok {
+		task.Status = TaskStatusCompleted
+	}
+	c.hooks.runPostHook(c.hookStderr(), HookPostComplete, hookInput)
+	return nil
 }
 
-// DeleteTask deletes a task.
+// DeleteTask deletes a task and runs the post-delete hook.
 func (c *Client) DeleteTask(projectID, taskID string) error {
+	hookInput := c.hookTask(HookPostDelete, projectID, taskID)
+	if err := c.deleteTask(projectID, taskID); err != nil {
+		return err
+	}
+	c.hooks.runPostHook(c.hookStderr(), HookPostDelete, hookInput)
+	return nil
+}
+
+func (c *Client) deleteTask(projectID, taskID string) error {
 	_, err := c.Delete("/project/" + projectID + "/task/" + taskID)
+	if err == nil {
+		c.index.forget(taskID)
+	}
 	return err
 }
 
@@ -202,12 +357,13 @@ func (c *Client) DiscoverInboxID() (string, error) {
 		return id, nil
 	}
 
-	task, err := c.CreateTask(&TaskCreateRequest{Title: ".ticky-inbox-probe"})
+	// The probe is internal, so no hooks run for it
+	task, err := c.createTask(&TaskCreateRequest{Title: ".ticky-inbox-probe"})
 	if err != nil {
 		return "", fmt.Errorf("failed to discover inbox ID: %w", err)
 	}
 	inboxID := task.ProjectID
-	_ = c.DeleteTask(inboxID, task.ID)
+	_ = c.deleteTask(inboxID, task.ID)
 
 	// Cache for future use
 	_ = SaveInboxID(inboxID)
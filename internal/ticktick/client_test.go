@@ -154,6 +154,39 @@ func TestGetProjectData_Success(t *testing.T) {
 	}
 }
 
+func TestCreateProject_Success(t *testing.T) {
+	// Arrange
+	client, cleanup := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s, want POST", r.Method)
+		}
+		if r.URL.Path != "/project" {
+			t.Errorf("path = %s, want /project", r.URL.Path)
+		}
+		var req ticktick.ProjectCreateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request body: %v", err)
+		}
+		if req.Name != "Releases" || req.Color != "#ff0000" {
+			t.Errorf("request = %+v, want Name=Releases, Color=#ff0000", req)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ticktick.Project{ID: "proj-new", Name: req.Name, Color: req.Color})
+	})
+	defer cleanup()
+
+	// Act
+	got, err := client.CreateProject(&ticktick.ProjectCreateRequest{Name: "Releases", Color: "#ff0000"})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("CreateProject() returned unexpected error: %v", err)
+	}
+	if got.ID != "proj-new" || got.Name != "Releases" {
+		t.Errorf("CreateProject() = %+v, want ID=proj-new, Name=Releases", got)
+	}
+}
+
 // --- Task Operations ---
 
 func TestCreateTask_Success(t *testing.T) {
@@ -241,6 +274,25 @@ func TestGetTask_Success(t *testing.T) {
 	}
 }
 
+func TestGetTask_NotFound(t *testing.T) {
+	// Arrange — a task deleted since its ID was recorded
+	client, cleanup := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	})
+	defer cleanup()
+
+	// Act
+	_, err := client.GetTask("proj-1", "task-1")
+
+	// Assert
+	if !ticktick.IsNotFound(err) {
+		t.Errorf("IsNotFound(%v) = false, want true", err)
+	}
+	if !strings.Contains(err.Error(), "API error (status 404)") {
+		t.Errorf("error = %q, want the API error message", err)
+	}
+}
+
 func TestUpdateTask_Success(t *testing.T) {
 	// Arrange
 	newTitle := "Updated Title"
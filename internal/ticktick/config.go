@@ -0,0 +1,449 @@
+package ticktick
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const configFile = "config.json"
+
+// Config holds the settings stored in the config file. Empty fields mean
+// "not set"; see ConfigKeys for the defaults.
+type Config struct {
+	InboxID          string   `json:"inbox_id,omitempty"`
+	ClientID         string   `json:"client_id,omitempty"`
+	ClientSecret     string   `json:"client_secret,omitempty"`
+	DefaultProject   string   `json:"default_project,omitempty"`
+	Output           string   `json:"output,omitempty"`
+	Timezone         string   `json:"timezone,omitempty"`
+	DateStyle        string   `json:"date_style,omitempty"`
+	DefaultPriority  string   `json:"default_priority,omitempty"`
+	DefaultTags      []string `json:"default_tags,omitempty"`
+	ProjectCacheTTL  string   `json:"project_cache_ttl,omitempty"`
+	EncryptToken     bool     `json:"encrypt_token,omitempty"`
+	CredentialHelper string   `json:"credential_helper,omitempty"`
+	RemindCommand    string   `json:"remind_command,omitempty"`
+	HookPreCreate    string   `json:"hook_pre_create,omitempty"`
+	HookPostCreate   string   `json:"hook_post_create,omitempty"`
+	HookPreUpdate    string   `json:"hook_pre_update,omitempty"`
+	HookPostComplete string   `json:"hook_post_complete,omitempty"`
+	HookPostDelete   string   `json:"hook_post_delete,omitempty"`
+	RedirectHost     string   `json:"redirect_host,omitempty"`
+	RedirectPort     string   `json:"redirect_port,omitempty"`
+	RedirectPath     string   `json:"redirect_path,omitempty"`
+}
+
+// ConfigKey describes one setting of the config file.
+type ConfigKey struct {
+	Name        string
+	Env         string // environment variable overriding the file, if any
+	Default     string
+	Description string
+	Secret      bool // hidden in listings
+
+	validate func(string) error
+	get      func(*Config) string
+	set      func(*Config, string)
+}
+
+var configKeys = []ConfigKey{
+	{
+		Name:        "default_project",
+		Env:         "TICKY_DEFAULT_PROJECT",
+		Default:     "inbox",
+		Description: "Project used when --project is omitted (name or ID)",
+		get:         func(c *Config) string { return c.DefaultProject },
+		set:         func(c *Config, v string) { c.DefaultProject = v },
+	},
+	{
+		Name:        "output",
+		Env:         "TICKY_OUTPUT",
+		Default:     "text",
+		Description: "Default output format: text, json or plain",
+		validate:    oneOf("text", "json", "plain"),
+		get:         func(c *Config) string { return c.Output },
+		set:         func(c *Config, v string) { c.Output = v },
+	},
+	{
+		Name:        "timezone",
+		Env:         "TICKY_TIMEZONE",
+		Description: "IANA time zone for due dates, e.g. Asia/Tokyo (default: system)",
+		validate: func(v string) error {
+			if _, err := time.LoadLocation(v); err != nil {
+				return fmt.Errorf("unknown time zone: %s", v)
+			}
+			return nil
+		},
+		get: func(c *Config) string { return c.Timezone },
+		set: func(c *Config, v string) { c.Timezone = v },
+	},
+	{
+		Name:        "date_style",
+		Env:         "TICKY_DATE_STYLE",
+		Default:     "iso",
+		Description: "How due dates are shown: iso, us, eu or relative",
+		validate:    oneOf("iso", "us", "eu", "relative"),
+		get:         func(c *Config) string { return c.DateStyle },
+		set:         func(c *Config, v string) { c.DateStyle = v },
+	},
+	{
+		Name:        "default_priority",
+		Env:         "TICKY_DEFAULT_PRIORITY",
+		Default:     "none",
+		Description: "Priority of new tasks: none, low, medium or high",
+		validate: func(v string) error {
+			_, err := ParsePriority(v)
+			return err
+		},
+		get: func(c *Config) string { return c.DefaultPriority },
+		set: func(c *Config, v string) { c.DefaultPriority = v },
+	},
+	{
+		Name:        "default_tags",
+		Env:         "TICKY_DEFAULT_TAGS",
+		Description: "Tags of new tasks (comma-separated)",
+		get:         func(c *Config) string { return strings.Join(c.DefaultTags, ",") },
+		set:         func(c *Config, v string) { c.DefaultTags = splitList(v) },
+	},
+	{
+		Name:        "project_cache_ttl",
+		Env:         "TICKY_PROJECT_CACHE_TTL",
+		Default:     ProjectCacheTTL.String(),
+		Description: "How long the project list is cached for name lookups, e.g. 15m",
+		validate: func(v string) error {
+			if d, err := time.ParseDuration(v); err != nil || d < 0 {
+				return fmt.Errorf("invalid duration: %s", v)
+			}
+			return nil
+		},
+		get: func(c *Config) string { return c.ProjectCacheTTL },
+		set: func(c *Config, v string) { c.ProjectCacheTTL = v },
+	},
+	{
+		Name:        "encrypt_token",
+		Env:         "TICKY_ENCRYPT_TOKEN",
+		Default:     "false",
+		Description: "Encrypt the token file with TICKY_KEY_FILE or a passphrase",
+		validate:    oneOf("true", "false"),
+		get: func(c *Config) string {
+			if c.EncryptToken {
+				return "true"
+			}
+			return ""
+		},
+		set: func(c *Config, v string) { c.EncryptToken = v == "true" },
+	},
+	{
+		Name:        "credential_helper",
+		Env:         "TICKY_CREDENTIAL_HELPER",
+		Description: "External command that stores the token instead of token.json",
+		get:         func(c *Config) string { return c.CredentialHelper },
+		set:         func(c *Config, v string) { c.CredentialHelper = v },
+	},
+	{
+		Name:        "remind_command",
+		Env:         "TICKY_REMIND_COMMAND",
+		Description: "Shell command run by \"remind daemon\" for each notification",
+		get:         func(c *Config) string { return c.RemindCommand },
+		set:         func(c *Config, v string) { c.RemindCommand = v },
+	},
+	{
+		Name:        "hook_pre_create",
+		Env:         "TICKY_HOOK_PRE_CREATE",
+		Description: "Command run before a task is created; may rewrite or reject it",
+		get:         func(c *Config) string { return c.HookPreCreate },
+		set:         func(c *Config, v string) { c.HookPreCreate = v },
+	},
+	{
+		Name:        "hook_post_create",
+		Env:         "TICKY_HOOK_POST_CREATE",
+		Description: "Command run after a task is created",
+		get:         func(c *Config) string { return c.HookPostCreate },
+		set:         func(c *Config, v string) { c.HookPostCreate = v },
+	},
+	{
+		Name:        "hook_pre_update",
+		Env:         "TICKY_HOOK_PRE_UPDATE",
+		Description: "Command run before a task is updated; may rewrite or reject it",
+		get:         func(c *Config) string { return c.HookPreUpdate },
+		set:         func(c *Config, v string) { c.HookPreUpdate = v },
+	},
+	{
+		Name:        "hook_post_complete",
+		Env:         "TICKY_HOOK_POST_COMPLETE",
+		Description: "Command run after a task is completed",
+		get:         func(c *Config) string { return c.HookPostComplete },
+		set:         func(c *Config, v string) { c.HookPostComplete = v },
+	},
+	{
+		Name:        "hook_post_delete",
+		Env:         "TICKY_HOOK_POST_DELETE",
+		Description: "Command run after a task is deleted",
+		get:         func(c *Config) string { return c.HookPostDelete },
+		set:         func(c *Config, v string) { c.HookPostDelete = v },
+	},
+	{
+		Name:        "client_id",
+		Env:         "TICKTICK_CLIENT_ID",
+		Description: "OAuth client ID of this profile",
+		get:         func(c *Config) string { return c.ClientID },
+		set:         func(c *Config, v string) { c.ClientID = v },
+	},
+	{
+		Name:        "client_secret",
+		Env:         "TICKTICK_CLIENT_SECRET",
+		Description: "OAuth client secret of this profile",
+		Secret:      true,
+		get:         func(c *Config) string { return c.ClientSecret },
+		set:         func(c *Config, v string) { c.ClientSecret = v },
+	},
+	{
+		Name:        "redirect_host",
+		Env:         "TICKY_REDIRECT_HOST",
+		Default:     "localhost",
+		Description: "Host of the OAuth redirect URI",
+		get:         func(c *Config) string { return c.RedirectHost },
+		set:         func(c *Config, v string) { c.RedirectHost = v },
+	},
+	{
+		Name:        "redirect_port",
+		Env:         "TICKY_REDIRECT_PORT",
+		Default:     "18080",
+		Description: "Port of the OAuth redirect URI and the login callback server",
+		validate:    validatePort,
+		get:         func(c *Config) string { return c.RedirectPort },
+		set:         func(c *Config, v string) { c.RedirectPort = v },
+	},
+	{
+		Name:        "redirect_path",
+		Env:         "TICKY_REDIRECT_PATH",
+		Default:     "/callback",
+		Description: "Path of the OAuth redirect URI",
+		validate:    validateRedirectPath,
+		get:         func(c *Config) string { return c.RedirectPath },
+		set:         func(c *Config, v string) { c.RedirectPath = v },
+	},
+	{
+		Name:        "inbox_id",
+		Description: "Cached Inbox project ID (discovered automatically)",
+		get:         func(c *Config) string { return c.InboxID },
+		set:         func(c *Config, v string) { c.InboxID = v },
+	},
+}
+
+// ConfigKeys returns the settings of the config file.
+func ConfigKeys() []ConfigKey {
+	return configKeys
+}
+
+func lookupConfigKey(name string) (ConfigKey, error) {
+	for _, k := range configKeys {
+		if k.Name == name {
+			return k, nil
+		}
+	}
+	names := make([]string, len(configKeys))
+	for i, k := range configKeys {
+		names[i] = k.Name
+	}
+	sort.Strings(names)
+	return ConfigKey{}, fmt.Errorf("unknown config key %q (valid keys: %s)", name, strings.Join(names, ", "))
+}
+
+func oneOf(values ...string) func(string) error {
+	return func(v string) error {
+		for _, allowed := range values {
+			if v == allowed {
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid value %q (use %s)", v, strings.Join(values, ", "))
+	}
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ConfigPath returns the full path to the config file.
+func ConfigPath() string {
+	return filepath.Join(configDir(), configFile)
+}
+
+// LoadConfig reads the config file. A missing file yields an empty Config.
+func LoadConfig() (*Config, error) {
+	cfg := &Config{}
+	data, err := os.ReadFile(ConfigPath())
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", ConfigPath(), err)
+	}
+	return cfg, nil
+}
+
+// LoadEffectiveConfig reads the config file and applies the environment
+// variables that override it. Every value is validated.
+func LoadEffectiveConfig() (*Config, error) {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range configKeys {
+		if k.Env != "" {
+			if v := os.Getenv(k.Env); v != "" {
+				k.set(cfg, v)
+			}
+		}
+		if err := k.check(k.get(cfg)); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+func (k ConfigKey) check(value string) error {
+	if value == "" || k.validate == nil {
+		return nil
+	}
+	if err := k.validate(value); err != nil {
+		source := "config key " + k.Name
+		if k.Env != "" && os.Getenv(k.Env) != "" {
+			source = k.Env
+		}
+		return fmt.Errorf("%s: %w", source, err)
+	}
+	return nil
+}
+
+// ConfigValue returns the effective value of a setting and where it came
+// from: "env", "config" or "default".
+func ConfigValue(name string) (value, source string, err error) {
+	k, err := lookupConfigKey(name)
+	if err != nil {
+		return "", "", err
+	}
+	if k.Env != "" {
+		if v := os.Getenv(k.Env); v != "" {
+			return v, "env", nil
+		}
+	}
+	cfg, err := LoadConfig()
+	if err != nil {
+		return "", "", err
+	}
+	if v := k.get(cfg); v != "" {
+		return v, "config", nil
+	}
+	return k.Default, "default", nil
+}
+
+// SetConfigValue validates and stores a setting. Other keys in the file,
+// including ones this version does not know, are kept.
+func SetConfigValue(name, value string) error {
+	k, err := lookupConfigKey(name)
+	if err != nil {
+		return err
+	}
+	if k.validate != nil {
+		if err := k.validate(value); err != nil {
+			return err
+		}
+	}
+	var cfg Config
+	k.set(&cfg, value)
+	return updateConfigFile(func(raw map[string]json.RawMessage) error {
+		fields, err := configFields(&cfg)
+		if err != nil {
+			return err
+		}
+		if v, ok := fields[name]; ok {
+			raw[name] = v
+		} else {
+			delete(raw, name)
+		}
+		return nil
+	})
+}
+
+// UnsetConfigValue removes a setting from the config file.
+func UnsetConfigValue(name string) error {
+	if _, err := lookupConfigKey(name); err != nil {
+		return err
+	}
+	return updateConfigFile(func(raw map[string]json.RawMessage) error {
+		delete(raw, name)
+		return nil
+	})
+}
+
+// configFields returns the JSON encoding of each set field of cfg.
+func configFields(cfg *Config) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// updateConfigFile rewrites the config file after fn has changed its keys.
+// Updates from concurrent processes are serialized by a lock, and the file
+// is replaced atomically. It is removed once no keys are left.
+func updateConfigFile(fn func(raw map[string]json.RawMessage) error) error {
+	unlock, err := lockFile(ConfigPath())
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	raw := make(map[string]json.RawMessage)
+	data, err := os.ReadFile(ConfigPath())
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return fmt.Errorf("failed to parse config file %s: %w", ConfigPath(), err)
+		}
+	}
+	if err := fn(raw); err != nil {
+		return err
+	}
+
+	if len(raw) == 0 {
+		if err := os.Remove(ConfigPath()); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove config file: %w", err)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(configDir(), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	data, err = json.MarshalIndent(raw, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	if err := writeFileAtomic(ConfigPath(), append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
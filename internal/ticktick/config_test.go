@@ -0,0 +1,157 @@
+package ticktick_test
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+)
+
+func TestSetConfigValue_KeepsOtherKeys(t *testing.T) {
+	// Arrange — a file with the cached inbox ID and a key from a newer version
+	t.Setenv("HOME", t.TempDir())
+	if err := ticktick.SaveInboxID("inbox1"); err != nil {
+		t.Fatalf("SaveInboxID() returned unexpected error: %v", err)
+	}
+	data, _ := os.ReadFile(ticktick.ConfigPath())
+	var raw map[string]any
+	json.Unmarshal(data, &raw)
+	raw["future_key"] = "kept"
+	data, _ = json.Marshal(raw)
+	os.WriteFile(ticktick.ConfigPath(), data, 0600)
+
+	// Act
+	if err := ticktick.SetConfigValue("default_tags", "work, urgent"); err != nil {
+		t.Fatalf("SetConfigValue() returned unexpected error: %v", err)
+	}
+	if err := ticktick.SaveInboxID("inbox2"); err != nil {
+		t.Fatalf("SaveInboxID() returned unexpected error: %v", err)
+	}
+
+	// Assert
+	cfg, err := ticktick.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() returned unexpected error: %v", err)
+	}
+	if cfg.InboxID != "inbox2" {
+		t.Errorf("InboxID = %q, want inbox2", cfg.InboxID)
+	}
+	if len(cfg.DefaultTags) != 2 || cfg.DefaultTags[0] != "work" || cfg.DefaultTags[1] != "urgent" {
+		t.Errorf("DefaultTags = %v, want [work urgent]", cfg.DefaultTags)
+	}
+	data, _ = os.ReadFile(ticktick.ConfigPath())
+	raw = nil
+	json.Unmarshal(data, &raw)
+	if raw["future_key"] != "kept" {
+		t.Errorf("future_key = %v, want kept", raw["future_key"])
+	}
+}
+
+func TestSetConfigValue_Validates(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	tests := []struct {
+		key     string
+		value   string
+		wantErr bool
+	}{
+		{"output", "json", false},
+		{"output", "yaml", true},
+		{"timezone", "Asia/Tokyo", false},
+		{"timezone", "Mars/Olympus", true},
+		{"date_style", "relative", false},
+		{"date_style", "long", true},
+		{"default_priority", "high", false},
+		{"default_priority", "urgent", true},
+		{"project_cache_ttl", "1h", false},
+		{"project_cache_ttl", "soon", true},
+		{"no_such_key", "x", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			if err := ticktick.SetConfigValue(tt.key, tt.value); (err != nil) != tt.wantErr {
+				t.Errorf("SetConfigValue(%q, %q) error = %v, wantErr %v", tt.key, tt.value, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigValue_Precedence(t *testing.T) {
+	// Arrange
+	t.Setenv("HOME", t.TempDir())
+	if err := ticktick.SetConfigValue("output", "plain"); err != nil {
+		t.Fatalf("SetConfigValue() returned unexpected error: %v", err)
+	}
+	t.Setenv("TICKY_OUTPUT", "json")
+
+	tests := []struct {
+		key        string
+		wantValue  string
+		wantSource string
+	}{
+		{"output", "json", "env"},
+		{"date_style", "iso", "default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			// Act
+			value, source, err := ticktick.ConfigValue(tt.key)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("ConfigValue() returned unexpected error: %v", err)
+			}
+			if value != tt.wantValue || source != tt.wantSource {
+				t.Errorf("ConfigValue(%q) = %q, %q, want %q, %q", tt.key, value, source, tt.wantValue, tt.wantSource)
+			}
+		})
+	}
+
+	t.Run("unset env falls back to config", func(t *testing.T) {
+		t.Setenv("TICKY_OUTPUT", "")
+		value, source, _ := ticktick.ConfigValue("output")
+		if value != "plain" || source != "config" {
+			t.Errorf("ConfigValue(output) = %q, %q, want plain, config", value, source)
+		}
+	})
+}
+
+func TestLoadEffectiveConfig_InvalidEnv(t *testing.T) {
+	// Arrange
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("TICKY_DATE_STYLE", "long")
+
+	// Act
+	_, err := ticktick.LoadEffectiveConfig()
+
+	// Assert
+	if err == nil {
+		t.Fatal("LoadEffectiveConfig() expected error for invalid TICKY_DATE_STYLE, got nil")
+	}
+}
+
+func TestDeleteToken_KeepsSettings(t *testing.T) {
+	// Arrange
+	t.Setenv("HOME", t.TempDir())
+	ticktick.SaveInboxID("inbox1")
+	if err := ticktick.SetConfigValue("default_project", "Work"); err != nil {
+		t.Fatalf("SetConfigValue() returned unexpected error: %v", err)
+	}
+
+	// Act
+	if err := ticktick.DeleteToken(); err != nil {
+		t.Fatalf("DeleteToken() returned unexpected error: %v", err)
+	}
+
+	// Assert
+	cfg, err := ticktick.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() returned unexpected error: %v", err)
+	}
+	if cfg.InboxID != "" || cfg.DefaultProject != "Work" {
+		t.Errorf("config = %+v, want inbox ID cleared and default_project kept", cfg)
+	}
+}
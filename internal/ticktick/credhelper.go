@@ -0,0 +1,140 @@
+package ticktick
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// ErrNoCredential is returned when the credential helper has no token.
+var ErrNoCredential = errors.New("credential helper has no token")
+
+// CredentialHelper returns the configured credential helper command, or "".
+func CredentialHelper() string {
+	helper, _, err := ConfigValue("credential_helper")
+	if err != nil {
+		return ""
+	}
+	return helper
+}
+
+// credentialHelperCommand builds the command for a helper action, following
+// git's conventions: "!cmd" is run by the shell, a bare name such as "pass"
+// runs ticky-credential-pass, and anything else is run as given.
+func credentialHelperCommand(helper, action string) *exec.Cmd {
+	if script, ok := strings.CutPrefix(helper, "!"); ok {
+		if runtime.GOOS == "windows" {
+			return exec.Command("cmd", "/C", script+" "+action)
+		}
+		return exec.Command("sh", "-c", script+` "$@"`, "ticky-credential", action)
+	}
+	fields := strings.Fields(helper)
+	name := fields[0]
+	if !strings.ContainsAny(name, `/\`) {
+		name = "ticky-credential-" + name
+	}
+	return exec.Command(name, append(fields[1:], action)...)
+}
+
+// runCredentialHelper sends attrs as "key=value" lines followed by a blank
+// line to the helper and parses its reply in the same format.
+func runCredentialHelper(helper, action string, attrs [][2]string) (map[string]string, error) {
+	var in bytes.Buffer
+	for _, kv := range attrs {
+		if strings.ContainsAny(kv[0]+kv[1], "\n\x00") {
+			return nil, fmt.Errorf("credential value for %s contains a newline", kv[0])
+		}
+		fmt.Fprintf(&in, "%s=%s\n", kv[0], kv[1])
+	}
+	in.WriteString("\n")
+
+	var out bytes.Buffer
+	cmd := credentialHelperCommand(helper, action)
+	cmd.Stdin = &in
+	cmd.Stdout = &out
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("credential helper %q %s failed: %w", helper, action, err)
+	}
+
+	reply := make(map[string]string)
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			break
+		}
+		if key, value, ok := strings.Cut(line, "="); ok {
+			reply[key] = value
+		}
+	}
+	return reply, scanner.Err()
+}
+
+// credentialAttrs identifies the token of the active profile to the helper.
+func credentialAttrs() [][2]string {
+	return [][2]string{
+		{"protocol", "https"},
+		{"host", "ticktick.com"},
+		{"profile", ActiveProfile()},
+	}
+}
+
+// helperGetToken fetches the token from the credential helper.
+func helperGetToken(helper string) (*OAuthToken, error) {
+	reply, err := runCredentialHelper(helper, "get", credentialAttrs())
+	if err != nil {
+		return nil, err
+	}
+	if reply["access_token"] == "" {
+		return nil, ErrNoCredential
+	}
+
+	token := &OAuthToken{
+		AccessToken:  reply["access_token"],
+		TokenType:    reply["token_type"],
+		Scope:        reply["scope"],
+		RefreshToken: reply["refresh_token"],
+	}
+	if v := reply["expires_in"]; v != "" {
+		if token.ExpiresIn, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("credential helper returned invalid expires_in: %s", v)
+		}
+	}
+	if v := reply["expires_at"]; v != "" {
+		if token.ExpiresAt, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return nil, fmt.Errorf("credential helper returned invalid expires_at: %s", v)
+		}
+	}
+	return token, nil
+}
+
+// helperStoreToken hands the token to the credential helper.
+func helperStoreToken(helper string, token *OAuthToken) error {
+	attrs := append(credentialAttrs(),
+		[2]string{"access_token", token.AccessToken},
+		[2]string{"token_type", token.TokenType},
+		[2]string{"expires_in", strconv.Itoa(token.ExpiresIn)},
+		[2]string{"scope", token.Scope},
+	)
+	if token.RefreshToken != "" {
+		attrs = append(attrs, [2]string{"refresh_token", token.RefreshToken})
+	}
+	if token.ExpiresAt != 0 {
+		attrs = append(attrs, [2]string{"expires_at", strconv.FormatInt(token.ExpiresAt, 10)})
+	}
+	_, err := runCredentialHelper(helper, "store", attrs)
+	return err
+}
+
+// helperEraseToken asks the credential helper to forget the token.
+func helperEraseToken(helper string) error {
+	_, err := runCredentialHelper(helper, "erase", credentialAttrs())
+	return err
+}
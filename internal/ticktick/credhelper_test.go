@@ -0,0 +1,130 @@
+package ticktick_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+)
+
+// credentialHelperScript is a helper that keeps the last stored request in
+// store.txt next to itself.
+const credentialHelperScript = `#!/bin/sh
+store="$(dirname "$0")/store.txt"
+case "$1" in
+get) [ -f "$store" ] && cat "$store" ;;
+store) cat > "$store" ;;
+erase) rm -f "$store" ;;
+esac
+exit 0
+`
+
+// setupCredentialHelper installs the helper script as ticky-credential-test
+// on PATH and returns its directory.
+func setupCredentialHelper(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("credential helper tests use a shell script")
+	}
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ticky-credential-test")
+	if err := os.WriteFile(path, []byte(credentialHelperScript), 0700); err != nil {
+		t.Fatalf("failed to write helper: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return dir
+}
+
+func TestCredentialHelper_RoundTrip(t *testing.T) {
+	dir := setupCredentialHelper(t)
+	script := filepath.Join(dir, "ticky-credential-test")
+
+	tests := []struct {
+		name   string
+		helper string
+	}{
+		{"bare name", "test"},
+		{"path", script},
+		{"shell", "!" + script},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			t.Setenv("TICKY_CREDENTIAL_HELPER", tt.helper)
+			want := &ticktick.OAuthToken{AccessToken: "helper-access", RefreshToken: "helper-refresh", ExpiresIn: 3600, ExpiresAt: 1900000000}
+
+			// Act
+			if err := ticktick.SaveToken(want); err != nil {
+				t.Fatalf("SaveToken() returned unexpected error: %v", err)
+			}
+			got, err := ticktick.LoadToken()
+
+			// Assert
+			if err != nil {
+				t.Fatalf("LoadToken() returned unexpected error: %v", err)
+			}
+			if *got != *want {
+				t.Errorf("LoadToken() = %+v, want %+v", got, want)
+			}
+			if _, err := os.Stat(ticktick.TokenPath()); !os.IsNotExist(err) {
+				t.Error("token.json was written although a credential helper is configured")
+			}
+		})
+	}
+}
+
+func TestCredentialHelper_SendsProfile(t *testing.T) {
+	// Arrange
+	dir := setupCredentialHelper(t)
+	t.Setenv("TICKY_CREDENTIAL_HELPER", "test")
+	useProfile(t, "work")
+
+	// Act
+	if err := ticktick.SaveToken(&ticktick.OAuthToken{AccessToken: "a"}); err != nil {
+		t.Fatalf("SaveToken() returned unexpected error: %v", err)
+	}
+
+	// Assert
+	data, _ := os.ReadFile(filepath.Join(dir, "store.txt"))
+	if !strings.Contains(string(data), "profile=work\n") || !strings.Contains(string(data), "host=ticktick.com\n") {
+		t.Errorf("helper input = %q, want profile and host attributes", data)
+	}
+}
+
+func TestCredentialHelper_EraseAndMissing(t *testing.T) {
+	// Arrange
+	setupCredentialHelper(t)
+	t.Setenv("TICKY_CREDENTIAL_HELPER", "test")
+	ticktick.SaveToken(&ticktick.OAuthToken{AccessToken: "a"})
+
+	// Act
+	if err := ticktick.DeleteToken(); err != nil {
+		t.Fatalf("DeleteToken() returned unexpected error: %v", err)
+	}
+	_, err := ticktick.LoadToken()
+
+	// Assert
+	if !errors.Is(err, ticktick.ErrNoCredential) {
+		t.Errorf("LoadToken() after erase error = %v, want ErrNoCredential", err)
+	}
+}
+
+func TestCredentialHelper_Failure(t *testing.T) {
+	// Arrange
+	setupCredentialHelper(t)
+	t.Setenv("TICKY_CREDENTIAL_HELPER", "!exit 3")
+
+	// Act
+	_, err := ticktick.LoadToken()
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "credential helper") {
+		t.Errorf("LoadToken() error = %v, want credential helper failure", err)
+	}
+}
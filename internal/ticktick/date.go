@@ -2,6 +2,7 @@ package ticktick
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 	"time"
@@ -47,5 +48,74 @@ func ParseDate(s string) (string, error) {
 
 func endOfDay(t time.Time) string {
 	eod := time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, time.Local)
-	return eod.UTC().Format("2006-01-02T15:04:05.000+0000")
+	return formatAPITime(eod)
+}
+
+// formatAPITime formats t in the UTC layout the TickTick API expects.
+func formatAPITime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000+0000")
+}
+
+// parseAPITime parses a timestamp as returned by the TickTick API.
+// Both RFC3339 and TickTick's "+0000" offset format are accepted.
+func parseAPITime(s string) (time.Time, error) {
+	// Try RFC3339 first
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	// Try TickTick's +0000 format
+	if t, err := time.Parse("2006-01-02T15:04:05.000+0000", s); err == nil {
+		return t, nil
+	}
+	// Try without milliseconds
+	if t, err := time.Parse("2006-01-02T15:04:05+0000", s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, &time.ParseError{Value: s, Message: "unsupported time format"}
+}
+
+// localDate returns the local calendar date (YYYY-MM-DD) of an API timestamp,
+// or "" if s is empty or cannot be parsed.
+func localDate(s string) string {
+	if s == "" {
+		return ""
+	}
+	t, err := parseAPITime(s)
+	if err != nil {
+		return ""
+	}
+	return t.Local().Format("2006-01-02")
+}
+
+// FormatDueDate formats an API due date for display. Styles are "iso"
+// (2026-02-12), "us" (02/12/2026), "eu" (12.02.2026) and "relative" (today,
+// tomorrow, in 3 days, 2 days ago); anything else falls back to iso.
+func FormatDueDate(due, style string, now time.Time) string {
+	t, err := parseAPITime(due)
+	if err != nil {
+		return due
+	}
+	t = t.Local()
+	switch style {
+	case "us":
+		return t.Format("01/02/2006")
+	case "eu":
+		return t.Format("02.01.2006")
+	case "relative":
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+		switch days := int(math.Round(day.Sub(today).Hours() / 24)); {
+		case days == 0:
+			return "today"
+		case days == 1:
+			return "tomorrow"
+		case days == -1:
+			return "yesterday"
+		case days > 1:
+			return fmt.Sprintf("in %d days", days)
+		default:
+			return fmt.Sprintf("%d days ago", -days)
+		}
+	}
+	return t.Format("2006-01-02")
 }
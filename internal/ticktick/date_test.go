@@ -228,3 +228,33 @@ func TestParseDate_ErrorMessages(t *testing.T) {
 		}
 	})
 }
+
+func TestFormatDueDate(t *testing.T) {
+	now := time.Date(2026, 2, 10, 9, 0, 0, 0, time.Local)
+	due := endOfDay(time.Date(2026, 2, 12, 0, 0, 0, 0, time.Local))
+
+	tests := []struct {
+		name  string
+		due   string
+		style string
+		want  string
+	}{
+		{"iso", due, "iso", "2026-02-12"},
+		{"us", due, "us", "02/12/2026"},
+		{"eu", due, "eu", "12.02.2026"},
+		{"unknown style falls back to iso", due, "", "2026-02-12"},
+		{"relative future", due, "relative", "in 2 days"},
+		{"relative today", endOfDay(now), "relative", "today"},
+		{"relative tomorrow", endOfDay(now.AddDate(0, 0, 1)), "relative", "tomorrow"},
+		{"relative past", endOfDay(now.AddDate(0, 0, -3)), "relative", "3 days ago"},
+		{"unparseable is returned as is", "soon", "iso", "soon"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatDueDate(tt.due, tt.style, now); got != tt.want {
+				t.Errorf("FormatDueDate(%q, %q) = %q, want %q", tt.due, tt.style, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package ticktick
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FieldChange describes a single changed task field.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// TaskChange identifies a task in a snapshot diff, with field-level changes
+// for modified tasks.
+type TaskChange struct {
+	ID        string        `json:"id"`
+	ProjectID string        `json:"projectId"`
+	Title     string        `json:"title"`
+	Changes   []FieldChange `json:"changes,omitempty"`
+}
+
+// SnapshotDiff is the difference between two snapshots, keyed by task ID.
+type SnapshotDiff struct {
+	Added     []TaskChange `json:"added"`
+	Removed   []TaskChange `json:"removed"`
+	Completed []TaskChange `json:"completed"`
+	Modified  []TaskChange `json:"modified"`
+}
+
+// Empty reports whether the snapshots are identical.
+func (d *SnapshotDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Completed) == 0 && len(d.Modified) == 0
+}
+
+// DiffSnapshots compares two snapshots by task ID. Tasks that disappear from b
+// are reported as removed; Client.ClassifyRemoved can tell completed tasks
+// apart when b reflects the live account.
+func DiffSnapshots(a, b *Backup) *SnapshotDiff {
+	d := &SnapshotDiff{}
+
+	before := indexTasks(a)
+	after := indexTasks(b)
+
+	for _, pd := range a.Projects {
+		for _, old := range pd.Tasks {
+			cur, ok := after[old.ID]
+			switch {
+			case !ok:
+				d.Removed = append(d.Removed, taskChange(old, nil))
+			case old.Status != TaskStatusCompleted && cur.Status == TaskStatusCompleted:
+				d.Completed = append(d.Completed, taskChange(cur, nil))
+			default:
+				if changes := DiffTask(old, cur); len(changes) > 0 {
+					d.Modified = append(d.Modified, taskChange(cur, changes))
+				}
+			}
+		}
+	}
+
+	for _, pd := range b.Projects {
+		for _, cur := range pd.Tasks {
+			if _, ok := before[cur.ID]; !ok {
+				d.Added = append(d.Added, taskChange(cur, nil))
+			}
+		}
+	}
+
+	return d
+}
+
+// ClassifyRemoved looks up each removed task and moves those that still exist
+// in a completed state to d.Completed. The Open API only lists open tasks, so
+// completed tasks otherwise look deleted in a live snapshot.
+func (c *Client) ClassifyRemoved(d *SnapshotDiff) {
+	var removed []TaskChange
+	for _, tc := range d.Removed {
+		task, err := c.GetTask(tc.ProjectID, tc.ID)
+		if err == nil && task.ID != "" && task.Status == TaskStatusCompleted {
+			d.Completed = append(d.Completed, tc)
+			continue
+		}
+		removed = append(removed, tc)
+	}
+	d.Removed = removed
+}
+
+// DiffTask returns the field-level changes between two versions of a task.
+func DiffTask(old, cur Task) []FieldChange {
+	var changes []FieldChange
+	add := func(field, o, n string) {
+		if o != n {
+			changes = append(changes, FieldChange{Field: field, Old: o, New: n})
+		}
+	}
+
+	add("title", old.Title, cur.Title)
+	add("project", old.ProjectID, cur.ProjectID)
+	add("due", old.DueDate, cur.DueDate)
+	add("priority", PriorityString(old.Priority), PriorityString(cur.Priority))
+	add("tags", tagsString(old.Tags), tagsString(cur.Tags))
+	add("content", old.Content, cur.Content)
+	add("status", taskStatusString(old.Status), taskStatusString(cur.Status))
+	return changes
+}
+
+// tagsString joins tags in sorted order, so the server reordering them is not
+// a change.
+func tagsString(tags []string) string {
+	sorted := append([]string(nil), tags...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+func indexTasks(b *Backup) map[string]Task {
+	index := make(map[string]Task)
+	for _, pd := range b.Projects {
+		for _, t := range pd.Tasks {
+			index[t.ID] = t
+		}
+	}
+	return index
+}
+
+func taskChange(t Task, changes []FieldChange) TaskChange {
+	return TaskChange{ID: t.ID, ProjectID: t.ProjectID, Title: t.Title, Changes: changes}
+}
+
+func taskStatusString(status int) string {
+	switch status {
+	case TaskStatusNormal:
+		return "open"
+	case TaskStatusCompleted:
+		return "completed"
+	default:
+		return strconv.Itoa(status)
+	}
+}
@@ -0,0 +1,150 @@
+package ticktick_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+)
+
+func snapshotOf(tasks ...ticktick.Task) *ticktick.Backup {
+	return &ticktick.Backup{
+		Version:  ticktick.BackupVersion,
+		Projects: []ticktick.ProjectData{{Project: ticktick.Project{ID: "proj-1"}, Tasks: tasks}},
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	// Arrange
+	a := snapshotOf(
+		ticktick.Task{ID: "same", ProjectID: "proj-1", Title: "Unchanged"},
+		ticktick.Task{ID: "gone", ProjectID: "proj-1", Title: "Removed"},
+		ticktick.Task{ID: "done", ProjectID: "proj-1", Title: "Will complete"},
+		ticktick.Task{ID: "edit", ProjectID: "proj-1", Title: "Old title", Priority: ticktick.PriorityLow, Tags: []string{"a"}},
+	)
+	b := snapshotOf(
+		ticktick.Task{ID: "same", ProjectID: "proj-1", Title: "Unchanged"},
+		ticktick.Task{ID: "done", ProjectID: "proj-1", Title: "Will complete", Status: ticktick.TaskStatusCompleted},
+		ticktick.Task{ID: "edit", ProjectID: "proj-1", Title: "New title", Priority: ticktick.PriorityHigh, Tags: []string{"a", "b"}, Content: "notes"},
+		ticktick.Task{ID: "new", ProjectID: "proj-1", Title: "Added"},
+	)
+
+	// Act
+	got := ticktick.DiffSnapshots(a, b)
+
+	// Assert
+	if len(got.Added) != 1 || got.Added[0].ID != "new" {
+		t.Errorf("Added = %+v, want [new]", got.Added)
+	}
+	if len(got.Removed) != 1 || got.Removed[0].ID != "gone" {
+		t.Errorf("Removed = %+v, want [gone]", got.Removed)
+	}
+	if len(got.Completed) != 1 || got.Completed[0].ID != "done" {
+		t.Errorf("Completed = %+v, want [done]", got.Completed)
+	}
+	if len(got.Modified) != 1 || got.Modified[0].ID != "edit" {
+		t.Fatalf("Modified = %+v, want [edit]", got.Modified)
+	}
+	wantChanges := []ticktick.FieldChange{
+		{Field: "title", Old: "Old title", New: "New title"},
+		{Field: "priority", Old: "low", New: "high"},
+		{Field: "tags", Old: "a", New: "a,b"},
+		{Field: "content", Old: "", New: "notes"},
+	}
+	if !reflect.DeepEqual(got.Modified[0].Changes, wantChanges) {
+		t.Errorf("Modified[0].Changes = %+v, want %+v", got.Modified[0].Changes, wantChanges)
+	}
+	if got.Empty() {
+		t.Error("Empty() = true, want false")
+	}
+}
+
+func TestDiffSnapshots_Identical(t *testing.T) {
+	// Arrange
+	a := snapshotOf(ticktick.Task{ID: "t1", Title: "Same"})
+
+	// Act
+	got := ticktick.DiffSnapshots(a, a)
+
+	// Assert
+	if !got.Empty() {
+		t.Errorf("DiffSnapshots() = %+v, want empty diff", got)
+	}
+}
+
+func TestDiffTask_DueAndProject(t *testing.T) {
+	// Arrange
+	old := ticktick.Task{ProjectID: "p1", DueDate: "2026-02-12T14:59:59.000+0000"}
+	cur := ticktick.Task{ProjectID: "p2"}
+
+	// Act
+	got := ticktick.DiffTask(old, cur)
+
+	// Assert
+	want := []ticktick.FieldChange{
+		{Field: "project", Old: "p1", New: "p2"},
+		{Field: "due", Old: "2026-02-12T14:59:59.000+0000", New: ""},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffTask() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDiffTask_TagOrder(t *testing.T) {
+	// Arrange
+	old := ticktick.Task{Tags: []string{"work", "errand"}}
+	reordered := ticktick.Task{Tags: []string{"errand", "work"}}
+	added := ticktick.Task{Tags: []string{"work", "home", "errand"}}
+
+	// Act
+	same := ticktick.DiffTask(old, reordered)
+	changed := ticktick.DiffTask(old, added)
+
+	// Assert
+	if len(same) != 0 {
+		t.Errorf("DiffTask(reordered) = %+v, want no changes", same)
+	}
+	want := []ticktick.FieldChange{{Field: "tags", Old: "errand,work", New: "errand,home,work"}}
+	if !reflect.DeepEqual(changed, want) {
+		t.Errorf("DiffTask(added) = %+v, want %+v", changed, want)
+	}
+	if old.Tags[0] != "work" {
+		t.Errorf("DiffTask() reordered the task's tags: %v", old.Tags)
+	}
+}
+
+func TestClassifyRemoved(t *testing.T) {
+	// Arrange — one removed task is actually completed, the other is gone
+	client, cleanup := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/project/proj-1/task/completed":
+			json.NewEncoder(w).Encode(ticktick.Task{ID: "completed", Status: ticktick.TaskStatusCompleted})
+		case "/project/proj-1/task/deleted":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer cleanup()
+	d := &ticktick.SnapshotDiff{
+		Removed: []ticktick.TaskChange{
+			{ID: "completed", ProjectID: "proj-1"},
+			{ID: "deleted", ProjectID: "proj-1"},
+		},
+	}
+
+	// Act
+	client.ClassifyRemoved(d)
+
+	// Assert
+	if len(d.Completed) != 1 || d.Completed[0].ID != "completed" {
+		t.Errorf("Completed = %+v, want [completed]", d.Completed)
+	}
+	if len(d.Removed) != 1 || d.Removed[0].ID != "deleted" {
+		t.Errorf("Removed = %+v, want [deleted]", d.Removed)
+	}
+}
@@ -1,6 +1,10 @@
 package ticktick
 
-import "net/http"
+import (
+	"net/http"
+	"os"
+	"time"
+)
 
 // NewTestClient creates a Client with a custom httpClient for testing.
 func NewTestClient(httpClient *http.Client, accessToken string) *Client {
@@ -10,6 +14,11 @@ func NewTestClient(httpClient *http.Client, accessToken string) *Client {
 	}
 }
 
+// ExpireToken marks the access token of a client as expired, for testing.
+func ExpireToken(c *Client) {
+	c.expiresAt = 1
+}
+
 // SetBaseURL overrides the package-level baseURL for testing.
 // Returns a restore function that resets the original value.
 func SetBaseURL(url string) func() {
@@ -26,12 +35,67 @@ func SetTokenURL(url string) func() {
 	return func() { tokenURL = orig }
 }
 
-// ExchangeToken exposes exchangeToken for testing.
-func ExchangeToken(clientID, clientSecret, code string) (*OAuthToken, error) {
-	return exchangeToken(clientID, clientSecret, code)
+// ExchangeToken exposes exchangeToken for testing, using the default
+// redirect URI.
+func ExchangeToken(clientID, clientSecret, code, verifier string) (*OAuthToken, error) {
+	return exchangeToken(clientID, clientSecret, code, DefaultRedirectURI, verifier)
+}
+
+// CodeChallenge exposes codeChallenge for testing.
+func CodeChallenge(verifier string) string {
+	return codeChallenge(verifier)
 }
 
 // GenerateState exposes generateState for testing.
 func GenerateState() (string, error) {
 	return generateState()
 }
+
+// EnableTaskIndex turns on the persistent task index, which test clients
+// do not use by default.
+func EnableTaskIndex(c *Client) {
+	c.index = newTaskIndex()
+}
+
+// SetKDFIterations lowers the PBKDF2 work factor to keep tests fast.
+// Returns a restore function that resets the original value.
+func SetKDFIterations(n int) func() {
+	orig := tokenKDFIterations
+	tokenKDFIterations = n
+	return func() { tokenKDFIterations = orig }
+}
+
+// ForgetPassphrase clears the passphrase remembered for this process.
+func ForgetPassphrase() {
+	passphrase.Lock()
+	passphrase.value = ""
+	passphrase.Unlock()
+}
+
+// LockFile exposes lockFile for testing.
+func LockFile(path string) (func(), error) {
+	return lockFile(path)
+}
+
+// WriteFileAtomic exposes writeFileAtomic for testing.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	return writeFileAtomic(path, data, perm)
+}
+
+// SetLockTimeout overrides lockTimeout for testing.
+// Returns a restore function that resets the original value.
+func SetLockTimeout(d time.Duration) func() {
+	orig := lockTimeout
+	lockTimeout = d
+	return func() { lockTimeout = orig }
+}
+
+// SetClock overrides the clock of a webhook queue for testing.
+func (q *WebhookQueue) SetClock(now func() time.Time) {
+	q.now = now
+}
+
+// SetHooks sets the hook commands of a client, keyed by event, for testing.
+func SetHooks(c *Client, h map[string]string) {
+	c.hooks = h
+}
@@ -0,0 +1,29 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package ticktick
+
+import (
+	"os"
+	"time"
+)
+
+// lockStaleAfter is the age after which a lock file is assumed to be left
+// over from a process that crashed while holding it.
+const lockStaleAfter = 2 * time.Minute
+
+// tryLock is the fallback for platforms without flock, such as Windows: the
+// lock is held by whoever manages to create the lock file.
+func tryLock(path string) (func(), error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err == nil {
+		f.Close()
+		return func() { os.Remove(path) }, nil
+	}
+	if !os.IsExist(err) {
+		return nil, err
+	}
+	if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > lockStaleAfter {
+		os.Remove(path)
+	}
+	return nil, errLocked
+}
@@ -0,0 +1,192 @@
+package ticktick_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+)
+
+func TestWriteFileAtomic_ReplacesFile(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	path := filepath.Join(dir, "token.json")
+	os.WriteFile(path, []byte("old contents that are longer"), 0644)
+
+	// Act
+	err := ticktick.WriteFileAtomic(path, []byte("new"), 0600)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("WriteFileAtomic() returned unexpected error: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new" {
+		t.Errorf("file contents = %q, want %q", data, "new")
+	}
+	if runtime.GOOS != "windows" {
+		if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+			t.Errorf("file permissions = %o, want 600", info.Mode().Perm())
+		}
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("directory has %d entries, want only the file (temporary file left behind?)", len(entries))
+	}
+}
+
+func TestLockFile_Exclusive(t *testing.T) {
+	// Arrange
+	defer ticktick.SetLockTimeout(200 * time.Millisecond)()
+	path := filepath.Join(t.TempDir(), "token.json")
+	unlock, err := ticktick.LockFile(path)
+	if err != nil {
+		t.Fatalf("LockFile() returned unexpected error: %v", err)
+	}
+
+	// Act
+	_, errHeld := ticktick.LockFile(path)
+	unlock()
+	unlockAgain, errReleased := ticktick.LockFile(path)
+
+	// Assert
+	if errHeld == nil {
+		t.Error("LockFile() succeeded while the lock was held")
+	}
+	if errReleased != nil {
+		t.Errorf("LockFile() after unlock returned unexpected error: %v", errReleased)
+	} else {
+		unlockAgain()
+	}
+}
+
+func TestNewClient_ConcurrentRefreshOnce(t *testing.T) {
+	// Arrange — an expired token and a server that rejects a reused refresh token
+	setupAuthEnv(t)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("TICKTICK_ACCESS_TOKEN", "")
+	var refreshes atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.PostForm.Get("refresh_token") != "old-refresh" {
+			http.Error(w, "invalid_grant", http.StatusBadRequest)
+			return
+		}
+		refreshes.Add(1)
+		json.NewEncoder(w).Encode(ticktick.OAuthToken{AccessToken: "new-access", RefreshToken: "new-refresh", ExpiresIn: 3600})
+	}))
+	defer server.Close()
+	defer ticktick.SetTokenURL(server.URL)()
+	ticktick.SaveToken(&ticktick.OAuthToken{AccessToken: "old-access", RefreshToken: "old-refresh", ExpiresAt: 1})
+
+	// Act
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := ticktick.NewClient()
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	// Assert
+	for err := range errs {
+		if err != nil {
+			t.Errorf("NewClient() returned unexpected error: %v", err)
+		}
+	}
+	if n := refreshes.Load(); n != 1 {
+		t.Errorf("token refreshed %d times, want 1", n)
+	}
+	if token, _ := ticktick.LoadToken(); token.AccessToken != "new-access" {
+		t.Errorf("saved access token = %q, want new-access", token.AccessToken)
+	}
+}
+
+// refreshServers starts a token server that trades old-refresh for
+// new-access and an API server that accepts only new-access. It returns a
+// client read from the saved token and the number of refreshes and of API
+// requests sent with another token.
+func refreshServers(t *testing.T) (client *ticktick.Client, refreshes, rejected *atomic.Int32) {
+	t.Helper()
+	setupAuthEnv(t)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("TICKTICK_ACCESS_TOKEN", "")
+	refreshes, rejected = new(atomic.Int32), new(atomic.Int32)
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.PostForm.Get("refresh_token") != "old-refresh" {
+			http.Error(w, "invalid_grant", http.StatusBadRequest)
+			return
+		}
+		refreshes.Add(1)
+		json.NewEncoder(w).Encode(ticktick.OAuthToken{AccessToken: "new-access", RefreshToken: "new-refresh", ExpiresIn: 3600})
+	}))
+	t.Cleanup(tokenServer.Close)
+	t.Cleanup(ticktick.SetTokenURL(tokenServer.URL))
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer new-access" {
+			rejected.Add(1)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode([]ticktick.Project{{ID: "p1", Name: "Work"}})
+	}))
+	t.Cleanup(apiServer.Close)
+	t.Cleanup(ticktick.SetBaseURL(apiServer.URL))
+
+	ticktick.SaveToken(&ticktick.OAuthToken{AccessToken: "old-access", RefreshToken: "old-refresh", ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	client, err := ticktick.NewClient()
+	if err != nil {
+		t.Fatalf("NewClient() returned unexpected error: %v", err)
+	}
+	return client, refreshes, rejected
+}
+
+func TestClient_RefreshesExpiredToken(t *testing.T) {
+	// Arrange — a long-running client whose token expires after it was created
+	client, refreshes, rejected := refreshServers(t)
+	ticktick.SaveToken(&ticktick.OAuthToken{AccessToken: "old-access", RefreshToken: "old-refresh", ExpiresAt: 1})
+	ticktick.ExpireToken(client)
+
+	// Act
+	_, err := client.GetProjects()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("GetProjects() returned unexpected error: %v", err)
+	}
+	if refreshes.Load() != 1 || rejected.Load() != 0 {
+		t.Errorf("refreshes = %d, rejected requests = %d; want 1, 0", refreshes.Load(), rejected.Load())
+	}
+	if token, _ := ticktick.LoadToken(); token.AccessToken != "new-access" {
+		t.Errorf("saved access token = %q, want new-access", token.AccessToken)
+	}
+}
+
+func TestClient_RefreshesRejectedToken(t *testing.T) {
+	// Arrange — a token the API no longer accepts although it has not expired
+	client, refreshes, rejected := refreshServers(t)
+
+	// Act
+	_, first := client.GetProjects()
+	_, second := client.GetProjects()
+
+	// Assert — refreshed once, and the rejected request was sent again
+	if first != nil || second != nil {
+		t.Fatalf("GetProjects() errors = %v, %v; want nil", first, second)
+	}
+	if refreshes.Load() != 1 || rejected.Load() != 1 {
+		t.Errorf("refreshes = %d, rejected requests = %d; want 1, 1", refreshes.Load(), rejected.Load())
+	}
+}
@@ -0,0 +1,209 @@
+package ticktick
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TaskFilter matches tasks against a filter expression such as
+// "tag=work priority>=medium due<today".
+//
+// An expression is a list of clauses separated by spaces or commas, all of
+// which must match. Each clause is "field op value" where op is one of
+// =, !=, ~ (contains), <, <=, > or >=. Supported fields:
+//
+//   - title, content: text; = and != compare case-insensitively, ~ matches substrings
+//   - tag: = requires the tag, != excludes it
+//   - priority: none, low, medium or high; ordered comparisons allowed
+//   - due: any ParseDate value or "none"; compared by calendar day
+//   - project: project ID
+//
+// Values containing spaces or commas can be double-quoted.
+type TaskFilter struct {
+	clauses []filterClause
+}
+
+type filterClause struct {
+	field string
+	op    string
+	value string
+}
+
+var filterOps = []string{"!=", "<=", ">=", "=", "~", "<", ">"}
+
+// ParseFilter parses a filter expression. An empty expression matches every task.
+func ParseFilter(expr string) (*TaskFilter, error) {
+	tokens, err := splitFilterExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &TaskFilter{}
+	for _, tok := range tokens {
+		c, err := parseFilterClause(tok)
+		if err != nil {
+			return nil, err
+		}
+		f.clauses = append(f.clauses, c)
+	}
+	return f, nil
+}
+
+// Match reports whether t satisfies every clause of the filter.
+func (f *TaskFilter) Match(t Task) bool {
+	for _, c := range f.clauses {
+		if !c.match(t) {
+			return false
+		}
+	}
+	return true
+}
+
+func splitFilterExpr(expr string) ([]string, error) {
+	var tokens []string
+	var b strings.Builder
+	inQuote := false
+	for _, r := range expr {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+		case !inQuote && (r == ' ' || r == ',' || r == '\t'):
+			if b.Len() > 0 {
+				tokens = append(tokens, b.String())
+				b.Reset()
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	if inQuote {
+		return nil, fmt.Errorf("unterminated quote in filter: %s", expr)
+	}
+	if b.Len() > 0 {
+		tokens = append(tokens, b.String())
+	}
+	return tokens, nil
+}
+
+func parseFilterClause(tok string) (filterClause, error) {
+	idx := strings.IndexAny(tok, "!=<>~")
+	op := ""
+	if idx > 0 {
+		for _, candidate := range filterOps {
+			if strings.HasPrefix(tok[idx:], candidate) {
+				op = candidate
+				break
+			}
+		}
+	}
+	if op == "" {
+		return filterClause{}, fmt.Errorf("invalid filter clause: %s (expected field, operator and value)", tok)
+	}
+
+	c := filterClause{
+		field: strings.ToLower(tok[:idx]),
+		op:    op,
+		value: tok[idx+len(op):],
+	}
+
+	ordered := op == "<" || op == "<=" || op == ">" || op == ">="
+	switch c.field {
+	case "title", "content":
+		if ordered {
+			return c, fmt.Errorf("operator %s is not supported for %s", op, c.field)
+		}
+	case "tag", "project":
+		if op != "=" && op != "!=" {
+			return c, fmt.Errorf("operator %s is not supported for %s", op, c.field)
+		}
+	case "priority":
+		if op == "~" {
+			return c, fmt.Errorf("operator ~ is not supported for priority")
+		}
+		if _, err := ParsePriority(c.value); err != nil {
+			return c, err
+		}
+	case "due":
+		if op == "~" {
+			return c, fmt.Errorf("operator ~ is not supported for due")
+		}
+		if c.value == "none" {
+			if ordered {
+				return c, fmt.Errorf("due=none only supports = and !=")
+			}
+			break
+		}
+		due, err := ParseDate(c.value)
+		if err != nil {
+			return c, err
+		}
+		c.value = localDate(due)
+	default:
+		return c, fmt.Errorf("unknown filter field: %s (use title, content, tag, priority, due or project)", c.field)
+	}
+	return c, nil
+}
+
+func (c filterClause) match(t Task) bool {
+	switch c.field {
+	case "title":
+		return matchText(t.Title, c.op, c.value)
+	case "content":
+		return matchText(t.Content, c.op, c.value)
+	case "project":
+		return (t.ProjectID == c.value) == (c.op == "=")
+	case "tag":
+		has := false
+		for _, tag := range t.Tags {
+			if strings.EqualFold(tag, c.value) {
+				has = true
+				break
+			}
+		}
+		return has == (c.op == "=")
+	case "priority":
+		want, _ := ParsePriority(c.value)
+		return compareOrdered(t.Priority-want, c.op)
+	case "due":
+		due := localDate(t.DueDate)
+		if c.value == "none" {
+			return (due == "") == (c.op == "=")
+		}
+		if due == "" {
+			return c.op == "!="
+		}
+		return compareOrdered(strings.Compare(due, c.value), c.op)
+	}
+	return false
+}
+
+func matchText(s, op, value string) bool {
+	switch op {
+	case "=":
+		return strings.EqualFold(s, value)
+	case "!=":
+		return !strings.EqualFold(s, value)
+	case "~":
+		return strings.Contains(strings.ToLower(s), strings.ToLower(value))
+	}
+	return false
+}
+
+// compareOrdered applies op to the sign of cmp (negative, zero or positive).
+func compareOrdered(cmp int, op string) bool {
+	switch op {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
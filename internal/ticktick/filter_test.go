@@ -0,0 +1,95 @@
+package ticktick
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTaskFilter_Match(t *testing.T) {
+	now := time.Now()
+	yesterday := endOfDay(now.AddDate(0, 0, -1))
+
+	task := Task{
+		ProjectID: "proj-1",
+		Title:     "Review quarterly report",
+		Content:   "Check the numbers",
+		Priority:  PriorityMedium,
+		Tags:      []string{"Work", "finance"},
+		DueDate:   yesterday,
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		want bool
+	}{
+		{"empty expression", "", true},
+		{"tag present", "tag=work", true},
+		{"tag absent", "tag=home", false},
+		{"tag excluded", "tag!=home", true},
+		{"priority equal", "priority=medium", true},
+		{"priority at least", "priority>=low", true},
+		{"priority above", "priority>medium", false},
+		{"overdue", "due<today", true},
+		{"due this week", "due<=+7d", true},
+		{"due after", "due>today", false},
+		{"due none", "due=none", false},
+		{"title contains", "title~quarterly", true},
+		{"title equals", `title="review quarterly report"`, true},
+		{"content contains", "content~NUMBERS", true},
+		{"project", "project=proj-1", true},
+		{"all clauses space separated", "tag=work priority>=medium due<today", true},
+		{"comma separated with one failing", "tag=work,priority=high", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := ParseFilter(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseFilter(%q) returned unexpected error: %v", tt.expr, err)
+			}
+			if got := f.Match(task); got != tt.want {
+				t.Errorf("ParseFilter(%q).Match() = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("no due date", func(t *testing.T) {
+		undated := Task{Title: "Someday"}
+		for expr, want := range map[string]bool{"due=none": true, "due<today": false, "due!=today": true} {
+			f, err := ParseFilter(expr)
+			if err != nil {
+				t.Fatalf("ParseFilter(%q) returned unexpected error: %v", expr, err)
+			}
+			if got := f.Match(undated); got != want {
+				t.Errorf("ParseFilter(%q).Match(undated) = %v, want %v", expr, got, want)
+			}
+		}
+	})
+}
+
+func TestParseFilter_InvalidInputs(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+	}{
+		{"missing operator", "work"},
+		{"missing field", "=work"},
+		{"unknown field", "color=red"},
+		{"invalid priority", "priority=urgent"},
+		{"invalid due", "due<someday"},
+		{"ordered tag", "tag>work"},
+		{"ordered title", "title<abc"},
+		{"contains priority", "priority~high"},
+		{"ordered due none", "due<none"},
+		{"unterminated quote", `title="abc`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseFilter(tt.expr); err == nil {
+				t.Errorf("ParseFilter(%q) expected error, got nil", tt.expr)
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package ticktick
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	handlesFile = "handles.json"
+	aliasesFile = "aliases.json"
+
+	// minTaskPrefix is the shortest task ID prefix accepted as a reference.
+	minTaskPrefix = 4
+)
+
+// handleStore persists the short numeric handles assigned to tasks.
+// Handles are never reused, so a handle always refers to the same task.
+type handleStore struct {
+	Next  int            `json:"next"`
+	Tasks map[string]int `json:"tasks"` // task ID -> handle
+}
+
+func loadHandles() (*handleStore, error) {
+	store := &handleStore{Next: 1, Tasks: make(map[string]int)}
+	data, err := os.ReadFile(filepath.Join(configDir(), handlesFile))
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", handlesFile, err)
+	}
+	if store.Tasks == nil {
+		store.Tasks = make(map[string]int)
+	}
+	return store, nil
+}
+
+func (s *handleStore) save() error {
+	dir := configDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(dir, handlesFile), data, 0600)
+}
+
+// AssignHandles returns the short handle of every task, assigning the next
+// free number to tasks seen for the first time. Handles are shown as "#N".
+// New handles are assigned under a file lock, so concurrent ticky processes
+// never hand out the same number twice.
+func AssignHandles(tasks []Task) (map[string]int, error) {
+	store, err := loadHandles()
+	if err != nil {
+		return nil, err
+	}
+	if handles, ok := store.lookup(tasks); ok {
+		return handles, nil
+	}
+
+	unlock, err := lockFile(filepath.Join(configDir(), handlesFile))
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// Another process may have assigned handles since the first read
+	if store, err = loadHandles(); err != nil {
+		return nil, err
+	}
+	for _, t := range tasks {
+		if _, ok := store.Tasks[t.ID]; !ok {
+			store.Tasks[t.ID] = store.Next
+			store.Next++
+		}
+	}
+	if err := store.save(); err != nil {
+		return nil, err
+	}
+	handles, _ := store.lookup(tasks)
+	return handles, nil
+}
+
+// lookup returns the handles of tasks, and whether all of them have one.
+func (s *handleStore) lookup(tasks []Task) (map[string]int, bool) {
+	handles := make(map[string]int, len(tasks))
+	for _, t := range tasks {
+		h, ok := s.Tasks[t.ID]
+		if !ok {
+			return nil, false
+		}
+		handles[t.ID] = h
+	}
+	return handles, true
+}
+
+// LoadAliases returns the named task bookmarks, keyed by alias name.
+func LoadAliases() (map[string]string, error) {
+	aliases := make(map[string]string)
+	data, err := os.ReadFile(filepath.Join(configDir(), aliasesFile))
+	if os.IsNotExist(err) {
+		return aliases, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", aliasesFile, err)
+	}
+	return aliases, nil
+}
+
+// UpdateAliases changes the named task bookmarks with fn and saves them. The
+// aliases file is locked meanwhile, so concurrent changes are not lost. If fn
+// returns an error, nothing is saved.
+func UpdateAliases(fn func(aliases map[string]string) error) error {
+	path := filepath.Join(configDir(), aliasesFile)
+	unlock, err := lockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	aliases, err := LoadAliases()
+	if err != nil {
+		return err
+	}
+	if err := fn(aliases); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(aliases, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0600)
+}
+
+// ValidateAliasName rejects alias names that could be confused with other
+// task references.
+func ValidateAliasName(name string) error {
+	switch {
+	case name == "" || name == "-":
+		return fmt.Errorf("invalid alias name: %q", name)
+	case strings.HasPrefix(name, "#"):
+		return fmt.Errorf("invalid alias name %q: must not start with #", name)
+	case strings.ContainsAny(name, " \t\r\n,"):
+		return fmt.Errorf("invalid alias name %q: must not contain spaces or commas", name)
+	case isHex(name):
+		return fmt.Errorf("invalid alias name %q: looks like a task ID", name)
+	}
+	return nil
+}
+
+// ResolveTaskRef turns a task reference into a task ID. A reference is an
+// alias set with "alias set", a handle such as "#12", a full task ID, or a
+// unique prefix (at least 4 characters) of a task ID seen before. Anything
+// else is returned unchanged.
+func (c *Client) ResolveTaskRef(ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+
+	aliases, err := LoadAliases()
+	if err != nil {
+		return "", err
+	}
+	if id, ok := aliases[ref]; ok {
+		return id, nil
+	}
+
+	if num, ok := strings.CutPrefix(ref, "#"); ok {
+		h, err := strconv.Atoi(num)
+		if err != nil {
+			return "", fmt.Errorf("invalid task handle: %s", ref)
+		}
+		store, err := loadHandles()
+		if err != nil {
+			return "", err
+		}
+		for id, taskHandle := range store.Tasks {
+			if taskHandle == h {
+				return id, nil
+			}
+		}
+		return "", fmt.Errorf("unknown task handle %s (run tasks list to assign handles)", ref)
+	}
+
+	if len(ref) >= 24 || len(ref) < minTaskPrefix || !isHex(ref) {
+		return ref, nil
+	}
+	store, err := loadHandles()
+	if err != nil {
+		return "", err
+	}
+	known := c.index.ids()
+	for id := range store.Tasks {
+		known = append(known, id)
+	}
+	var matches []string
+	seen := make(map[string]bool)
+	for _, id := range known {
+		if strings.HasPrefix(id, ref) && !seen[id] {
+			seen[id] = true
+			matches = append(matches, id)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return ref, nil
+	case 1:
+		return matches[0], nil
+	default:
+		sort.Strings(matches)
+		return "", fmt.Errorf("task ID prefix %q is ambiguous; candidates: %s", ref, strings.Join(matches, ", "))
+	}
+}
+
+func isHex(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return false
+		}
+	}
+	return true
+}
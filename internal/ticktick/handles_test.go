@@ -0,0 +1,188 @@
+package ticktick_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+)
+
+const (
+	handleTaskA = "6631f0a8e4b0aaaaaaaaaaaa"
+	handleTaskB = "6631f0a8e4b0bbbbbbbbbbbb"
+	handleTaskC = "7000000000000000000000cc"
+)
+
+func TestAssignHandles_Stable(t *testing.T) {
+	// Arrange
+	t.Setenv("HOME", t.TempDir())
+
+	// Act
+	first, err := ticktick.AssignHandles([]ticktick.Task{{ID: handleTaskA}, {ID: handleTaskB}})
+	if err != nil {
+		t.Fatalf("AssignHandles() returned unexpected error: %v", err)
+	}
+	second, err := ticktick.AssignHandles([]ticktick.Task{{ID: handleTaskC}, {ID: handleTaskB}})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("AssignHandles() returned unexpected error: %v", err)
+	}
+	if first[handleTaskA] != 1 || first[handleTaskB] != 2 {
+		t.Errorf("first handles = %v, want A=1 B=2", first)
+	}
+	if second[handleTaskB] != 2 || second[handleTaskC] != 3 {
+		t.Errorf("second handles = %v, want B to keep 2 and C to get 3", second)
+	}
+}
+
+func TestResolveTaskRef(t *testing.T) {
+	// Arrange
+	t.Setenv("HOME", t.TempDir())
+	if _, err := ticktick.AssignHandles([]ticktick.Task{{ID: handleTaskA}, {ID: handleTaskB}, {ID: handleTaskC}}); err != nil {
+		t.Fatalf("AssignHandles() returned unexpected error: %v", err)
+	}
+	err := ticktick.UpdateAliases(func(aliases map[string]string) error {
+		aliases["standup"] = handleTaskC
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("UpdateAliases() returned unexpected error: %v", err)
+	}
+	client := ticktick.NewTestClient(http.DefaultClient, "test-token")
+
+	tests := []struct {
+		name string
+		ref  string
+		want string
+	}{
+		{"alias", "standup", handleTaskC},
+		{"handle", "#2", handleTaskB},
+		{"full id", handleTaskA, handleTaskA},
+		{"unique prefix", "7000", handleTaskC},
+		{"longer unique prefix", "6631f0a8e4b0a", handleTaskA},
+		{"unknown prefix passes through", "abcd", "abcd"},
+		{"non-hex passes through", "task-1", "task-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := client.ResolveTaskRef(tt.ref)
+			if err != nil {
+				t.Fatalf("ResolveTaskRef(%q) returned unexpected error: %v", tt.ref, err)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveTaskRef(%q) = %q, want %q", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveTaskRef_Errors(t *testing.T) {
+	// Arrange
+	t.Setenv("HOME", t.TempDir())
+	if _, err := ticktick.AssignHandles([]ticktick.Task{{ID: handleTaskA}, {ID: handleTaskB}}); err != nil {
+		t.Fatalf("AssignHandles() returned unexpected error: %v", err)
+	}
+	client := ticktick.NewTestClient(http.DefaultClient, "test-token")
+
+	tests := []struct {
+		name string
+		ref  string
+		want string
+	}{
+		{"ambiguous prefix", "6631", "ambiguous"},
+		{"unknown handle", "#99", "unknown task handle"},
+		{"malformed handle", "#abc", "invalid task handle"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.ResolveTaskRef(tt.ref)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ResolveTaskRef(%q) error = %v, want to contain %q", tt.ref, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateAliasName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"standup", false},
+		{"weekly-review", false},
+		{"", true},
+		{"#1", true},
+		{"has space", true},
+		{"cafe", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ticktick.ValidateAliasName(tt.name); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateAliasName(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAssignHandlesAndAliases_Concurrent(t *testing.T) {
+	// Arrange
+	t.Setenv("HOME", t.TempDir())
+	const workers = 8
+
+	// Act — each worker lists its own task and sets its own alias
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := fmt.Sprintf("%024x", i)
+			if _, err := ticktick.AssignHandles([]ticktick.Task{{ID: id}}); err != nil {
+				errs <- err
+			}
+			errs <- ticktick.UpdateAliases(func(aliases map[string]string) error {
+				aliases[fmt.Sprintf("a%d", i)] = id
+				return nil
+			})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	// Assert
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("concurrent update returned unexpected error: %v", err)
+		}
+	}
+	var tasks []ticktick.Task
+	for i := 0; i < workers; i++ {
+		tasks = append(tasks, ticktick.Task{ID: fmt.Sprintf("%024x", i)})
+	}
+	handles, err := ticktick.AssignHandles(tasks)
+	if err != nil {
+		t.Fatalf("AssignHandles() returned unexpected error: %v", err)
+	}
+	seen := make(map[int]bool)
+	for _, h := range handles {
+		if seen[h] || h < 1 || h > workers {
+			t.Errorf("handles = %v, want each of 1-%d exactly once", handles, workers)
+			break
+		}
+		seen[h] = true
+	}
+	aliases, err := ticktick.LoadAliases()
+	if err != nil {
+		t.Fatalf("LoadAliases() returned unexpected error: %v", err)
+	}
+	if len(aliases) != workers {
+		t.Errorf("aliases = %v, want %d entries", aliases, workers)
+	}
+}
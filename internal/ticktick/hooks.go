@@ -0,0 +1,208 @@
+package ticktick
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Hook events. Pre-hooks run before the request is sent and may rewrite or
+// reject it; post-hooks run after the API accepted it.
+const (
+	HookPreCreate    = "pre-create"
+	HookPostCreate   = "post-create"
+	HookPreUpdate    = "pre-update"
+	HookPostComplete = "post-complete"
+	HookPostDelete   = "post-delete"
+)
+
+// hookTimeout bounds how long a hook may run.
+const hookTimeout = 30 * time.Second
+
+// hookConfigKeys maps hook events to the config keys naming their commands.
+var hookConfigKeys = map[string]string{
+	HookPreCreate:    "hook_pre_create",
+	HookPostCreate:   "hook_post_create",
+	HookPreUpdate:    "hook_pre_update",
+	HookPostComplete: "hook_post_complete",
+	HookPostDelete:   "hook_post_delete",
+}
+
+// HookError is returned when a pre-hook rejects a request.
+type HookError struct {
+	Event   string
+	Message string
+}
+
+func (e *HookError) Error() string {
+	return fmt.Sprintf("%s hook rejected the request: %s", e.Event, e.Message)
+}
+
+// HooksDisabled turns off all hooks, e.g. for a bulk import that should not
+// run them for every task. Setting TICKY_NO_HOOKS has the same effect.
+var HooksDisabled bool
+
+// hooks maps hook events to the shell commands run for them. A nil map runs
+// no hooks.
+type hooks map[string]string
+
+// loadHooks returns the hooks configured for the active profile.
+func loadHooks() hooks {
+	if HooksDisabled || os.Getenv("TICKY_NO_HOOKS") != "" {
+		return nil
+	}
+	h := make(hooks)
+	for event, key := range hookConfigKeys {
+		if command, _, err := ConfigValue(key); err == nil && command != "" {
+			h[event] = command
+		}
+	}
+	return h
+}
+
+// hookTaskRef is the stdin of post-complete and post-delete hooks when the
+// task could not be fetched.
+type hookTaskRef struct {
+	ID        string `json:"id"`
+	ProjectID string `json:"projectId"`
+}
+
+// runPreHook runs the hook for event with req as JSON on stdin and env added
+// to its environment. If the hook prints JSON, it replaces req; if it exits
+// with a non-zero status, the request is rejected with its message. Other
+// output of the hook goes to w.
+func runPreHook[T any](w io.Writer, h hooks, event string, req *T, env ...string) error {
+	command, ok := h[event]
+	if !ok {
+		return nil
+	}
+	input, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s hook input: %w", event, err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	err = runHook(command, event, input, &stdout, &stderr, env...)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = strings.TrimSpace(stdout.String())
+		}
+		if msg == "" {
+			msg = exitErr.Error()
+		}
+		return &HookError{Event: event, Message: msg}
+	}
+	w.Write(stderr.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to run %s hook: %w", event, err)
+	}
+
+	if len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+		return nil
+	}
+	var rewritten T
+	dec := json.NewDecoder(&stdout)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&rewritten); err != nil {
+		return fmt.Errorf("invalid output of %s hook: %w", event, err)
+	}
+	*req = rewritten
+	return nil
+}
+
+// runPostHook runs the hook for event with v as JSON on stdin. The request
+// has already been carried out, so failures are only reported on w, which
+// also gets the hook's output.
+func (h hooks) runPostHook(w io.Writer, event string, v any) {
+	command, ok := h[event]
+	if !ok {
+		return
+	}
+	input, err := json.Marshal(v)
+	if err == nil {
+		// Hook output must not mix with ticky's own output on stdout
+		err = runHook(command, event, input, w, w)
+	}
+	if err != nil {
+		fmt.Fprintf(w, "Warning: %s hook failed: %v\n", event, err)
+	}
+}
+
+// runHook runs a hook command with the shell.
+func runHook(command, event string, input []byte, stdout, stderr io.Writer, env ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+	cmd := shellCommand(ctx, command)
+	cmd.Env = append(os.Environ(), "TICKY_HOOK="+event, "TICKY_PROFILE="+ActiveProfile())
+	cmd.Env = append(cmd.Env, env...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return cmd.Run()
+}
+
+// SetHookOutput sets where the output of hooks and hook warnings go, instead
+// of standard error. The terminal UI discards them so they do not draw over
+// the screen; a rejecting pre-hook still fails the request.
+func (c *Client) SetHookOutput(w io.Writer) {
+	c.hookOutput = w
+}
+
+// hookStderr returns where the output of hooks goes.
+func (c *Client) hookStderr() io.Writer {
+	if c.hookOutput != nil {
+		return c.hookOutput
+	}
+	return os.Stderr
+}
+
+// hookProjectName returns the name of the project with id for hooks, or ""
+// if it cannot be found. An empty or Inbox ID is the Inbox.
+func (c *Client) hookProjectName(id string) string {
+	if id == "" || strings.HasPrefix(id, "inbox") {
+		return "Inbox"
+	}
+	projects, _, err := c.cachedProjects()
+	if err != nil {
+		return ""
+	}
+	for _, p := range projects {
+		if p.ID == id {
+			return p.Name
+		}
+	}
+	return ""
+}
+
+// hookTask returns the stdin of the post-hook for event on a task that is
+// about to be completed or deleted. The task is fetched first, since it is
+// gone after a delete; if that fails, only its IDs are passed. It returns nil
+// when no hook is configured for event.
+func (c *Client) hookTask(event, projectID, taskID string) any {
+	if _, ok := c.hooks[event]; !ok {
+		return nil
+	}
+	task, err := c.GetTask(projectID, taskID)
+	if err != nil {
+		return hookTaskRef{ID: taskID, ProjectID: projectID}
+	}
+	return task
+}
+
+// shellCommand returns a command that runs command with the system shell.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
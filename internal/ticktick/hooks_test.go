@@ -0,0 +1,242 @@
+package ticktick_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+)
+
+// hookServer answers every request with a task and records the request
+// bodies by path.
+func hookServer(t *testing.T, bodies map[string]string) (*ticktick.Client, func()) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("hook tests use POSIX shell commands")
+	}
+	return setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies[r.URL.Path] = string(body)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ticktick.Task{ID: "new-task", ProjectID: "team", Title: "Plan"})
+	})
+}
+
+func TestCreateTask_HooksRewriteAndObserve(t *testing.T) {
+	// Arrange
+	bodies := make(map[string]string)
+	client, cleanup := hookServer(t, bodies)
+	defer cleanup()
+	out := filepath.Join(t.TempDir(), "post-create")
+	ticktick.SetHooks(client, map[string]string{
+		ticktick.HookPreCreate:  `sed 's/"title":"Plan"/"title":"Plan","tags":["team-x"]/'`,
+		ticktick.HookPostCreate: `{ echo "$TICKY_HOOK"; cat; } > ` + out,
+	})
+	req := &ticktick.TaskCreateRequest{Title: "Plan", ProjectID: "team"}
+
+	// Act
+	task, err := client.CreateTask(req)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("CreateTask() returned unexpected error: %v", err)
+	}
+	if !strings.Contains(bodies["/task"], `"tags":["team-x"]`) || len(req.Tags) != 1 {
+		t.Errorf("sent %s, want the tag added by the pre-create hook", bodies["/task"])
+	}
+	data, _ := os.ReadFile(out)
+	if !strings.HasPrefix(string(data), "post-create\n") || !strings.Contains(string(data), `"id":"`+task.ID+`"`) {
+		t.Errorf("post-create hook saw %q, want the event and the created task", data)
+	}
+}
+
+func TestPreHooks_Reject(t *testing.T) {
+	tests := []struct {
+		name    string
+		hook    string
+		wantMsg string
+	}{
+		{"message on stderr", `echo "Team tasks need a due date" >&2; exit 1`, "pre-create hook rejected the request: Team tasks need a due date"},
+		{"invalid output", `echo not json`, "invalid output of pre-create hook"},
+		{"unknown field", `echo '{"title":"x","colour":"red"}'`, "invalid output of pre-create hook"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			bodies := make(map[string]string)
+			client, cleanup := hookServer(t, bodies)
+			defer cleanup()
+			ticktick.SetHooks(client, map[string]string{ticktick.HookPreCreate: tt.hook})
+
+			// Act
+			_, err := client.CreateTask(&ticktick.TaskCreateRequest{Title: "Plan"})
+
+			// Assert
+			if err == nil || !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Fatalf("CreateTask() error = %v, want %q", err, tt.wantMsg)
+			}
+			if len(bodies) != 0 {
+				t.Errorf("requests sent = %v, want none", bodies)
+			}
+		})
+	}
+}
+
+func TestUpdateTask_PreHookRejectionIsHookError(t *testing.T) {
+	// Arrange
+	bodies := make(map[string]string)
+	client, cleanup := hookServer(t, bodies)
+	defer cleanup()
+	ticktick.SetHooks(client, map[string]string{ticktick.HookPreUpdate: `grep -q '"title":"Final"' || { echo "title must be Final"; exit 2; }`})
+
+	// Act
+	_, rejected := client.UpdateTask(&ticktick.TaskUpdateRequest{ID: "t1", ProjectID: "p1", Title: "Draft"})
+	_, accepted := client.UpdateTask(&ticktick.TaskUpdateRequest{ID: "t1", ProjectID: "p1", Title: "Final"})
+
+	// Assert
+	var hookErr *ticktick.HookError
+	if !errors.As(rejected, &hookErr) || hookErr.Event != ticktick.HookPreUpdate || hookErr.Message != "title must be Final" {
+		t.Errorf("UpdateTask(Draft) error = %v, want a pre-update HookError with the stdout message", rejected)
+	}
+	if accepted != nil || bodies["/task/t1"] == "" {
+		t.Errorf("UpdateTask(Final) error = %v, sent %q; want the unchanged request sent", accepted, bodies["/task/t1"])
+	}
+}
+
+func TestCompleteAndDeleteTask_PostHooks(t *testing.T) {
+	// Arrange
+	bodies := make(map[string]string)
+	client, cleanup := hookServer(t, bodies)
+	defer cleanup()
+	out := filepath.Join(t.TempDir(), "post")
+	ticktick.SetHooks(client, map[string]string{
+		ticktick.HookPostComplete: `{ echo "$TICKY_HOOK"; cat; echo; } >> ` + out,
+		ticktick.HookPostDelete:   `exit 1`,
+	})
+
+	// Act
+	completeErr := client.CompleteTask("p1", "t1")
+	deleteErr := client.DeleteTask("p1", "t2")
+
+	// Assert — a failing post-hook does not fail the finished operation
+	if completeErr != nil || deleteErr != nil {
+		t.Fatalf("errors = %v, %v; want nil", completeErr, deleteErr)
+	}
+	// The hook gets the task fetched before completion, marked completed
+	data, _ := os.ReadFile(out)
+	got := string(data)
+	if !strings.HasPrefix(got, "post-complete\n") || !strings.Contains(got, `"title":"Plan"`) || !strings.Contains(got, `"status":2`) {
+		t.Errorf("post-complete hook saw %q, want the full completed task", got)
+	}
+}
+
+func TestCreateTask_PreHookProjectName(t *testing.T) {
+	// Arrange
+	if runtime.GOOS == "windows" {
+		t.Skip("hook tests use POSIX shell commands")
+	}
+	t.Setenv("HOME", t.TempDir())
+	client, cleanup := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/project" {
+			json.NewEncoder(w).Encode([]ticktick.Project{{ID: "team", Name: "Team X"}})
+			return
+		}
+		json.NewEncoder(w).Encode(ticktick.Task{ID: "new-task", ProjectID: "team", Title: "Plan"})
+	})
+	defer cleanup()
+	out := filepath.Join(t.TempDir(), "names")
+	ticktick.SetHooks(client, map[string]string{ticktick.HookPreCreate: `echo "$TICKY_PROJECT_NAME" >> ` + out})
+
+	// Act
+	_, teamErr := client.CreateTask(&ticktick.TaskCreateRequest{Title: "Plan", ProjectID: "team"})
+	_, inboxErr := client.CreateTask(&ticktick.TaskCreateRequest{Title: "Plan"})
+
+	// Assert
+	if teamErr != nil || inboxErr != nil {
+		t.Fatalf("errors = %v, %v; want nil", teamErr, inboxErr)
+	}
+	if data, _ := os.ReadFile(out); string(data) != "Team X\nInbox\n" {
+		t.Errorf("TICKY_PROJECT_NAME = %q, want Team X then Inbox", data)
+	}
+}
+
+func TestNewClient_NoHooks(t *testing.T) {
+	// Arrange — a rejecting pre-create hook, turned off with TICKY_NO_HOOKS
+	if runtime.GOOS == "windows" {
+		t.Skip("hook tests use POSIX shell commands")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("TICKTICK_ACCESS_TOKEN", "test-token")
+	t.Setenv("TICKY_HOOK_PRE_CREATE", "exit 1")
+	t.Setenv("TICKY_NO_HOOKS", "1")
+	_, cleanup := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ticktick.Task{ID: "new-task", Title: "Plan"})
+	})
+	defer cleanup()
+	client, err := ticktick.NewClient()
+	if err != nil {
+		t.Fatalf("NewClient() returned unexpected error: %v", err)
+	}
+
+	// Act
+	_, err = client.CreateTask(&ticktick.TaskCreateRequest{Title: "Plan"})
+
+	// Assert
+	if err != nil {
+		t.Errorf("CreateTask() error = %v, want the hook skipped", err)
+	}
+}
+
+func TestMoveTask_RunsNoHooks(t *testing.T) {
+	// Arrange — a pre-create hook that would reject the copy
+	bodies := make(map[string]string)
+	client, cleanup := hookServer(t, bodies)
+	defer cleanup()
+	out := filepath.Join(t.TempDir(), "post-delete")
+	ticktick.SetHooks(client, map[string]string{
+		ticktick.HookPreCreate:  `exit 1`,
+		ticktick.HookPostDelete: `cat > ` + out,
+	})
+
+	// Act
+	_, err := client.MoveTask(&ticktick.Task{ID: "t1", ProjectID: "p1", Title: "Plan"}, "team")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("MoveTask() returned unexpected error: %v", err)
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Error("MoveTask() ran the post-delete hook")
+	}
+}
+
+func TestSetHookOutput(t *testing.T) {
+	// Arrange
+	bodies := make(map[string]string)
+	client, cleanup := hookServer(t, bodies)
+	defer cleanup()
+	ticktick.SetHooks(client, map[string]string{ticktick.HookPostCreate: `echo "from hook"; exit 1`})
+	var buf strings.Builder
+	client.SetHookOutput(&buf)
+
+	// Act
+	_, err := client.CreateTask(&ticktick.TaskCreateRequest{Title: "Plan"})
+
+	// Assert — the hook output and the warning go to the writer
+	if err != nil {
+		t.Fatalf("CreateTask() returned unexpected error: %v", err)
+	}
+	if got := buf.String(); !strings.Contains(got, "from hook") || !strings.Contains(got, "Warning: post-create hook failed") {
+		t.Errorf("hook output = %q, want the hook output and the warning", got)
+	}
+}
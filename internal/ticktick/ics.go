@@ -0,0 +1,335 @@
+package ticktick
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const icsImportsFile = "ics_imports.json"
+
+// ICSTodo is a VTODO component parsed from an iCalendar file.
+type ICSTodo struct {
+	UID         string
+	Summary     string
+	Description string
+	Due         time.Time
+	AllDay      bool
+	TimeZone    string
+	Priority    int // iCalendar priority: 0 (undefined), 1 (highest) to 9 (lowest)
+	Categories  []string
+	RRule       string
+	Status      string
+}
+
+// Completed reports whether the VTODO is marked as completed.
+func (t ICSTodo) Completed() bool {
+	return strings.EqualFold(t.Status, "COMPLETED")
+}
+
+// CreateRequest converts the VTODO into a task creation request for projectID.
+func (t ICSTodo) CreateRequest(projectID string) *TaskCreateRequest {
+	req := &TaskCreateRequest{
+		Title:     t.Summary,
+		ProjectID: projectID,
+		Content:   t.Description,
+		Priority:  ICSPriority(t.Priority),
+		Tags:      t.Categories,
+		TimeZone:  t.TimeZone,
+	}
+	if !t.Due.IsZero() {
+		if t.AllDay {
+			req.DueDate = endOfDay(t.Due)
+			req.IsAllDay = true
+		} else {
+			req.DueDate = formatAPITime(t.Due)
+		}
+	}
+	if t.RRule != "" {
+		req.RepeatFlag = "RRULE:" + t.RRule
+	}
+	return req
+}
+
+// ICSPriority maps an iCalendar priority (RFC 5545 section 3.8.1.9) to a TickTick priority.
+func ICSPriority(p int) int {
+	switch {
+	case p >= 1 && p <= 4:
+		return PriorityHigh
+	case p == 5:
+		return PriorityMedium
+	case p >= 6 && p <= 9:
+		return PriorityLow
+	default:
+		return PriorityNone
+	}
+}
+
+// ParseICS parses all VTODO components from an iCalendar stream.
+// Folded lines are unfolded and TZID parameters on DUE are honored.
+func ParseICS(r io.Reader) ([]ICSTodo, error) {
+	lines, err := unfoldICSLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var todos []ICSTodo
+	var cur *ICSTodo
+	depth := 0 // nesting depth of components inside the current VTODO (e.g. VALARM)
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+		name, params, value, err := parseICSContentLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VTODO"):
+			cur = &ICSTodo{}
+			depth = 0
+			continue
+		case name == "END" && strings.EqualFold(value, "VTODO"):
+			if cur != nil {
+				todos = append(todos, *cur)
+			}
+			cur = nil
+			continue
+		case cur == nil:
+			continue
+		case name == "BEGIN":
+			depth++
+			continue
+		case name == "END":
+			depth--
+			continue
+		case depth > 0:
+			continue
+		}
+
+		switch name {
+		case "UID":
+			cur.UID = value
+		case "SUMMARY":
+			cur.Summary = unescapeICSText(value)
+		case "DESCRIPTION":
+			cur.Description = unescapeICSText(value)
+		case "PRIORITY":
+			p, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid PRIORITY: %s", i+1, value)
+			}
+			cur.Priority = p
+		case "CATEGORIES":
+			for _, c := range splitICSList(value) {
+				if c = strings.TrimSpace(unescapeICSText(c)); c != "" {
+					cur.Categories = append(cur.Categories, c)
+				}
+			}
+		case "RRULE":
+			cur.RRule = value
+		case "STATUS":
+			cur.Status = strings.ToUpper(value)
+		case "DUE":
+			due, allDay, err := parseICSDateTime(value, params)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid DUE: %w", i+1, err)
+			}
+			cur.Due = due
+			cur.AllDay = allDay
+			cur.TimeZone = ""
+			if loc, ok := icsLocation(params["TZID"]); ok && !allDay {
+				cur.TimeZone = loc.String()
+			}
+		}
+	}
+
+	return todos, nil
+}
+
+// unfoldICSLines reads content lines, joining folded continuation lines
+// (those starting with a space or tab) onto the preceding line.
+func unfoldICSLines(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read calendar: %w", err)
+	}
+	return lines, nil
+}
+
+// parseICSContentLine splits "NAME;PARAM=VALUE:value" into its parts.
+// Parameter names are upper-cased; quoted parameter values may contain ':' and ';'.
+func parseICSContentLine(line string) (string, map[string]string, string, error) {
+	params := make(map[string]string)
+
+	end := strings.IndexAny(line, ";:")
+	if end < 0 {
+		return "", nil, "", fmt.Errorf("malformed content line: %q", line)
+	}
+	name := strings.ToUpper(line[:end])
+
+	rest := line[end:]
+	for strings.HasPrefix(rest, ";") {
+		rest = rest[1:]
+		eq := strings.IndexByte(rest, '=')
+		if eq < 0 {
+			return "", nil, "", fmt.Errorf("malformed parameter in %q", line)
+		}
+		key := strings.ToUpper(rest[:eq])
+		rest = rest[eq+1:]
+
+		var val string
+		if strings.HasPrefix(rest, `"`) {
+			closing := strings.IndexByte(rest[1:], '"')
+			if closing < 0 {
+				return "", nil, "", fmt.Errorf("unterminated quoted parameter in %q", line)
+			}
+			val = rest[1 : closing+1]
+			rest = rest[closing+2:]
+		} else {
+			stop := strings.IndexAny(rest, ";:")
+			if stop < 0 {
+				return "", nil, "", fmt.Errorf("malformed content line: %q", line)
+			}
+			val = rest[:stop]
+			rest = rest[stop:]
+		}
+		params[key] = val
+	}
+
+	if !strings.HasPrefix(rest, ":") {
+		return "", nil, "", fmt.Errorf("malformed content line: %q", line)
+	}
+	return name, params, rest[1:], nil
+}
+
+// parseICSDateTime parses a DATE or DATE-TIME value. It reports whether the
+// value is a date without a time component.
+func parseICSDateTime(value string, params map[string]string) (time.Time, bool, error) {
+	if strings.EqualFold(params["VALUE"], "DATE") || len(value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", value, time.Local)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+
+	loc, ok := icsLocation(params["TZID"])
+	if !ok {
+		loc = time.Local
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+// icsLocation loads the time zone named by a TZID parameter. A leading "/"
+// (a globally unique TZID) is ignored. It reports false if tzid is empty or
+// not a known zone, in which case local time is used.
+func icsLocation(tzid string) (*time.Location, bool) {
+	if tzid == "" {
+		return nil, false
+	}
+	loc, err := time.LoadLocation(strings.TrimPrefix(tzid, "/"))
+	return loc, err == nil
+}
+
+// splitICSList splits a comma-separated value, ignoring escaped commas.
+func splitICSList(value string) []string {
+	var parts []string
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\' && i+1 < len(value):
+			b.WriteByte(value[i])
+			b.WriteByte(value[i+1])
+			i++
+		case value[i] == ',':
+			parts = append(parts, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(value[i])
+		}
+	}
+	return append(parts, b.String())
+}
+
+// unescapeICSText decodes TEXT value escapes (\\, \;, \, and \n).
+func unescapeICSText(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// ICSImport records the task created for an imported VTODO.
+type ICSImport struct {
+	TaskID    string `json:"taskId"`
+	ProjectID string `json:"projectId"`
+	// PendingComplete is set while the task of a completed VTODO has not
+	// been completed yet; the next import retries the completion.
+	PendingComplete bool `json:"pendingComplete,omitempty"`
+}
+
+// LoadICSImports returns the map of already imported VTODO UIDs to their
+// tasks.
+func LoadICSImports() (map[string]ICSImport, error) {
+	imports := make(map[string]ICSImport)
+	data, err := os.ReadFile(filepath.Join(configDir(), icsImportsFile))
+	if os.IsNotExist(err) {
+		return imports, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &imports); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", icsImportsFile, err)
+	}
+	return imports, nil
+}
+
+// SaveICSImports persists the map of imported VTODO UIDs to their tasks.
+func SaveICSImports(imports map[string]ICSImport) error {
+	dir := configDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(imports, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(dir, icsImportsFile), data, 0600)
+}
@@ -0,0 +1,271 @@
+package ticktick
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleICS = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Example//Tasks//EN\r\n" +
+	"BEGIN:VTODO\r\n" +
+	"UID:todo-1@example.com\r\n" +
+	"SUMMARY:Write the quarterly report for the finance team and circulate it to \r\n" +
+	" all stakeholders\r\n" +
+	"DESCRIPTION:Include Q3 numbers\\nand\\, if possible\\, a forecast\r\n" +
+	"PRIORITY:1\r\n" +
+	"CATEGORIES:work,finance\r\n" +
+	"DUE;TZID=America/New_York:20260301T170000\r\n" +
+	"RRULE:FREQ=MONTHLY;BYMONTHDAY=1\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"ACTION:DISPLAY\r\n" +
+	"DESCRIPTION:Reminder\r\n" +
+	"END:VALARM\r\n" +
+	"END:VTODO\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:event-1@example.com\r\n" +
+	"SUMMARY:Not a task\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VTODO\r\n" +
+	"UID:todo-2@example.com\r\n" +
+	"SUMMARY:Buy milk\r\n" +
+	"DUE;VALUE=DATE:20260302\r\n" +
+	"STATUS:COMPLETED\r\n" +
+	"CATEGORIES:shopping\r\n" +
+	"CATEGORIES:home\\,garden\r\n" +
+	"END:VTODO\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParseICS_Sample(t *testing.T) {
+	// Act
+	got, err := ParseICS(strings.NewReader(sampleICS))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("ParseICS() returned unexpected error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("ParseICS() returned %d todos, want 2", len(got))
+	}
+
+	first := got[0]
+	if first.UID != "todo-1@example.com" {
+		t.Errorf("UID = %q, want %q", first.UID, "todo-1@example.com")
+	}
+	wantSummary := "Write the quarterly report for the finance team and circulate it to all stakeholders"
+	if first.Summary != wantSummary {
+		t.Errorf("Summary = %q, want %q", first.Summary, wantSummary)
+	}
+	wantDesc := "Include Q3 numbers\nand, if possible, a forecast"
+	if first.Description != wantDesc {
+		t.Errorf("Description = %q, want %q", first.Description, wantDesc)
+	}
+	if first.Priority != 1 {
+		t.Errorf("Priority = %d, want 1", first.Priority)
+	}
+	if !reflect.DeepEqual(first.Categories, []string{"work", "finance"}) {
+		t.Errorf("Categories = %v, want [work finance]", first.Categories)
+	}
+	if first.RRule != "FREQ=MONTHLY;BYMONTHDAY=1" {
+		t.Errorf("RRule = %q, want %q", first.RRule, "FREQ=MONTHLY;BYMONTHDAY=1")
+	}
+	if first.TimeZone != "America/New_York" {
+		t.Errorf("TimeZone = %q, want %q", first.TimeZone, "America/New_York")
+	}
+	wantDue := time.Date(2026, 3, 1, 22, 0, 0, 0, time.UTC)
+	if !first.Due.Equal(wantDue) {
+		t.Errorf("Due = %v, want %v", first.Due, wantDue)
+	}
+	if first.AllDay {
+		t.Error("AllDay = true, want false")
+	}
+	if first.Completed() {
+		t.Error("Completed() = true, want false")
+	}
+
+	second := got[1]
+	if !second.AllDay {
+		t.Error("second AllDay = false, want true")
+	}
+	if !second.Completed() {
+		t.Error("second Completed() = false, want true")
+	}
+	if !reflect.DeepEqual(second.Categories, []string{"shopping", "home,garden"}) {
+		t.Errorf("second Categories = %v, want [shopping home,garden]", second.Categories)
+	}
+}
+
+func TestParseICS_UTCDue(t *testing.T) {
+	// Arrange
+	input := "BEGIN:VTODO\nUID:a\nSUMMARY:UTC\nDUE:20260115T093000Z\nEND:VTODO\n"
+
+	// Act
+	got, err := ParseICS(strings.NewReader(input))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("ParseICS() returned unexpected error: %v", err)
+	}
+	want := time.Date(2026, 1, 15, 9, 30, 0, 0, time.UTC)
+	if len(got) != 1 || !got[0].Due.Equal(want) {
+		t.Fatalf("ParseICS() = %+v, want one todo due %v", got, want)
+	}
+}
+
+func TestParseICS_QuotedParameter(t *testing.T) {
+	// Arrange — quoted TZID containing ':' must not end the parameter list
+	input := "BEGIN:VTODO\nUID:q\nSUMMARY:Quoted\nDUE;TZID=\"Europe/Berlin\";X-NOTE=\"a:b\":20260601T080000\nEND:VTODO\n"
+
+	// Act
+	got, err := ParseICS(strings.NewReader(input))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("ParseICS() returned unexpected error: %v", err)
+	}
+	want := time.Date(2026, 6, 1, 6, 0, 0, 0, time.UTC)
+	if len(got) != 1 || !got[0].Due.Equal(want) {
+		t.Fatalf("ParseICS() = %+v, want one todo due %v", got, want)
+	}
+	if got[0].TimeZone != "Europe/Berlin" {
+		t.Errorf("TimeZone = %q, want %q", got[0].TimeZone, "Europe/Berlin")
+	}
+}
+
+func TestParseICS_TimeZoneIsTheOneUsed(t *testing.T) {
+	tests := []struct {
+		name string
+		due  string
+		want string
+	}{
+		{"known zone", "DUE;TZID=Asia/Tokyo:20260601T080000", "Asia/Tokyo"},
+		{"globally unique prefix", "DUE;TZID=/Europe/Berlin:20260601T080000", "Europe/Berlin"},
+		{"unknown zone falls back to local time", "DUE;TZID=Custom Zone:20260601T080000", ""},
+		{"all-day date", "DUE;VALUE=DATE;TZID=Asia/Tokyo:20260601", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got, err := ParseICS(strings.NewReader("BEGIN:VTODO\nUID:z\nSUMMARY:Zone\n" + tt.due + "\nEND:VTODO\n"))
+
+			// Assert
+			if err != nil {
+				t.Fatalf("ParseICS() returned unexpected error: %v", err)
+			}
+			if got[0].TimeZone != tt.want {
+				t.Errorf("TimeZone = %q, want %q", got[0].TimeZone, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseICS_InvalidInputs(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"invalid priority", "BEGIN:VTODO\nPRIORITY:high\nEND:VTODO\n"},
+		{"invalid due", "BEGIN:VTODO\nDUE:tomorrow\nEND:VTODO\n"},
+		{"missing colon", "BEGIN:VTODO\nSUMMARY\nEND:VTODO\n"},
+		{"unterminated quote", "BEGIN:VTODO\nDUE;TZID=\"Europe/Berlin:20260601T080000\nEND:VTODO\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseICS(strings.NewReader(tt.input))
+			if err == nil {
+				t.Error("ParseICS() expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestICSPriority(t *testing.T) {
+	tests := []struct {
+		name  string
+		input int
+		want  int
+	}{
+		{"undefined", 0, PriorityNone},
+		{"highest", 1, PriorityHigh},
+		{"high boundary", 4, PriorityHigh},
+		{"medium", 5, PriorityMedium},
+		{"low boundary", 6, PriorityLow},
+		{"lowest", 9, PriorityLow},
+		{"out of range", 10, PriorityNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ICSPriority(tt.input); got != tt.want {
+				t.Errorf("ICSPriority(%d) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestICSTodo_CreateRequest(t *testing.T) {
+	// Arrange
+	todo := ICSTodo{
+		Summary:    "Pay rent",
+		Priority:   5,
+		Categories: []string{"home"},
+		Due:        time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local),
+		AllDay:     true,
+		RRule:      "FREQ=MONTHLY",
+	}
+
+	// Act
+	req := todo.CreateRequest("proj-1")
+
+	// Assert
+	if req.Title != "Pay rent" || req.ProjectID != "proj-1" {
+		t.Errorf("CreateRequest() = %+v, want title 'Pay rent' in proj-1", req)
+	}
+	if req.Priority != PriorityMedium {
+		t.Errorf("Priority = %d, want %d", req.Priority, PriorityMedium)
+	}
+	if req.DueDate != expectedEndOfDay(todo.Due) {
+		t.Errorf("DueDate = %q, want %q", req.DueDate, expectedEndOfDay(todo.Due))
+	}
+	if !req.IsAllDay {
+		t.Error("IsAllDay = false, want true")
+	}
+	if req.RepeatFlag != "RRULE:FREQ=MONTHLY" {
+		t.Errorf("RepeatFlag = %q, want %q", req.RepeatFlag, "RRULE:FREQ=MONTHLY")
+	}
+}
+
+func TestSaveAndLoadICSImports_RoundTrip(t *testing.T) {
+	setupTestHome(t)
+
+	// Arrange — nothing imported yet
+	empty, err := LoadICSImports()
+	if err != nil {
+		t.Fatalf("LoadICSImports() returned unexpected error: %v", err)
+	}
+	if len(empty) != 0 {
+		t.Fatalf("LoadICSImports() = %v, want empty map", empty)
+	}
+
+	// Act
+	want := map[string]ICSImport{
+		"todo-1@example.com": {TaskID: "task-1", ProjectID: "proj-1"},
+		"todo-2@example.com": {TaskID: "task-2", ProjectID: "proj-1", PendingComplete: true},
+	}
+	if err := SaveICSImports(want); err != nil {
+		t.Fatalf("SaveICSImports() returned unexpected error: %v", err)
+	}
+	got, err := LoadICSImports()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("LoadICSImports() returned unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadICSImports() = %v, want %v", got, want)
+	}
+}
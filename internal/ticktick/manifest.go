@@ -0,0 +1,214 @@
+package ticktick
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// taskKeyPrefix marks the line in a task's content that stores its manifest key.
+const taskKeyPrefix = "ticky-key: "
+
+// appliedKeysFile records the manifest keys that "tasks apply" has created
+// tasks for.
+const appliedKeysFile = "applied_keys.json"
+
+// Manifest is a list of task specs applied by "tasks apply".
+type Manifest struct {
+	Project string     `yaml:"project,omitempty" json:"project,omitempty"` // default project for specs without one
+	Tasks   []TaskSpec `yaml:"tasks" json:"tasks"`
+}
+
+// TaskSpec describes a task to create from a manifest.
+type TaskSpec struct {
+	Key      string   `yaml:"key,omitempty" json:"key,omitempty"`
+	Title    string   `yaml:"title" json:"title"`
+	Project  string   `yaml:"project,omitempty" json:"project,omitempty"`
+	Priority string   `yaml:"priority,omitempty" json:"priority,omitempty"`
+	Due      string   `yaml:"due,omitempty" json:"due,omitempty"`
+	Tags     []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Content  string   `yaml:"content,omitempty" json:"content,omitempty"`
+	Items    []string `yaml:"items,omitempty" json:"items,omitempty"`
+}
+
+// ParseManifest parses a YAML or JSON manifest. The document is either an
+// object with a "tasks" list or a bare list of task specs. Specs inherit the
+// manifest's default project, are validated, and must have unique keys.
+func ParseManifest(data []byte) (*Manifest, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	return manifestFromNode(&node)
+}
+
+// manifestFromNode decodes and validates a parsed manifest document.
+func manifestFromNode(node *yaml.Node) (*Manifest, error) {
+	var m Manifest
+	if len(node.Content) > 0 && node.Content[0].Kind == yaml.SequenceNode {
+		if err := node.Decode(&m.Tasks); err != nil {
+			return nil, fmt.Errorf("failed to parse manifest: %w", err)
+		}
+	} else if err := node.Decode(&m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	seen := make(map[string]int)
+	for i := range m.Tasks {
+		spec := &m.Tasks[i]
+		if spec.Project == "" {
+			spec.Project = m.Project
+		}
+		if err := spec.validate(); err != nil {
+			return nil, fmt.Errorf("task %d: %w", i+1, err)
+		}
+		key := spec.ResolvedKey()
+		if prev, ok := seen[key]; ok {
+			return nil, fmt.Errorf("task %d: duplicate key %q (also used by task %d)", i+1, key, prev)
+		}
+		seen[key] = i + 1
+	}
+	return &m, nil
+}
+
+func (s TaskSpec) validate() error {
+	if strings.TrimSpace(s.Title) == "" {
+		return fmt.Errorf("title is required")
+	}
+	if s.Priority != "" {
+		if _, err := ParsePriority(s.Priority); err != nil {
+			return err
+		}
+	}
+	if s.Due != "" {
+		if _, err := ParseDate(s.Due); err != nil {
+			return err
+		}
+	}
+	if strings.ContainsAny(s.Key, "\r\n") {
+		return fmt.Errorf("key must be a single line")
+	}
+	return nil
+}
+
+// ResolvedKey returns the spec's idempotency key: the explicit key if set,
+// otherwise a hash of its project and title.
+func (s TaskSpec) ResolvedKey() string {
+	if s.Key != "" {
+		return s.Key
+	}
+	return shortHash(strings.ToLower(s.Project) + "\x00" + s.Title)
+}
+
+// shortHash returns the first 12 hex digits of the SHA-1 of s.
+func shortHash(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// CreateRequest converts the spec into a task creation request for projectID.
+// The idempotency key is stored in the task content.
+func (s TaskSpec) CreateRequest(projectID string) (*TaskCreateRequest, error) {
+	req := &TaskCreateRequest{
+		Title:     s.Title,
+		ProjectID: projectID,
+		Content:   WithTaskKey(s.Content, s.ResolvedKey()),
+		Tags:      s.Tags,
+	}
+	if s.Priority != "" {
+		p, err := ParsePriority(s.Priority)
+		if err != nil {
+			return nil, err
+		}
+		req.Priority = p
+	}
+	if s.Due != "" {
+		due, err := ParseDate(s.Due)
+		if err != nil {
+			return nil, err
+		}
+		req.DueDate = due
+	}
+	for i, item := range s.Items {
+		req.Items = append(req.Items, ChecklistItem{Title: item, SortOrder: int64(i)})
+	}
+	return req, nil
+}
+
+// WithTaskKey appends a key marker line to content.
+func WithTaskKey(content, key string) string {
+	marker := taskKeyPrefix + key
+	if content == "" {
+		return marker
+	}
+	return strings.TrimRight(content, "\n") + "\n\n" + marker
+}
+
+// TaskKey extracts the key stored by WithTaskKey, or "" if content has none.
+func TaskKey(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		if key, ok := strings.CutPrefix(strings.TrimSpace(line), taskKeyPrefix); ok {
+			return key
+		}
+	}
+	return ""
+}
+
+// AppliedKeys maps project IDs to the manifest keys applied in them and the
+// IDs of the tasks created for them. Completed tasks are not returned by the
+// API, so this record is what keeps a re-applied manifest from recreating
+// them.
+type AppliedKeys map[string]map[string]string
+
+// Lookup returns the task created for key in projectID, or "".
+func (a AppliedKeys) Lookup(projectID, key string) string {
+	return a[projectID][key]
+}
+
+// Record stores the task created for key in projectID.
+func (a AppliedKeys) Record(projectID, key, taskID string) {
+	if a[projectID] == nil {
+		a[projectID] = make(map[string]string)
+	}
+	a[projectID][key] = taskID
+}
+
+// Forget removes the record of key in projectID.
+func (a AppliedKeys) Forget(projectID, key string) {
+	delete(a[projectID], key)
+}
+
+// LoadAppliedKeys returns the record of applied manifest keys.
+func LoadAppliedKeys() (AppliedKeys, error) {
+	applied := make(AppliedKeys)
+	data, err := os.ReadFile(filepath.Join(configDir(), appliedKeysFile))
+	if os.IsNotExist(err) {
+		return applied, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &applied); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", appliedKeysFile, err)
+	}
+	return applied, nil
+}
+
+// SaveAppliedKeys persists the record of applied manifest keys.
+func SaveAppliedKeys(applied AppliedKeys) error {
+	dir := configDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(applied, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(dir, appliedKeysFile), data, 0600)
+}
@@ -0,0 +1,188 @@
+package ticktick
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseManifest_YAML(t *testing.T) {
+	// Arrange
+	input := `
+project: Onboarding
+tasks:
+  - key: laptop
+    title: Set up laptop
+    priority: high
+    due: 2026-03-02
+    tags: [it, setup]
+    content: Ask IT for the admin password
+    items:
+      - Install tools
+      - Configure VPN
+  - title: Meet the team
+    project: Team
+`
+
+	// Act
+	got, err := ParseManifest([]byte(input))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("ParseManifest() returned unexpected error: %v", err)
+	}
+	if len(got.Tasks) != 2 {
+		t.Fatalf("ParseManifest() returned %d tasks, want 2", len(got.Tasks))
+	}
+	first := got.Tasks[0]
+	if first.Project != "Onboarding" {
+		t.Errorf("Tasks[0].Project = %q, want inherited %q", first.Project, "Onboarding")
+	}
+	if !reflect.DeepEqual(first.Items, []string{"Install tools", "Configure VPN"}) {
+		t.Errorf("Tasks[0].Items = %v", first.Items)
+	}
+	if got.Tasks[1].Project != "Team" {
+		t.Errorf("Tasks[1].Project = %q, want %q", got.Tasks[1].Project, "Team")
+	}
+}
+
+func TestParseManifest_JSONList(t *testing.T) {
+	// Arrange — a bare JSON list is accepted as well
+	input := `[{"title": "One", "tags": ["a"]}, {"title": "Two", "priority": "low"}]`
+
+	// Act
+	got, err := ParseManifest([]byte(input))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("ParseManifest() returned unexpected error: %v", err)
+	}
+	if len(got.Tasks) != 2 || got.Tasks[1].Priority != "low" {
+		t.Errorf("ParseManifest() = %+v, want 2 tasks with the second at low priority", got)
+	}
+}
+
+func TestParseManifest_InvalidInputs(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"missing title", "tasks:\n  - priority: high\n", "title is required"},
+		{"invalid priority", "tasks:\n  - title: A\n    priority: urgent\n", "invalid priority"},
+		{"invalid due", "tasks:\n  - title: A\n    due: someday\n", "unsupported date format"},
+		{"duplicate explicit key", "tasks:\n  - {title: A, key: k}\n  - {title: B, key: k}\n", "duplicate key"},
+		{"duplicate derived key", "tasks:\n  - {title: A}\n  - {title: A}\n", "duplicate key"},
+		{"malformed yaml", "tasks: [", "failed to parse manifest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseManifest([]byte(tt.input))
+			if err == nil {
+				t.Fatal("ParseManifest() expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %q, want to contain %q", err.Error(), tt.want)
+			}
+		})
+	}
+}
+
+func TestTaskSpec_ResolvedKey(t *testing.T) {
+	explicit := TaskSpec{Key: "laptop", Title: "Set up laptop"}
+	if got := explicit.ResolvedKey(); got != "laptop" {
+		t.Errorf("ResolvedKey() = %q, want %q", got, "laptop")
+	}
+
+	a := TaskSpec{Title: "Set up laptop", Project: "Onboarding"}
+	b := TaskSpec{Title: "Set up laptop", Project: "onboarding"}
+	c := TaskSpec{Title: "Set up laptop", Project: "Other"}
+	if a.ResolvedKey() != b.ResolvedKey() {
+		t.Error("derived key should ignore project name case")
+	}
+	if a.ResolvedKey() == c.ResolvedKey() {
+		t.Error("derived key should differ between projects")
+	}
+}
+
+func TestTaskSpec_CreateRequest(t *testing.T) {
+	// Arrange
+	spec := TaskSpec{
+		Key:      "laptop",
+		Title:    "Set up laptop",
+		Priority: "medium",
+		Content:  "Notes",
+		Items:    []string{"Install tools"},
+	}
+
+	// Act
+	req, err := spec.CreateRequest("proj-1")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("CreateRequest() returned unexpected error: %v", err)
+	}
+	if req.Priority != PriorityMedium || req.ProjectID != "proj-1" {
+		t.Errorf("CreateRequest() = %+v, want medium priority in proj-1", req)
+	}
+	if req.Content != "Notes\n\nticky-key: laptop" {
+		t.Errorf("Content = %q", req.Content)
+	}
+	if TaskKey(req.Content) != "laptop" {
+		t.Errorf("TaskKey(Content) = %q, want %q", TaskKey(req.Content), "laptop")
+	}
+	if len(req.Items) != 1 || req.Items[0].Title != "Install tools" {
+		t.Errorf("Items = %+v", req.Items)
+	}
+}
+
+func TestTaskKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"empty", "", ""},
+		{"no marker", "just notes", ""},
+		{"marker only", WithTaskKey("", "k1"), "k1"},
+		{"marker after notes", WithTaskKey("notes\n", "k2"), "k2"},
+		{"edited around marker", "intro\nticky-key: k3\nmore notes", "k3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TaskKey(tt.content); got != tt.want {
+				t.Errorf("TaskKey(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppliedKeys_SurviveReload(t *testing.T) {
+	setupTestHome(t)
+
+	// Arrange — a task created by an earlier apply, completed since
+	applied, err := LoadAppliedKeys()
+	if err != nil {
+		t.Fatalf("LoadAppliedKeys() returned unexpected error: %v", err)
+	}
+	applied.Record("proj-1", "laptop", "task-1")
+
+	// Act
+	if err := SaveAppliedKeys(applied); err != nil {
+		t.Fatalf("SaveAppliedKeys() returned unexpected error: %v", err)
+	}
+	got, err := LoadAppliedKeys()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("LoadAppliedKeys() returned unexpected error: %v", err)
+	}
+	if id := got.Lookup("proj-1", "laptop"); id != "task-1" {
+		t.Errorf("Lookup(proj-1, laptop) = %q, want task-1", id)
+	}
+	if id := got.Lookup("proj-2", "laptop"); id != "" {
+		t.Errorf("Lookup(proj-2, laptop) = %q, want empty: keys are per project", id)
+	}
+}
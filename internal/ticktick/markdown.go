@@ -0,0 +1,225 @@
+package ticktick
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// MarkdownTask is a task parsed from a Markdown checklist.
+type MarkdownTask struct {
+	Title     string
+	Completed bool
+	Priority  int
+	Due       string // YYYY-MM-DD
+	Tags      []string
+	Items     []MarkdownItem
+}
+
+// MarkdownItem is a checklist entry nested under a MarkdownTask.
+type MarkdownItem struct {
+	Title     string
+	Completed bool
+}
+
+var (
+	markdownCheckboxRe = regexp.MustCompile(`^(\s*)[-*+] \[([ xX])\]\s+(.*)$`)
+	markdownDueRe      = regexp.MustCompile(`(?:^|\s)\(due: (\d{4}-\d{2}-\d{2})\)$`)
+	markdownPriorityRe = regexp.MustCompile(`(?:^|\s)\[(low|medium|high)\]$`)
+
+	// Title text that would be read back as an annotation
+	markdownTagWordRe       = regexp.MustCompile(`(^|\s)#`)
+	markdownDueTokenRe      = regexp.MustCompile(`\(due: (\d{4}-\d{2}-\d{2})\)`)
+	markdownPriorityTokenRe = regexp.MustCompile(`\[(low|medium|high)\]`)
+)
+
+// markdownEscape is the character that makes the next one literal, as in
+// Markdown itself, so escaped titles still render as written.
+const markdownEscape = '\\'
+
+// RenderMarkdown writes projects as "## Name" headings followed by their tasks
+// as "- [ ]" checklist lines. Priority, due date and tags are appended in the
+// same style as the text output of "tasks list", and checklist items are
+// nested underneath their task.
+func RenderMarkdown(w io.Writer, projects []ProjectData) error {
+	bw := bufio.NewWriter(w)
+	for i, pd := range projects {
+		if i > 0 {
+			bw.WriteString("\n")
+		}
+		fmt.Fprintf(bw, "## %s\n\n", pd.Project.Name)
+		if len(pd.Tasks) == 0 {
+			bw.WriteString("_No tasks_\n")
+			continue
+		}
+		for _, t := range pd.Tasks {
+			fmt.Fprintf(bw, "- %s %s\n", markdownCheckbox(t.Status == TaskStatusCompleted), markdownTaskLine(t))
+			for _, item := range t.Items {
+				fmt.Fprintf(bw, "  - %s %s\n", markdownCheckbox(item.Status == ChecklistStatusCompleted), item.Title)
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+func markdownCheckbox(done bool) string {
+	if done {
+		return "[x]"
+	}
+	return "[ ]"
+}
+
+func markdownTaskLine(t Task) string {
+	var b strings.Builder
+	b.WriteString(escapeMarkdownTitle(t.Title))
+	if t.Priority > 0 {
+		fmt.Fprintf(&b, " [%s]", PriorityString(t.Priority))
+	}
+	if due := localDate(t.DueDate); due != "" {
+		fmt.Fprintf(&b, " (due: %s)", due)
+	}
+	for _, tag := range t.Tags {
+		b.WriteString(" #" + escapeMarkdownTag(tag))
+	}
+	return b.String()
+}
+
+// escapeMarkdownTitle escapes the parts of a title that parseMarkdownTaskLine
+// would take for tags, a priority or a due date.
+func escapeMarkdownTitle(title string) string {
+	s := strings.ReplaceAll(title, `\`, `\\`)
+	s = markdownTagWordRe.ReplaceAllString(s, `$1\#`)
+	s = markdownDueTokenRe.ReplaceAllString(s, `\(due: $1)`)
+	return markdownPriorityTokenRe.ReplaceAllString(s, `\[$1]`)
+}
+
+// escapeMarkdownTag escapes spaces in a tag so it stays one word.
+func escapeMarkdownTag(tag string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(tag, `\`, `\\`), " ", `\ `)
+}
+
+// unescapeMarkdown removes the escapes added by escapeMarkdownTitle and
+// escapeMarkdownTag. Other backslashes are kept.
+func unescapeMarkdown(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == markdownEscape && i+1 < len(s) && strings.IndexByte(`\#[( `, s[i+1]) >= 0 {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// markdownWords splits text at whitespace, keeping escaped spaces in their
+// word.
+func markdownWords(text string) []string {
+	var words []string
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == markdownEscape && i+1 < len(text):
+			b.WriteByte(c)
+			b.WriteByte(text[i+1])
+			i++
+		case c == ' ' || c == '\t':
+			if b.Len() > 0 {
+				words = append(words, b.String())
+				b.Reset()
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	if b.Len() > 0 {
+		words = append(words, b.String())
+	}
+	return words
+}
+
+// ParseMarkdownChecklist extracts tasks from the "- [ ]" / "- [x]" lines of a
+// Markdown document. Checkbox lines indented deeper than the preceding task
+// become its checklist items; all other lines are ignored.
+func ParseMarkdownChecklist(r io.Reader) ([]MarkdownTask, error) {
+	var tasks []MarkdownTask
+	taskIndent := -1
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		m := markdownCheckboxRe.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		indent := len(strings.ReplaceAll(m[1], "\t", "    "))
+		done := m[2] != " "
+		text := strings.TrimSpace(m[3])
+
+		if len(tasks) > 0 && indent > taskIndent {
+			last := &tasks[len(tasks)-1]
+			last.Items = append(last.Items, MarkdownItem{Title: text, Completed: done})
+			continue
+		}
+
+		task := parseMarkdownTaskLine(text)
+		task.Completed = done
+		tasks = append(tasks, task)
+		taskIndent = indent
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read markdown: %w", err)
+	}
+	return tasks, nil
+}
+
+// parseMarkdownTaskLine splits trailing "[priority] (due: date) #tags"
+// annotations off a checklist line. Backslash-escaped annotations are part of
+// the title.
+func parseMarkdownTaskLine(text string) MarkdownTask {
+	var task MarkdownTask
+
+	words := markdownWords(text)
+	for len(words) > 1 && strings.HasPrefix(words[len(words)-1], "#") && len(words[len(words)-1]) > 1 {
+		task.Tags = append([]string{unescapeMarkdown(words[len(words)-1][1:])}, task.Tags...)
+		words = words[:len(words)-1]
+	}
+	text = strings.Join(words, " ")
+
+	if m := markdownDueRe.FindStringSubmatch(text); m != nil {
+		task.Due = m[1]
+		text = strings.TrimSuffix(text, m[0])
+	}
+	if m := markdownPriorityRe.FindStringSubmatch(text); m != nil {
+		task.Priority, _ = ParsePriority(m[1])
+		text = strings.TrimSuffix(text, m[0])
+	}
+
+	task.Title = unescapeMarkdown(strings.TrimSpace(text))
+	return task
+}
+
+// CreateRequest converts the task into a task creation request for projectID.
+func (t MarkdownTask) CreateRequest(projectID string) (*TaskCreateRequest, error) {
+	req := &TaskCreateRequest{
+		Title:     t.Title,
+		ProjectID: projectID,
+		Priority:  t.Priority,
+		Tags:      t.Tags,
+	}
+	if t.Due != "" {
+		due, err := ParseDate(t.Due)
+		if err != nil {
+			return nil, err
+		}
+		req.DueDate = due
+	}
+	for i, item := range t.Items {
+		status := ChecklistStatusNormal
+		if item.Completed {
+			status = ChecklistStatusCompleted
+		}
+		req.Items = append(req.Items, ChecklistItem{Title: item.Title, Status: status, SortOrder: int64(i)})
+	}
+	return req, nil
+}
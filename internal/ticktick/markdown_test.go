@@ -0,0 +1,200 @@
+package ticktick
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	// Arrange
+	due := endOfDay(time.Date(2026, 2, 12, 0, 0, 0, 0, time.Local))
+	projects := []ProjectData{
+		{
+			Project: Project{Name: "Work"},
+			Tasks: []Task{
+				{
+					Title:    "Review PR",
+					Priority: PriorityHigh,
+					DueDate:  due,
+					Tags:     []string{"work", "review"},
+					Items: []ChecklistItem{
+						{Title: "Check tests"},
+						{Title: "Read description", Status: ChecklistStatusCompleted},
+					},
+				},
+				{Title: "Ship", Status: TaskStatusCompleted},
+			},
+		},
+		{Project: Project{Name: "Empty"}},
+	}
+	want := "## Work\n\n" +
+		"- [ ] Review PR [high] (due: 2026-02-12) #work #review\n" +
+		"  - [ ] Check tests\n" +
+		"  - [x] Read description\n" +
+		"- [x] Ship\n" +
+		"\n## Empty\n\n" +
+		"_No tasks_\n"
+
+	// Act
+	var buf bytes.Buffer
+	err := RenderMarkdown(&buf, projects)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("RenderMarkdown() returned unexpected error: %v", err)
+	}
+	if buf.String() != want {
+		t.Errorf("RenderMarkdown() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestParseMarkdownChecklist(t *testing.T) {
+	// Arrange
+	input := `# Meeting notes
+
+Some prose that is ignored.
+
+- [ ] Review PR [high] (due: 2026-02-12) #work #review
+  - [ ] Check tests
+  - [x] Read description
+- [X] Send minutes
+* [ ] Follow up on #42 with Sam
+- not a checkbox
+`
+
+	// Act
+	got, err := ParseMarkdownChecklist(strings.NewReader(input))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("ParseMarkdownChecklist() returned unexpected error: %v", err)
+	}
+	want := []MarkdownTask{
+		{
+			Title:    "Review PR",
+			Priority: PriorityHigh,
+			Due:      "2026-02-12",
+			Tags:     []string{"work", "review"},
+			Items: []MarkdownItem{
+				{Title: "Check tests"},
+				{Title: "Read description", Completed: true},
+			},
+		},
+		{Title: "Send minutes", Completed: true},
+		{Title: "Follow up on #42 with Sam"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseMarkdownChecklist() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseMarkdownChecklist_IndentedFirstTask(t *testing.T) {
+	// Arrange — a list nested inside another block keeps its own hierarchy
+	input := "    - [ ] Parent\n        - [ ] Child\n    - [ ] Sibling\n"
+
+	// Act
+	got, err := ParseMarkdownChecklist(strings.NewReader(input))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("ParseMarkdownChecklist() returned unexpected error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("ParseMarkdownChecklist() returned %d tasks, want 2", len(got))
+	}
+	if len(got[0].Items) != 1 || got[0].Items[0].Title != "Child" {
+		t.Errorf("first task items = %+v, want [Child]", got[0].Items)
+	}
+}
+
+func TestMarkdownTask_CreateRequest(t *testing.T) {
+	// Arrange
+	task := MarkdownTask{
+		Title:    "Review PR",
+		Priority: PriorityMedium,
+		Due:      "2026-02-12",
+		Tags:     []string{"work"},
+		Items:    []MarkdownItem{{Title: "a"}, {Title: "b", Completed: true}},
+	}
+
+	// Act
+	req, err := task.CreateRequest("proj-1")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("CreateRequest() returned unexpected error: %v", err)
+	}
+	wantDue := expectedEndOfDay(time.Date(2026, 2, 12, 0, 0, 0, 0, time.Local))
+	if req.DueDate != wantDue {
+		t.Errorf("DueDate = %q, want %q", req.DueDate, wantDue)
+	}
+	wantItems := []ChecklistItem{
+		{Title: "a", Status: ChecklistStatusNormal, SortOrder: 0},
+		{Title: "b", Status: ChecklistStatusCompleted, SortOrder: 1},
+	}
+	if !reflect.DeepEqual(req.Items, wantItems) {
+		t.Errorf("Items = %+v, want %+v", req.Items, wantItems)
+	}
+}
+
+func TestMarkdown_RoundTrip(t *testing.T) {
+	// Arrange
+	line := "- [ ] Review PR [low] (due: 2026-03-01) #a #b"
+
+	// Act
+	parsed, err := ParseMarkdownChecklist(strings.NewReader(line))
+	if err != nil || len(parsed) != 1 {
+		t.Fatalf("ParseMarkdownChecklist() = %v, %v; want one task", parsed, err)
+	}
+	req, err := parsed[0].CreateRequest("p")
+	if err != nil {
+		t.Fatalf("CreateRequest() returned unexpected error: %v", err)
+	}
+	got := "- [ ] " + markdownTaskLine(Task{Title: req.Title, Priority: req.Priority, DueDate: req.DueDate, Tags: req.Tags})
+
+	// Assert
+	if got != line {
+		t.Errorf("round trip = %q, want %q", got, line)
+	}
+}
+
+func TestMarkdown_RoundTripEscapedTitles(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+		tags  []string
+	}{
+		{"trailing hashtag", "Fix issue #42", nil},
+		{"trailing priority", "Triage [high]", nil},
+		{"trailing due date", "Renew (due: 2026-05-01)", nil},
+		{"all annotations", "A [low] (due: 2026-01-01) #x", []string{"y"}},
+		{"backslashes", `Path C:\temp\ and \#`, nil},
+		{"tag with spaces", "Plan", []string{"team x", `a\b`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			task := Task{Title: tt.title, Tags: tt.tags}
+			var buf strings.Builder
+			if err := RenderMarkdown(&buf, []ProjectData{{Project: Project{Name: "P"}, Tasks: []Task{task}}}); err != nil {
+				t.Fatalf("RenderMarkdown() returned unexpected error: %v", err)
+			}
+
+			// Act
+			got, err := ParseMarkdownChecklist(strings.NewReader(buf.String()))
+
+			// Assert
+			if err != nil || len(got) != 1 {
+				t.Fatalf("ParseMarkdownChecklist() = %v, %v; want one task", got, err)
+			}
+			want := MarkdownTask{Title: tt.title, Tags: tt.tags}
+			if !reflect.DeepEqual(got[0], want) {
+				t.Errorf("round trip of %q = %+v, want %+v", buf.String(), got[0], want)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package ticktick
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// DefaultProfile is the profile whose files live directly in
+	// ~/.config/ticky, as they did before profiles existed.
+	DefaultProfile = "default"
+
+	profilesDir       = "profiles"
+	activeProfileFile = "active_profile"
+)
+
+var (
+	profile          = DefaultProfile
+	validProfileName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+)
+
+// SetProfile selects the profile whose token, config and caches are used.
+func SetProfile(name string) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	profile = name
+	return nil
+}
+
+// ActiveProfile returns the name of the selected profile.
+func ActiveProfile() string {
+	return profile
+}
+
+// ValidateProfileName rejects names that are not safe as directory names.
+func ValidateProfileName(name string) error {
+	if !validProfileName.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q: use letters, digits, - and _", name)
+	}
+	return nil
+}
+
+// baseDir returns ~/.config/ticky, which holds the default profile and the
+// files shared by all profiles.
+func baseDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, tokenDir)
+}
+
+// profileDir returns the directory holding the files of a profile.
+func profileDir(name string) string {
+	if name == DefaultProfile {
+		return baseDir()
+	}
+	return filepath.Join(baseDir(), profilesDir, name)
+}
+
+// ProfileDir returns the directory of the active profile.
+func ProfileDir() string {
+	return profileDir(profile)
+}
+
+// LoadDefaultProfile returns the profile chosen with "profile use", or
+// DefaultProfile when none was chosen.
+func LoadDefaultProfile() (string, error) {
+	data, err := os.ReadFile(filepath.Join(baseDir(), activeProfileFile))
+	if os.IsNotExist(err) {
+		return DefaultProfile, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read active profile: %w", err)
+	}
+	name := strings.TrimSpace(string(data))
+	if name == "" {
+		return DefaultProfile, nil
+	}
+	return name, ValidateProfileName(name)
+}
+
+// SaveDefaultProfile makes name the profile used when neither --profile nor
+// TICKY_PROFILE is given, creating its directory.
+func SaveDefaultProfile(name string) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	path := filepath.Join(baseDir(), activeProfileFile)
+	if name == DefaultProfile {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to reset active profile: %w", err)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(profileDir(name), 0700); err != nil {
+		return fmt.Errorf("failed to create profile directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(name+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to save active profile: %w", err)
+	}
+	return nil
+}
+
+// ProfileInfo describes a profile found on disk. LoggedIn is nil when the
+// profile keeps its token with a credential helper, which is not asked, as
+// it may prompt.
+type ProfileInfo struct {
+	Name             string `json:"name"`
+	Dir              string `json:"dir"`
+	LoggedIn         *bool  `json:"logged_in"`
+	CredentialHelper string `json:"credential_helper,omitempty"`
+}
+
+// ListProfiles returns the default profile and every profile directory,
+// sorted by name with the default profile first.
+func ListProfiles() ([]ProfileInfo, error) {
+	names := []string{}
+	entries, err := os.ReadDir(filepath.Join(baseDir(), profilesDir))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+	for _, e := range entries {
+		if e.IsDir() && ValidateProfileName(e.Name()) == nil && e.Name() != DefaultProfile {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	names = append([]string{DefaultProfile}, names...)
+
+	profiles := make([]ProfileInfo, len(names))
+	for i, name := range names {
+		profiles[i] = profileInfo(name)
+	}
+	return profiles, nil
+}
+
+// GetProfile describes the named profile.
+func GetProfile(name string) ProfileInfo {
+	return profileInfo(name)
+}
+
+func profileInfo(name string) ProfileInfo {
+	dir := profileDir(name)
+	info := ProfileInfo{Name: name, Dir: dir, CredentialHelper: profileCredentialHelper(dir)}
+	if info.CredentialHelper == "" {
+		_, err := os.Stat(filepath.Join(dir, tokenFile))
+		loggedIn := err == nil
+		info.LoggedIn = &loggedIn
+	}
+	return info
+}
+
+// profileCredentialHelper returns the credential helper of the profile in
+// dir, from TICKY_CREDENTIAL_HELPER or its config file.
+func profileCredentialHelper(dir string) string {
+	if helper := os.Getenv("TICKY_CREDENTIAL_HELPER"); helper != "" {
+		return helper
+	}
+	data, err := os.ReadFile(filepath.Join(dir, configFile))
+	if err != nil {
+		return ""
+	}
+	var cfg Config
+	if json.Unmarshal(data, &cfg) != nil {
+		return ""
+	}
+	return cfg.CredentialHelper
+}
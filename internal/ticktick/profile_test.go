@@ -0,0 +1,157 @@
+package ticktick_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+)
+
+// useProfile switches to a profile for the duration of the test.
+func useProfile(t *testing.T, name string) {
+	t.Helper()
+	if err := ticktick.SetProfile(name); err != nil {
+		t.Fatalf("SetProfile(%q) returned unexpected error: %v", name, err)
+	}
+	t.Cleanup(func() { ticktick.SetProfile(ticktick.DefaultProfile) })
+}
+
+func TestProfile_IsolatesTokenAndConfig(t *testing.T) {
+	// Arrange
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := ticktick.SaveInboxID("personal-inbox"); err != nil {
+		t.Fatalf("SaveInboxID() returned unexpected error: %v", err)
+	}
+
+	// Act
+	useProfile(t, "work")
+	if err := ticktick.SaveToken(&ticktick.OAuthToken{AccessToken: "work-token"}); err != nil {
+		t.Fatalf("SaveToken() returned unexpected error: %v", err)
+	}
+	_, inboxErr := ticktick.LoadInboxID()
+
+	// Assert
+	wantPath := filepath.Join(home, ".config", "ticky", "profiles", "work", "token.json")
+	if got := ticktick.TokenPath(); got != wantPath {
+		t.Errorf("TokenPath() = %q, want %q", got, wantPath)
+	}
+	if inboxErr == nil {
+		t.Error("LoadInboxID() in work profile found the default profile's inbox")
+	}
+	ticktick.SetProfile(ticktick.DefaultProfile)
+	if _, err := ticktick.LoadToken(); err == nil {
+		t.Error("LoadToken() in default profile found the work profile's token")
+	}
+}
+
+func TestDefaultProfile_RoundTrip(t *testing.T) {
+	// Arrange
+	t.Setenv("HOME", t.TempDir())
+
+	// Act & Assert
+	if got, _ := ticktick.LoadDefaultProfile(); got != ticktick.DefaultProfile {
+		t.Errorf("LoadDefaultProfile() = %q, want %q before any profile use", got, ticktick.DefaultProfile)
+	}
+	if err := ticktick.SaveDefaultProfile("work"); err != nil {
+		t.Fatalf("SaveDefaultProfile() returned unexpected error: %v", err)
+	}
+	if got, _ := ticktick.LoadDefaultProfile(); got != "work" {
+		t.Errorf("LoadDefaultProfile() = %q, want work", got)
+	}
+	if err := ticktick.SaveDefaultProfile(ticktick.DefaultProfile); err != nil {
+		t.Fatalf("SaveDefaultProfile() returned unexpected error: %v", err)
+	}
+	if got, _ := ticktick.LoadDefaultProfile(); got != ticktick.DefaultProfile {
+		t.Errorf("LoadDefaultProfile() = %q after reset, want %q", got, ticktick.DefaultProfile)
+	}
+}
+
+func TestListProfiles(t *testing.T) {
+	// Arrange
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("TICKY_CREDENTIAL_HELPER", "")
+	useProfile(t, "work")
+	ticktick.SaveToken(&ticktick.OAuthToken{AccessToken: "work-token"})
+	useProfile(t, "personal")
+	ticktick.SaveInboxID("inbox")
+	useProfile(t, "vault")
+	ticktick.SetConfigValue("credential_helper", "pass")
+
+	// Act
+	profiles, err := ticktick.ListProfiles()
+
+	// Assert — the helper is not asked, so its login state is unknown
+	if err != nil {
+		t.Fatalf("ListProfiles() returned unexpected error: %v", err)
+	}
+	var got []string
+	for _, p := range profiles {
+		got = append(got, p.Name)
+		switch {
+		case p.Name == "vault":
+			if p.LoggedIn != nil || p.CredentialHelper != "pass" {
+				t.Errorf("profile vault = %+v, want unknown login with helper pass", p)
+			}
+		case p.LoggedIn == nil || *p.LoggedIn != (p.Name == "work"):
+			t.Errorf("profile %s LoggedIn = %v", p.Name, p.LoggedIn)
+		}
+	}
+	if strings.Join(got, ",") != "default,personal,vault,work" {
+		t.Errorf("ListProfiles() names = %v, want [default personal vault work]", got)
+	}
+}
+
+func TestValidateProfileName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"work", false},
+		{"client_a-2", false},
+		{"", true},
+		{"../etc", true},
+		{"-work", true},
+		{"has space", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ticktick.ValidateProfileName(tt.name); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateProfileName(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRefreshAccessToken_ProfileCredentials(t *testing.T) {
+	// Arrange — credentials come from the profile config, not the environment
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("TICKTICK_CLIENT_ID", "")
+	t.Setenv("TICKTICK_CLIENT_SECRET", "")
+	useProfile(t, "work")
+	ticktick.SetConfigValue("client_id", "work-id")
+	ticktick.SetConfigValue("client_secret", "work-secret")
+
+	var gotUser, gotPass string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser, gotPass, _ = r.BasicAuth()
+		w.Write([]byte(`{"access_token":"new"}`))
+	}))
+	defer server.Close()
+	defer ticktick.SetTokenURL(server.URL)()
+
+	// Act
+	_, err := ticktick.RefreshAccessToken("refresh")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("RefreshAccessToken() returned unexpected error: %v", err)
+	}
+	if gotUser != "work-id" || gotPass != "work-secret" {
+		t.Errorf("Basic Auth = %q/%q, want work-id/work-secret", gotUser, gotPass)
+	}
+}
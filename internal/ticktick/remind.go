@@ -0,0 +1,260 @@
+package ticktick
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	remindStateFile = "remind_state.json"
+
+	// reminderCommandTimeout bounds how long a notification command may run.
+	reminderCommandTimeout = time.Minute
+
+	// lateAfter is how late a notification may fire before it is reported as
+	// caught up.
+	lateAfter = time.Minute
+)
+
+// Notification kinds.
+const (
+	NotifyDue      = "due"
+	NotifyReminder = "reminder"
+)
+
+// Notification is a point in time at which a task should be announced.
+type Notification struct {
+	Kind string
+	At   time.Time
+	Task Task
+}
+
+// Key identifies the notification in the remind state. A task whose due date
+// or reminders change gets new keys and is notified again.
+func (n Notification) Key() string {
+	return n.Task.ID + "|" + n.Kind + "|" + strconv.FormatInt(n.At.Unix(), 10)
+}
+
+// Env returns the TICKY_* environment variables describing the notification
+// for the command run at now.
+func (n Notification) Env(now time.Time) []string {
+	due := ""
+	if t, err := parseAPITime(n.Task.DueDate); err == nil {
+		due = t.Local().Format(time.RFC3339)
+	}
+	late := "false"
+	if now.Sub(n.At) > lateAfter {
+		late = "true"
+	}
+	return []string{
+		"TICKY_NOTIFY_KIND=" + n.Kind,
+		"TICKY_NOTIFY_AT=" + n.At.Local().Format(time.RFC3339),
+		"TICKY_NOTIFY_LATE=" + late,
+		"TICKY_TASK_ID=" + n.Task.ID,
+		"TICKY_TASK_PROJECT_ID=" + n.Task.ProjectID,
+		"TICKY_TASK_TITLE=" + n.Task.Title,
+		"TICKY_TASK_CONTENT=" + n.Task.Content,
+		"TICKY_TASK_DUE=" + due,
+		"TICKY_TASK_ALL_DAY=" + strconv.FormatBool(n.Task.IsAllDay),
+		"TICKY_TASK_PRIORITY=" + PriorityString(n.Task.Priority),
+		"TICKY_TASK_TAGS=" + strings.Join(n.Task.Tags, ","),
+	}
+}
+
+// Notifications returns the notifications of open tasks, earliest first.
+// A task with reminders is announced at each reminder; a task with only a
+// due date is announced when it is due, or allDayAt after local midnight of
+// the due day for all-day tasks. Unparsable reminders are skipped.
+func Notifications(tasks []Task, allDayAt time.Duration) []Notification {
+	var notes []Notification
+	for _, t := range tasks {
+		if t.Status == TaskStatusCompleted || t.DueDate == "" {
+			continue
+		}
+		due, err := parseAPITime(t.DueDate)
+		if err != nil {
+			continue
+		}
+
+		if len(t.Reminders) == 0 {
+			at := due
+			if t.IsAllDay {
+				local := due.Local()
+				at = time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.Local).Add(allDayAt)
+			}
+			notes = append(notes, Notification{Kind: NotifyDue, At: at, Task: t})
+			continue
+		}
+		for _, r := range t.Reminders {
+			offset, err := ParseTrigger(r)
+			if err != nil {
+				continue
+			}
+			notes = append(notes, Notification{Kind: NotifyReminder, At: due.Add(offset), Task: t})
+		}
+	}
+	sort.SliceStable(notes, func(i, j int) bool { return notes[i].At.Before(notes[j].At) })
+	return notes
+}
+
+// ParseTrigger parses a TickTick reminder such as "TRIGGER:-PT30M" or
+// "TRIGGER:P0DT9H0M0S" into its offset from the due date. The value is an
+// iCalendar duration of weeks, days, hours, minutes and seconds.
+func ParseTrigger(s string) (time.Duration, error) {
+	v := strings.TrimPrefix(strings.TrimSpace(s), "TRIGGER:")
+	sign := time.Duration(1)
+	if rest, ok := strings.CutPrefix(v, "-"); ok {
+		sign, v = -1, rest
+	} else {
+		v = strings.TrimPrefix(v, "+")
+	}
+	rest, ok := strings.CutPrefix(v, "P")
+	if !ok || rest == "" {
+		return 0, fmt.Errorf("invalid reminder trigger: %s", s)
+	}
+
+	dateUnits := map[rune]time.Duration{'W': 7 * 24 * time.Hour, 'D': 24 * time.Hour}
+	timeUnits := map[rune]time.Duration{'H': time.Hour, 'M': time.Minute, 'S': time.Second}
+	units, inTime := dateUnits, false
+	var d time.Duration
+	num, parts := "", 0
+	for _, r := range rest {
+		switch {
+		case r >= '0' && r <= '9':
+			num += string(r)
+		case r == 'T' && num == "" && !inTime:
+			units, inTime = timeUnits, true
+		default:
+			unit, ok := units[r]
+			if !ok || num == "" {
+				return 0, fmt.Errorf("invalid reminder trigger: %s", s)
+			}
+			n, _ := strconv.Atoi(num)
+			d += time.Duration(n) * unit
+			num = ""
+			parts++
+		}
+	}
+	if num != "" || parts == 0 {
+		return 0, fmt.Errorf("invalid reminder trigger: %s", s)
+	}
+	return sign * d, nil
+}
+
+// RemindStatePath returns the remind state file of the active profile.
+func RemindStatePath() string {
+	return filepath.Join(configDir(), remindStateFile)
+}
+
+// remindState records the notifications that have fired, keyed by
+// Notification.Key, with the time they were due.
+type remindState struct {
+	Fired map[string]time.Time `json:"fired"`
+}
+
+func loadRemindState(path string) (*remindState, error) {
+	s := &remindState{Fired: make(map[string]time.Time)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read remind state: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse remind state %s: %w", path, err)
+	}
+	if s.Fired == nil {
+		s.Fired = make(map[string]time.Time)
+	}
+	return s, nil
+}
+
+func (s *remindState) save(path string) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to encode remind state: %w", err)
+	}
+	if err := writeFileAtomic(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write remind state: %w", err)
+	}
+	return nil
+}
+
+// FireNotifications calls fire for every notification that is due at now,
+// was due at most catchUp ago, and has not fired according to the state
+// file at path. A catchUp below lateAfter counts as lateAfter: the daemon
+// wakes a little after a notification is due, which is not a missed one.
+// Each one is recorded as soon as it has fired, so restarts
+// do not repeat it. The state file is locked meanwhile, so several daemons
+// of one profile do not notify twice. It returns the time of the next
+// pending notification, or the zero time if there is none.
+func FireNotifications(path string, notes []Notification, now time.Time, catchUp time.Duration, fire func(Notification)) (time.Time, error) {
+	unlock, err := lockFile(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer unlock()
+
+	state, err := loadRemindState(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// Entries outside the catch-up window can never fire again
+	oldest := now.Add(-max(catchUp, lateAfter))
+	changed := false
+	for key, at := range state.Fired {
+		if at.Before(oldest) {
+			delete(state.Fired, key)
+			changed = true
+		}
+	}
+
+	var next time.Time
+	for _, n := range notes {
+		if _, done := state.Fired[n.Key()]; done || n.At.Before(oldest) {
+			continue
+		}
+		if n.At.After(now) {
+			if next.IsZero() || n.At.Before(next) {
+				next = n.At
+			}
+			continue
+		}
+		fire(n)
+		state.Fired[n.Key()] = n.At
+		if err := state.save(path); err != nil {
+			return next, err
+		}
+		changed = false
+	}
+	if changed {
+		return next, state.save(path)
+	}
+	return next, nil
+}
+
+// RunReminderCommand runs command with the shell, passing the notification
+// in TICKY_* environment variables. Its output goes to ticky's stdout and
+// stderr.
+func RunReminderCommand(command string, n Notification, now time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), reminderCommandTimeout)
+	defer cancel()
+
+	cmd := shellCommand(ctx, command)
+	cmd.Env = append(os.Environ(), n.Env(now)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("reminder command failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package ticktick_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+)
+
+func TestParseTrigger(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"TRIGGER:PT0S", 0, false},
+		{"TRIGGER:-PT30M", -30 * time.Minute, false},
+		{"TRIGGER:P0DT9H0M0S", 9 * time.Hour, false},
+		{"TRIGGER:-P1DT15H0M0S", -39 * time.Hour, false},
+		{"-P1W", -7 * 24 * time.Hour, false},
+		{"TRIGGER:", 0, true},
+		{"TRIGGER:PT", 0, true},
+		{"TRIGGER:P5", 0, true},
+		{"TRIGGER:PT5D", 0, true},
+		{"TRIGGER:-PT5X", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			// Act
+			got, err := ticktick.ParseTrigger(tt.input)
+
+			// Assert
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTrigger(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseTrigger(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotifications(t *testing.T) {
+	// Arrange
+	orig := time.Local
+	time.Local = time.UTC
+	defer func() { time.Local = orig }()
+	tasks := []ticktick.Task{
+		{ID: "reminders", DueDate: "2026-03-01T12:00:00.000+0000", Reminders: []string{"TRIGGER:PT0S", "TRIGGER:-PT30M", "bogus"}},
+		{ID: "timed", DueDate: "2026-03-01T10:00:00.000+0000"},
+		{ID: "all-day", DueDate: "2026-03-02T00:00:00.000+0000", IsAllDay: true},
+		{ID: "done", DueDate: "2026-03-01T08:00:00.000+0000", Status: ticktick.TaskStatusCompleted},
+		{ID: "no-due"},
+	}
+
+	// Act
+	notes := ticktick.Notifications(tasks, 9*time.Hour)
+
+	// Assert
+	var got []string
+	for _, n := range notes {
+		got = append(got, n.Task.ID+" "+n.Kind+" "+n.At.Format("01-02 15:04"))
+	}
+	want := []string{
+		"timed due 03-01 10:00",
+		"reminders reminder 03-01 11:30",
+		"reminders reminder 03-01 12:00",
+		"all-day due 03-02 09:00",
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Notifications() = %v, want %v", got, want)
+	}
+}
+
+func TestFireNotifications_CatchesUpWithoutRepeating(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "remind_state.json")
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	notes := []ticktick.Notification{
+		{Kind: ticktick.NotifyDue, At: now.Add(-48 * time.Hour), Task: ticktick.Task{ID: "too-old"}},
+		{Kind: ticktick.NotifyDue, At: now.Add(-time.Hour), Task: ticktick.Task{ID: "missed"}},
+		{Kind: ticktick.NotifyReminder, At: now.Add(time.Hour), Task: ticktick.Task{ID: "upcoming"}},
+	}
+	var fired []string
+	fire := func(n ticktick.Notification) { fired = append(fired, n.Task.ID) }
+
+	// Act — the second call reloads the state as after a restart
+	next, err := ticktick.FireNotifications(path, notes, now, 24*time.Hour, fire)
+	if err != nil {
+		t.Fatalf("FireNotifications() returned unexpected error: %v", err)
+	}
+	ticktick.FireNotifications(path, notes, now, 24*time.Hour, fire)
+	ticktick.FireNotifications(path, notes, now.Add(2*time.Hour), 24*time.Hour, fire)
+
+	// Assert
+	if got := strings.Join(fired, ","); got != "missed,upcoming" {
+		t.Errorf("fired = %s, want missed,upcoming", got)
+	}
+	if !next.Equal(now.Add(time.Hour)) {
+		t.Errorf("next = %v, want %v", next, now.Add(time.Hour))
+	}
+}
+
+func TestFireNotifications_NoCatchUpStillFiresOnTime(t *testing.T) {
+	// Arrange — the daemon wakes a moment after the notification is due
+	path := filepath.Join(t.TempDir(), "remind_state.json")
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	notes := []ticktick.Notification{
+		{Kind: ticktick.NotifyDue, At: now.Add(-2 * time.Second), Task: ticktick.Task{ID: "due-now"}},
+		{Kind: ticktick.NotifyDue, At: now.Add(-time.Hour), Task: ticktick.Task{ID: "missed"}},
+	}
+	var fired []string
+
+	// Act
+	_, err := ticktick.FireNotifications(path, notes, now, 0, func(n ticktick.Notification) { fired = append(fired, n.Task.ID) })
+
+	// Assert
+	if err != nil {
+		t.Fatalf("FireNotifications() returned unexpected error: %v", err)
+	}
+	if got := strings.Join(fired, ","); got != "due-now" {
+		t.Errorf("fired = %s, want due-now", got)
+	}
+}
+
+func TestRunReminderCommand_PassesTaskInEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("reminder command test uses a POSIX shell")
+	}
+
+	// Arrange
+	out := filepath.Join(t.TempDir(), "out")
+	n := ticktick.Notification{
+		Kind: ticktick.NotifyReminder,
+		At:   time.Now().Add(-time.Hour),
+		Task: ticktick.Task{ID: "t1", Title: "Call dentist", Priority: ticktick.PriorityHigh, Tags: []string{"health", "phone"}},
+	}
+	command := `printf '%s|%s|%s|%s|%s' "$TICKY_TASK_TITLE" "$TICKY_NOTIFY_KIND" "$TICKY_NOTIFY_LATE" "$TICKY_TASK_PRIORITY" "$TICKY_TASK_TAGS" > ` + out
+
+	// Act
+	err := ticktick.RunReminderCommand(command, n, time.Now())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("RunReminderCommand() returned unexpected error: %v", err)
+	}
+	data, _ := os.ReadFile(out)
+	if want := "Call dentist|reminder|true|high|health,phone"; string(data) != want {
+		t.Errorf("command saw %q, want %q", data, want)
+	}
+	if err := ticktick.RunReminderCommand("exit 3", n, time.Now()); err == nil {
+		t.Error("RunReminderCommand() with a failing command returned nil error")
+	}
+}
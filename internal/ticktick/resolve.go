@@ -0,0 +1,181 @@
+package ticktick
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const projectCacheFile = "projects_cache.json"
+
+// ProjectCacheTTL is how long the cached project list is used to resolve
+// project names before it is fetched again.
+var ProjectCacheTTL = 15 * time.Minute
+
+// ErrProjectNotFound is returned when a project reference matches nothing.
+var ErrProjectNotFound = errors.New("project not found")
+
+// AmbiguousProjectError is returned when a project reference matches more
+// than one project.
+type AmbiguousProjectError struct {
+	Ref        string
+	Candidates []Project
+}
+
+func (e *AmbiguousProjectError) Error() string {
+	names := make([]string, len(e.Candidates))
+	for i, p := range e.Candidates {
+		names[i] = fmt.Sprintf("%s (%s)", p.Name, p.ID)
+	}
+	return fmt.Sprintf("project %q is ambiguous; candidates: %s", e.Ref, strings.Join(names, ", "))
+}
+
+type projectCache struct {
+	FetchedAt time.Time `json:"fetched_at"`
+	Projects  []Project `json:"projects"`
+}
+
+// ResolveProject resolves a project reference to a project ID. The reference
+// may be a project ID, "inbox", or a project name matched by MatchProject.
+// Names are looked up in a cached project list, which is refreshed when it
+// is older than ProjectCacheTTL or does not contain the name. An empty
+// reference resolves to "".
+func (c *Client) ResolveProject(ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	switch {
+	case ref == "":
+		return "", nil
+	case strings.EqualFold(ref, "inbox"):
+		return c.DiscoverInboxID()
+	case looksLikeProjectID(ref):
+		return ref, nil
+	}
+
+	projects, fresh, err := c.cachedProjects()
+	if err != nil {
+		return "", err
+	}
+	p, err := MatchProject(projects, ref)
+	if errors.Is(err, ErrProjectNotFound) && !fresh {
+		// The project may have been created since the cache was written
+		if projects, err = c.refreshProjectCache(); err != nil {
+			return "", err
+		}
+		p, err = MatchProject(projects, ref)
+	}
+	if err != nil {
+		return "", err
+	}
+	return p.ID, nil
+}
+
+// MatchProject finds the project referred to by ref. Matching tries, in
+// order: the exact ID, the case-insensitive name, a unique name prefix, and
+// a unique fuzzy match where the characters of ref appear in order in the
+// name. Several matches at the same stage are reported as an
+// *AmbiguousProjectError.
+func MatchProject(projects []Project, ref string) (*Project, error) {
+	for i := range projects {
+		if projects[i].ID == ref {
+			return &projects[i], nil
+		}
+	}
+
+	needle := strings.ToLower(strings.TrimSpace(ref))
+	matchers := []func(name string) bool{
+		func(name string) bool { return name == needle },
+		func(name string) bool { return strings.HasPrefix(name, needle) },
+		func(name string) bool { return isSubsequence(needle, name) },
+	}
+	for _, match := range matchers {
+		var found []Project
+		for _, p := range projects {
+			if match(strings.ToLower(p.Name)) {
+				found = append(found, p)
+			}
+		}
+		switch len(found) {
+		case 0:
+			continue
+		case 1:
+			return &found[0], nil
+		default:
+			return nil, &AmbiguousProjectError{Ref: ref, Candidates: found}
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrProjectNotFound, ref)
+}
+
+// isSubsequence reports whether the runes of sub appear in s in order.
+func isSubsequence(sub, s string) bool {
+	r := []rune(sub)
+	if len(r) == 0 {
+		return false
+	}
+	for _, c := range s {
+		if c == r[0] {
+			r = r[1:]
+			if len(r) == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// looksLikeProjectID reports whether ref has the shape of a TickTick project
+// ID: 24 hex digits, or "inbox" followed by digits.
+func looksLikeProjectID(ref string) bool {
+	digits, hex := ref, "0123456789abcdef"
+	if rest, ok := strings.CutPrefix(ref, "inbox"); ok && rest != "" {
+		digits, hex = rest, "0123456789"
+	} else if len(ref) != 24 {
+		return false
+	}
+	for _, c := range digits {
+		if !strings.ContainsRune(hex, c) {
+			return false
+		}
+	}
+	return true
+}
+
+// cachedProjects returns the cached project list, fetching it when missing
+// or expired. fresh reports whether the list was just fetched.
+func (c *Client) cachedProjects() (projects []Project, fresh bool, err error) {
+	data, err := os.ReadFile(filepath.Join(configDir(), projectCacheFile))
+	if err == nil {
+		var cache projectCache
+		if json.Unmarshal(data, &cache) == nil && time.Since(cache.FetchedAt) < ProjectCacheTTL {
+			return cache.Projects, false, nil
+		}
+	}
+	projects, err = c.refreshProjectCache()
+	return projects, true, err
+}
+
+// refreshProjectCache fetches the project list and caches it. Failing to
+// write the cache is not an error.
+func (c *Client) refreshProjectCache() ([]Project, error) {
+	projects, err := c.GetProjects()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get projects: %w", err)
+	}
+
+	dir := configDir()
+	if err := os.MkdirAll(dir, 0700); err == nil {
+		if data, err := json.Marshal(projectCache{FetchedAt: time.Now(), Projects: projects}); err == nil {
+			_ = writeFileAtomic(filepath.Join(dir, projectCacheFile), data, 0600)
+		}
+	}
+	return projects, nil
+}
+
+// invalidateProjectCache removes the cached project list.
+func invalidateProjectCache() {
+	_ = os.Remove(filepath.Join(configDir(), projectCacheFile))
+}
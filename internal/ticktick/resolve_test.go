@@ -0,0 +1,147 @@
+package ticktick_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+)
+
+var resolveProjects = []ticktick.Project{
+	{ID: "aaaaaaaaaaaaaaaaaaaaaaaa", Name: "Work"},
+	{ID: "bbbbbbbbbbbbbbbbbbbbbbbb", Name: "Workout"},
+	{ID: "cccccccccccccccccccccccc", Name: "Personal Errands"},
+	{ID: "dddddddddddddddddddddddd", Name: "Reading List"},
+}
+
+func TestMatchProject(t *testing.T) {
+	tests := []struct {
+		name   string
+		ref    string
+		wantID string
+	}{
+		{"exact id", "cccccccccccccccccccccccc", "cccccccccccccccccccccccc"},
+		{"exact name wins over prefix", "work", "aaaaaaaaaaaaaaaaaaaaaaaa"},
+		{"case-insensitive name", "READING LIST", "dddddddddddddddddddddddd"},
+		{"unique prefix", "pers", "cccccccccccccccccccccccc"},
+		{"unique prefix longer than shared", "worko", "bbbbbbbbbbbbbbbbbbbbbbbb"},
+		{"fuzzy", "rdlst", "dddddddddddddddddddddddd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ticktick.MatchProject(resolveProjects, tt.ref)
+			if err != nil {
+				t.Fatalf("MatchProject(%q) returned unexpected error: %v", tt.ref, err)
+			}
+			if got.ID != tt.wantID {
+				t.Errorf("MatchProject(%q) = %s, want %s", tt.ref, got.ID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestMatchProject_Ambiguous(t *testing.T) {
+	// Act
+	_, err := ticktick.MatchProject(resolveProjects, "wo")
+
+	// Assert
+	var amb *ticktick.AmbiguousProjectError
+	if !errors.As(err, &amb) {
+		t.Fatalf("MatchProject() error = %v, want *AmbiguousProjectError", err)
+	}
+	if len(amb.Candidates) != 2 {
+		t.Errorf("Candidates = %+v, want Work and Workout", amb.Candidates)
+	}
+	for _, want := range []string{"Work (aaaaaaaaaaaaaaaaaaaaaaaa)", "Workout (bbbbbbbbbbbbbbbbbbbbbbbb)"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error = %q, want to list %q", err.Error(), want)
+		}
+	}
+}
+
+func TestMatchProject_NotFound(t *testing.T) {
+	_, err := ticktick.MatchProject(resolveProjects, "groceries")
+	if !errors.Is(err, ticktick.ErrProjectNotFound) {
+		t.Errorf("MatchProject() error = %v, want ErrProjectNotFound", err)
+	}
+}
+
+func TestResolveProject(t *testing.T) {
+	// Arrange
+	t.Setenv("HOME", t.TempDir())
+	if err := ticktick.SaveInboxID("inbox123"); err != nil {
+		t.Fatalf("SaveInboxID() returned unexpected error: %v", err)
+	}
+	calls := 0
+	client, cleanup := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/project" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		calls++
+		json.NewEncoder(w).Encode(resolveProjects)
+	})
+	defer cleanup()
+
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{"", ""},
+		{"Inbox", "inbox123"},
+		{"inbox118979798", "inbox118979798"},
+		{"0123456789abcdef01234567", "0123456789abcdef01234567"},
+		{"work", "aaaaaaaaaaaaaaaaaaaaaaaa"},
+		{"pers", "cccccccccccccccccccccccc"},
+	}
+
+	// Act & Assert
+	for _, tt := range tests {
+		got, err := client.ResolveProject(tt.ref)
+		if err != nil {
+			t.Fatalf("ResolveProject(%q) returned unexpected error: %v", tt.ref, err)
+		}
+		if got != tt.want {
+			t.Errorf("ResolveProject(%q) = %q, want %q", tt.ref, got, tt.want)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("GetProjects called %d times, want 1 (cached afterwards)", calls)
+	}
+}
+
+func TestResolveProject_RefreshesStaleCacheOnMiss(t *testing.T) {
+	// Arrange — the first listing lacks the project, the second has it
+	t.Setenv("HOME", t.TempDir())
+	listings := [][]ticktick.Project{
+		resolveProjects,
+		append(resolveProjects, ticktick.Project{ID: "eeeeeeeeeeeeeeeeeeeeeeee", Name: "Garden"}),
+	}
+	calls := 0
+	client, cleanup := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(listings[min(calls, 1)])
+		calls++
+	})
+	defer cleanup()
+	if _, err := client.ResolveProject("work"); err != nil {
+		t.Fatalf("ResolveProject(work) returned unexpected error: %v", err)
+	}
+
+	// Act
+	got, err := client.ResolveProject("garden")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("ResolveProject(garden) returned unexpected error: %v", err)
+	}
+	if got != "eeeeeeeeeeeeeeeeeeeeeeee" || calls != 2 {
+		t.Errorf("ResolveProject(garden) = %q after %d calls, want new project after 2", got, calls)
+	}
+
+	if _, err := client.ResolveProject("nothing-like-this"); !errors.Is(err, ticktick.ErrProjectNotFound) {
+		t.Errorf("ResolveProject(unknown) error = %v, want ErrProjectNotFound", err)
+	}
+}
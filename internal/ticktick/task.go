@@ -0,0 +1,50 @@
+package ticktick
+
+import "fmt"
+
+// UpdateRequest returns an update request that keeps every editable field
+// of the task as it is. Callers modify the fields they want to change.
+func (t *Task) UpdateRequest() *TaskUpdateRequest {
+	req := &TaskUpdateRequest{
+		ID:        t.ID,
+		ProjectID: t.ProjectID,
+		Title:     t.Title,
+		Content:   t.Content,
+		Tags:      t.Tags,
+	}
+
+	p := t.Priority
+	req.Priority = &p
+
+	if t.DueDate != "" {
+		d := t.DueDate
+		req.DueDate = &d
+	}
+	return req
+}
+
+// MoveTask moves a task to another project. The open API cannot change a
+// task's project, so the task is recreated in the target project and the
+// original is deleted. If the deletion fails, the new task is returned along
+// with the error. A move is neither a new task nor a deletion, so no hooks
+// run for it.
+func (c *Client) MoveTask(t *Task, toProjectID string) (*Task, error) {
+	if t.ProjectID == toProjectID {
+		return t, nil
+	}
+
+	moved, err := c.createTask(restoreRequest(*t, toProjectID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create task in target project: %w", err)
+	}
+	if err := c.deleteTask(t.ProjectID, t.ID); err != nil {
+		return moved, fmt.Errorf("task copied as %s but failed to delete original: %w", moved.ID, err)
+	}
+	return moved, nil
+}
+
+// DueDay returns the task's due date as a local YYYY-MM-DD string, or "" if
+// the task has no due date.
+func (t *Task) DueDay() string {
+	return localDate(t.DueDate)
+}
@@ -0,0 +1,119 @@
+package ticktick_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+)
+
+func TestTask_UpdateRequest(t *testing.T) {
+	// Arrange
+	task := &ticktick.Task{
+		ID:        "task-1",
+		ProjectID: "proj-1",
+		Title:     "Write docs",
+		Content:   "Details",
+		Priority:  ticktick.PriorityHigh,
+		DueDate:   "2026-03-02T14:59:59.000+0000",
+		Tags:      []string{"docs"},
+	}
+
+	// Act
+	req := task.UpdateRequest()
+
+	// Assert
+	if req.ID != "task-1" || req.ProjectID != "proj-1" || req.Title != "Write docs" || req.Content != "Details" {
+		t.Errorf("UpdateRequest() = %+v, want identity and text fields copied", req)
+	}
+	if req.Priority == nil || *req.Priority != ticktick.PriorityHigh {
+		t.Errorf("Priority = %v, want high", req.Priority)
+	}
+	if req.DueDate == nil || *req.DueDate != task.DueDate {
+		t.Errorf("DueDate = %v, want %q", req.DueDate, task.DueDate)
+	}
+
+	undated := (&ticktick.Task{ID: "task-2"}).UpdateRequest()
+	if undated.DueDate != nil {
+		t.Errorf("DueDate = %q, want nil for a task without due date", *undated.DueDate)
+	}
+}
+
+func TestTask_DueDay(t *testing.T) {
+	due, _ := ticktick.ParseDate("2026-03-02")
+	if got := (&ticktick.Task{DueDate: due}).DueDay(); got != "2026-03-02" {
+		t.Errorf("DueDay() = %q, want %q", got, "2026-03-02")
+	}
+	if got := (&ticktick.Task{}).DueDay(); got != "" {
+		t.Errorf("DueDay() = %q, want empty for a task without due date", got)
+	}
+}
+
+func TestMoveTask_Success(t *testing.T) {
+	// Arrange
+	var created ticktick.TaskCreateRequest
+	var deleted string
+	client, cleanup := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/task":
+			json.NewDecoder(r.Body).Decode(&created)
+			json.NewEncoder(w).Encode(ticktick.Task{ID: "task-new", ProjectID: created.ProjectID, Title: created.Title})
+		case r.Method == http.MethodDelete:
+			deleted = r.URL.Path
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+	defer cleanup()
+	task := &ticktick.Task{
+		ID:        "task-1",
+		ProjectID: "proj-1",
+		Title:     "Move me",
+		Items:     []ticktick.ChecklistItem{{ID: "item-1", Title: "Step"}},
+	}
+
+	// Act
+	got, err := client.MoveTask(task, "proj-2")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("MoveTask() returned unexpected error: %v", err)
+	}
+	if got.ID != "task-new" || got.ProjectID != "proj-2" {
+		t.Errorf("MoveTask() = %+v, want task-new in proj-2", got)
+	}
+	if created.ProjectID != "proj-2" || created.Title != "Move me" {
+		t.Errorf("created = %+v, want copy in proj-2", created)
+	}
+	if len(created.Items) != 1 || created.Items[0].ID != "" {
+		t.Errorf("created.Items = %+v, want items without IDs", created.Items)
+	}
+	if deleted != "/project/proj-1/task/task-1" {
+		t.Errorf("deleted = %q, want original task", deleted)
+	}
+}
+
+func TestMoveTask_DeleteFails(t *testing.T) {
+	// Arrange
+	client, cleanup := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(ticktick.Task{ID: "task-new", ProjectID: "proj-2"})
+	})
+	defer cleanup()
+
+	// Act
+	got, err := client.MoveTask(&ticktick.Task{ID: "task-1", ProjectID: "proj-1"}, "proj-2")
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "failed to delete original") {
+		t.Errorf("MoveTask() error = %v, want delete failure", err)
+	}
+	if got == nil || got.ID != "task-new" {
+		t.Errorf("MoveTask() = %+v, want the copied task", got)
+	}
+}
@@ -0,0 +1,183 @@
+package ticktick
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const taskIndexFile = "task_index.json"
+
+// ErrTaskNotFound is returned when a task cannot be found in any project.
+var ErrTaskNotFound = errors.New("task not found")
+
+// taskIndex maps task IDs to the IDs of their projects so tasks can be found
+// without their project. It is filled by every call that returns tasks and
+// persisted in the config directory; tasks missing from a project's task
+// list are dropped again, which keeps it to the open tasks plus those seen
+// since the last listing. A nil index records nothing.
+type taskIndex struct {
+	mu      sync.Mutex
+	entries map[string]string
+}
+
+func newTaskIndex() *taskIndex {
+	return &taskIndex{}
+}
+
+// load reads the index file once. The caller must hold x.mu.
+func (x *taskIndex) load() {
+	if x.entries != nil {
+		return
+	}
+	x.entries = make(map[string]string)
+	if data, err := os.ReadFile(filepath.Join(configDir(), taskIndexFile)); err == nil {
+		_ = json.Unmarshal(data, &x.entries)
+	}
+}
+
+// save writes the index file. The caller must hold x.mu. Failing to write
+// the index is not an error: it only makes later lookups slower.
+func (x *taskIndex) save() {
+	dir := configDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return
+	}
+	if data, err := json.Marshal(x.entries); err == nil {
+		_ = writeFileAtomic(filepath.Join(dir, taskIndexFile), data, 0600)
+	}
+}
+
+func (x *taskIndex) lookup(taskID string) (string, bool) {
+	if x == nil {
+		return "", false
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.load()
+	projectID, ok := x.entries[taskID]
+	return projectID, ok
+}
+
+func (x *taskIndex) ids() []string {
+	if x == nil {
+		return nil
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.load()
+	ids := make([]string, 0, len(x.entries))
+	for id := range x.entries {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func (x *taskIndex) record(tasks ...Task) {
+	if x == nil {
+		return
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.load()
+	changed := false
+	for _, t := range tasks {
+		if t.ID != "" && t.ProjectID != "" && x.entries[t.ID] != t.ProjectID {
+			x.entries[t.ID] = t.ProjectID
+			changed = true
+		}
+	}
+	if changed {
+		x.save()
+	}
+}
+
+// sync records the full task list of a project, dropping the tasks that are
+// no longer in it.
+func (x *taskIndex) sync(projectID string, tasks []Task) {
+	if x == nil {
+		return
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.load()
+	seen := make(map[string]bool, len(tasks))
+	changed := false
+	for _, t := range tasks {
+		seen[t.ID] = true
+		if t.ID != "" && t.ProjectID != "" && x.entries[t.ID] != t.ProjectID {
+			x.entries[t.ID] = t.ProjectID
+			changed = true
+		}
+	}
+	for id, pid := range x.entries {
+		if pid == projectID && !seen[id] {
+			delete(x.entries, id)
+			changed = true
+		}
+	}
+	if changed {
+		x.save()
+	}
+}
+
+func (x *taskIndex) forget(taskID string) {
+	if x == nil {
+		return
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.load()
+	if _, ok := x.entries[taskID]; ok {
+		delete(x.entries, taskID)
+		x.save()
+	}
+}
+
+// FindTaskProject returns the ID of the project containing a task. The local
+// task index is consulted first and its entry checked, as in LocateTask;
+// otherwise every project is scanned, which also refreshes the index.
+// Completed tasks are only found via the index, until the next listing of
+// their project drops them.
+func (c *Client) FindTaskProject(taskID string) (string, error) {
+	task, err := c.LocateTask(taskID)
+	if err != nil {
+		return "", err
+	}
+	return task.ProjectID, nil
+}
+
+// LocateTask fetches a task by ID alone. A stale index entry is dropped and
+// the projects are scanned instead.
+func (c *Client) LocateTask(taskID string) (*Task, error) {
+	if projectID, ok := c.index.lookup(taskID); ok {
+		if task, err := c.GetTask(projectID, taskID); err == nil {
+			return task, nil
+		}
+		c.index.forget(taskID)
+	}
+	return c.scanForTask(taskID)
+}
+
+// scanForTask searches the open tasks of every project for taskID.
+func (c *Client) scanForTask(taskID string) (*Task, error) {
+	projectIDs, err := c.GetAllProjectIDs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+	for _, id := range projectIDs {
+		pd, err := c.GetProjectData(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tasks: %w", err)
+		}
+		for i := range pd.Tasks {
+			if pd.Tasks[i].ID == taskID {
+				return &pd.Tasks[i], nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: %s is not an open task in any project (pass --project for completed tasks)", ErrTaskNotFound, taskID)
+}
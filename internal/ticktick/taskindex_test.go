@@ -0,0 +1,193 @@
+package ticktick_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+)
+
+// taskIndexServer serves two projects with one task each and counts requests by path.
+func taskIndexServer(t *testing.T) (*ticktick.Client, map[string]int, func()) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	if err := ticktick.SaveInboxID("inbox1"); err != nil {
+		t.Fatalf("SaveInboxID() returned unexpected error: %v", err)
+	}
+
+	calls := make(map[string]int)
+	client, cleanup := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls[r.Method+" "+r.URL.Path]++
+		switch r.URL.Path {
+		case "/project":
+			json.NewEncoder(w).Encode([]ticktick.Project{{ID: "proj-1", Name: "Work"}})
+		case "/project/inbox1/data":
+			json.NewEncoder(w).Encode(ticktick.ProjectData{Tasks: []ticktick.Task{{ID: "task-a", ProjectID: "inbox1"}}})
+		case "/project/proj-1/data":
+			json.NewEncoder(w).Encode(ticktick.ProjectData{Tasks: []ticktick.Task{{ID: "task-b", ProjectID: "proj-1"}}})
+		case "/project/proj-1/task/task-b":
+			if r.Method == http.MethodDelete {
+				return
+			}
+			json.NewEncoder(w).Encode(ticktick.Task{ID: "task-b", ProjectID: "proj-1", Title: "B"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	ticktick.EnableTaskIndex(client)
+	return client, calls, cleanup
+}
+
+func TestFindTaskProject_ScansThenUsesIndex(t *testing.T) {
+	// Arrange
+	client, calls, cleanup := taskIndexServer(t)
+	defer cleanup()
+
+	// Act
+	first, err := client.FindTaskProject("task-b")
+	if err != nil {
+		t.Fatalf("FindTaskProject() returned unexpected error: %v", err)
+	}
+	scans := calls["GET /project/proj-1/data"]
+	second, err := client.FindTaskProject("task-b")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("FindTaskProject() returned unexpected error: %v", err)
+	}
+	if first != "proj-1" || second != "proj-1" {
+		t.Errorf("FindTaskProject() = %q, %q, want proj-1", first, second)
+	}
+	if calls["GET /project/proj-1/data"] != scans {
+		t.Error("second lookup scanned projects again, want index hit")
+	}
+}
+
+func TestFindTaskProject_IndexPersistsAcrossClients(t *testing.T) {
+	// Arrange — listing a project fills the index
+	client, calls, cleanup := taskIndexServer(t)
+	defer cleanup()
+	if _, err := client.GetProjectData("proj-1"); err != nil {
+		t.Fatalf("GetProjectData() returned unexpected error: %v", err)
+	}
+	other := ticktick.NewTestClient(http.DefaultClient, "test-token")
+	ticktick.EnableTaskIndex(other)
+	scans := calls["GET /project/proj-1/data"]
+
+	// Act
+	got, err := other.FindTaskProject("task-b")
+
+	// Assert — the entry is checked with one request instead of a scan
+	if err != nil {
+		t.Fatalf("FindTaskProject() returned unexpected error: %v", err)
+	}
+	if got != "proj-1" || calls["GET /project/proj-1/data"] != scans || calls["GET /project/proj-1/task/task-b"] != 1 {
+		t.Errorf("FindTaskProject() = %q with requests %v, want proj-1 from the index", got, calls)
+	}
+}
+
+func TestFindTaskProject_DropsStaleEntry(t *testing.T) {
+	// Arrange — task-a was moved to the inbox since it was indexed in proj-1
+	client, _, cleanup := taskIndexServer(t)
+	defer cleanup()
+	path := filepath.Join(os.Getenv("HOME"), ".config", "ticky", "task_index.json")
+	if err := os.WriteFile(path, []byte(`{"task-a":"proj-1"}`), 0600); err != nil {
+		t.Fatalf("failed to write index: %v", err)
+	}
+
+	// Act
+	got, err := client.FindTaskProject("task-a")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("FindTaskProject() returned unexpected error: %v", err)
+	}
+	if got != "inbox1" {
+		t.Errorf("FindTaskProject() = %q, want inbox1", got)
+	}
+}
+
+func TestLocateTask_DropsStaleEntry(t *testing.T) {
+	// Arrange — the index points task-a at the wrong project
+	client, _, cleanup := taskIndexServer(t)
+	defer cleanup()
+	path := filepath.Join(os.Getenv("HOME"), ".config", "ticky", "task_index.json")
+	if err := os.WriteFile(path, []byte(`{"task-a":"proj-1"}`), 0600); err != nil {
+		t.Fatalf("failed to write index: %v", err)
+	}
+
+	// Act
+	got, err := client.LocateTask("task-a")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("LocateTask() returned unexpected error: %v", err)
+	}
+	if got.ProjectID != "inbox1" {
+		t.Errorf("LocateTask().ProjectID = %q, want inbox1", got.ProjectID)
+	}
+}
+
+func TestFindTaskProject_NotFound(t *testing.T) {
+	// Arrange
+	client, _, cleanup := taskIndexServer(t)
+	defer cleanup()
+
+	// Act
+	_, err := client.FindTaskProject("missing")
+
+	// Assert
+	if !errors.Is(err, ticktick.ErrTaskNotFound) {
+		t.Errorf("FindTaskProject() error = %v, want ErrTaskNotFound", err)
+	}
+	if !strings.Contains(err.Error(), "--project") {
+		t.Errorf("error = %q, want hint about --project", err.Error())
+	}
+}
+
+func TestDeleteTask_ForgetsIndexEntry(t *testing.T) {
+	// Arrange
+	client, calls, cleanup := taskIndexServer(t)
+	defer cleanup()
+	client.GetProjectData("proj-1")
+
+	// Act
+	if err := client.DeleteTask("proj-1", "task-b"); err != nil {
+		t.Fatalf("DeleteTask() returned unexpected error: %v", err)
+	}
+	client.FindTaskProject("task-b")
+
+	// Assert — the lookup had to scan again
+	if calls["GET /project/inbox1/data"] == 0 {
+		t.Error("lookup after delete used the index, want a scan")
+	}
+}
+
+func TestGetProjectData_PrunesIndex(t *testing.T) {
+	// Arrange — task-old has left proj-1; task-x belongs to another project
+	client, _, cleanup := taskIndexServer(t)
+	defer cleanup()
+	path := filepath.Join(os.Getenv("HOME"), ".config", "ticky", "task_index.json")
+	if err := os.WriteFile(path, []byte(`{"task-old":"proj-1","task-x":"inbox1"}`), 0600); err != nil {
+		t.Fatalf("failed to write index: %v", err)
+	}
+
+	// Act
+	if _, err := client.GetProjectData("proj-1"); err != nil {
+		t.Fatalf("GetProjectData() returned unexpected error: %v", err)
+	}
+
+	// Assert
+	data, _ := os.ReadFile(path)
+	var got map[string]string
+	json.Unmarshal(data, &got)
+	want := map[string]string{"task-b": "proj-1", "task-x": "inbox1"}
+	if len(got) != len(want) || got["task-b"] != "proj-1" || got["task-x"] != "inbox1" {
+		t.Errorf("index = %v, want %v", got, want)
+	}
+}
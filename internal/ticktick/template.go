@@ -0,0 +1,271 @@
+package ticktick
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// templateExts are the file extensions recognised in the templates directory,
+// in lookup order.
+var templateExts = []string{".yaml", ".yml", ".json"}
+
+// TemplatesDir returns the directory holding task templates.
+func TemplatesDir() string {
+	return filepath.Join(baseDir(), "templates")
+}
+
+// ListTemplates returns the names of all saved templates, sorted.
+func ListTemplates() ([]string, error) {
+	entries, err := os.ReadDir(TemplatesDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		ext := filepath.Ext(e.Name())
+		for _, te := range templateExts {
+			if ext == te {
+				names = append(names, strings.TrimSuffix(e.Name(), ext))
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// TemplatePath returns the path of an existing template, or the path a new
+// .yaml template would be saved to.
+func TemplatePath(name string) (string, error) {
+	if err := validateTemplateName(name); err != nil {
+		return "", err
+	}
+	for _, ext := range templateExts {
+		path := filepath.Join(TemplatesDir(), name+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return filepath.Join(TemplatesDir(), name+".yaml"), nil
+}
+
+// LoadTemplate reads the source of the named template.
+func LoadTemplate(name string) (string, error) {
+	path, err := TemplatePath(name)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("template %q not found in %s", name, TemplatesDir())
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// SaveTemplate writes a template source as <name>.yaml, replacing any
+// existing template with that name.
+func SaveTemplate(name string, src []byte) error {
+	path, err := TemplatePath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(TemplatesDir(), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, src, 0600)
+}
+
+func validateTemplateName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid template name: %q", name)
+	}
+	return nil
+}
+
+var (
+	// templateActionRe matches a {{...}} action, skipping "}}" inside its
+	// string literals.
+	templateActionRe = regexp.MustCompile(`\{\{(?:"(?:[^"\\\n]|\\.)*"|` + "`[^`]*`" + `|[^"` + "`" + `}]|\}[^}])*\}\}`)
+
+	templatePlaceholderRe = regexp.MustCompile(`__ticky_action_(\d+)__`)
+)
+
+// RenderTemplate expands a template source with text/template and parses the
+// result as a manifest. Variables are available as {{.name}}; referencing an
+// undefined variable is an error. {{due "+3d"}} resolves a due expression to
+// a local YYYY-MM-DD date.
+//
+// The YAML is parsed before the actions are expanded, each within the value
+// it appears in, so variable values are always plain text: "#" or ": " in a
+// value cannot turn into YAML syntax, and "true" or "42" stay strings.
+// Actions therefore cannot span several values.
+func RenderTemplate(src string, vars map[string]string) (*Manifest, error) {
+	funcs := template.FuncMap{
+		"due": func(expr string) (string, error) {
+			d, err := ParseDate(expr)
+			if err != nil {
+				return "", err
+			}
+			return localDate(d), nil
+		},
+	}
+	if _, err := template.New("template").Funcs(funcs).Parse(src); err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	if vars == nil {
+		vars = map[string]string{}
+	}
+
+	// Hide the actions from the YAML parser; a value such as {{due "+3d"}}
+	// would otherwise read as a flow mapping
+	var actions []string
+	masked := templateActionRe.ReplaceAllStringFunc(src, func(action string) string {
+		actions = append(actions, action)
+		return fmt.Sprintf("__ticky_action_%d__", len(actions)-1)
+	})
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(masked), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	var render func(n *yaml.Node) error
+	render = func(n *yaml.Node) error {
+		for _, child := range n.Content {
+			if err := render(child); err != nil {
+				return err
+			}
+		}
+		if n.Kind != yaml.ScalarNode || !templatePlaceholderRe.MatchString(n.Value) {
+			return nil
+		}
+		value := templatePlaceholderRe.ReplaceAllStringFunc(n.Value, func(ph string) string {
+			i, _ := strconv.Atoi(templatePlaceholderRe.FindStringSubmatch(ph)[1])
+			return actions[i]
+		})
+		tmpl, err := template.New("template").Option("missingkey=error").Funcs(funcs).Parse(value)
+		if err != nil {
+			return fmt.Errorf("failed to parse template: line %d: %w", n.Line, err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, vars); err != nil {
+			return fmt.Errorf("failed to render template: line %d: %w", n.Line, err)
+		}
+		n.Value, n.Tag = buf.String(), "!!str"
+		return nil
+	}
+	if err := render(&doc); err != nil {
+		return nil, err
+	}
+	return manifestFromNode(&doc)
+}
+
+// ScopeTemplateKeys gives the specs without an explicit key a key derived from
+// the template name and vars as well as their project and title. Templates
+// saved from a project have literal titles, so without this every apply after
+// the first would find the keys already applied, whatever the variables.
+func ScopeTemplateKeys(m *Manifest, name string, vars map[string]string) {
+	names := make([]string, 0, len(vars))
+	for k := range vars {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	scope := name
+	for _, k := range names {
+		scope += "\x00" + k + "=" + vars[k]
+	}
+	for i := range m.Tasks {
+		if m.Tasks[i].Key == "" {
+			m.Tasks[i].Key = shortHash(scope + "\x00" + m.Tasks[i].ResolvedKey())
+		}
+	}
+}
+
+// TemplateFromProject builds a template source from a project's open tasks.
+// Due dates are stored relative to now ("today", "+Nd"), or as absolute dates
+// when they are already past, and manifest keys are dropped from contents.
+// Text that looks like a template action is escaped, so applying the template
+// reproduces it literally.
+func TemplateFromProject(pd *ProjectData, now time.Time) ([]byte, error) {
+	m := Manifest{Project: escapeTemplateText(pd.Project.Name)}
+	today := now.Format("2006-01-02")
+	for _, t := range pd.Tasks {
+		spec := TaskSpec{
+			Title:   escapeTemplateText(t.Title),
+			Content: escapeTemplateText(stripTaskKey(t.Content)),
+		}
+		for _, tag := range t.Tags {
+			spec.Tags = append(spec.Tags, escapeTemplateText(tag))
+		}
+		if t.Priority != PriorityNone {
+			spec.Priority = PriorityString(t.Priority)
+		}
+		if due := localDate(t.DueDate); due != "" {
+			spec.Due = relativeDue(today, due)
+		}
+		for _, item := range t.Items {
+			spec.Items = append(spec.Items, escapeTemplateText(item.Title))
+		}
+		m.Tasks = append(m.Tasks, spec)
+	}
+
+	data, err := yaml.Marshal(&m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode template: %w", err)
+	}
+	return data, nil
+}
+
+// escapeTemplateText makes s render as itself. Only "{{" starts an action;
+// a lone "}}" is already plain text.
+func escapeTemplateText(s string) string {
+	return strings.ReplaceAll(s, "{{", `{{"{{"}}`)
+}
+
+// relativeDue expresses the date due relative to today, both YYYY-MM-DD.
+func relativeDue(today, due string) string {
+	t, err1 := time.Parse("2006-01-02", today)
+	d, err2 := time.Parse("2006-01-02", due)
+	if err1 != nil || err2 != nil {
+		return due
+	}
+	days := int(d.Sub(t).Hours() / 24)
+	switch {
+	case days < 0:
+		return due
+	case days == 0:
+		return "today"
+	default:
+		return fmt.Sprintf("+%dd", days)
+	}
+}
+
+// stripTaskKey removes the key marker written by WithTaskKey.
+func stripTaskKey(content string) string {
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), taskKeyPrefix) {
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
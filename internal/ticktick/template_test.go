@@ -0,0 +1,260 @@
+package ticktick
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSaveAndListTemplates(t *testing.T) {
+	setupTestHome(t)
+
+	// Arrange
+	src := "tasks:\n  - title: Release {{.version}}\n"
+
+	// Act
+	if err := SaveTemplate("release", []byte(src)); err != nil {
+		t.Fatalf("SaveTemplate() returned unexpected error: %v", err)
+	}
+	if err := SaveTemplate("onboarding", []byte(src)); err != nil {
+		t.Fatalf("SaveTemplate() returned unexpected error: %v", err)
+	}
+	names, err := ListTemplates()
+	if err != nil {
+		t.Fatalf("ListTemplates() returned unexpected error: %v", err)
+	}
+	got, err := LoadTemplate("release")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("LoadTemplate() returned unexpected error: %v", err)
+	}
+	if got != src {
+		t.Errorf("LoadTemplate() = %q, want %q", got, src)
+	}
+	if !reflect.DeepEqual(names, []string{"onboarding", "release"}) {
+		t.Errorf("ListTemplates() = %v, want [onboarding release]", names)
+	}
+}
+
+func TestLoadTemplate_Errors(t *testing.T) {
+	setupTestHome(t)
+
+	if _, err := LoadTemplate("missing"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("LoadTemplate(missing) error = %v, want not found", err)
+	}
+	if _, err := LoadTemplate("../token"); err == nil || !strings.Contains(err.Error(), "invalid template name") {
+		t.Errorf("LoadTemplate(../token) error = %v, want invalid template name", err)
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	// Arrange
+	src := `project: Releases
+tasks:
+  - key: "release-{{.version}}"
+    title: "Release {{.version}}"
+    due: {{due "+3d"}}
+`
+	wantDue := time.Now().AddDate(0, 0, 3).Format("2006-01-02")
+
+	// Act
+	m, err := RenderTemplate(src, map[string]string{"version": "1.4.0"})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("RenderTemplate() returned unexpected error: %v", err)
+	}
+	spec := m.Tasks[0]
+	if spec.Title != "Release 1.4.0" || spec.Key != "release-1.4.0" {
+		t.Errorf("spec = %+v, want title and key with version 1.4.0", spec)
+	}
+	if spec.Due != wantDue {
+		t.Errorf("spec.Due = %q, want %q", spec.Due, wantDue)
+	}
+	if spec.Project != "Releases" {
+		t.Errorf("spec.Project = %q, want %q", spec.Project, "Releases")
+	}
+}
+
+func TestRenderTemplate_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"missing variable", "tasks:\n  - title: Release {{.version}}\n", "failed to render template"},
+		{"invalid due expression", "tasks:\n  - title: A\n    due: {{due \"someday\"}}\n", "failed to render template"},
+		{"template syntax", "tasks:\n  - title: {{.version\n", "failed to parse template"},
+		{"action spanning values", "tasks:\n  - title: \"{{if true}}A\"\n  - title: \"B{{end}}\"\n", "failed to parse template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := RenderTemplate(tt.src, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("RenderTemplate() error = %v, want to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateFromProject(t *testing.T) {
+	// Arrange
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.Local)
+	pd := &ProjectData{
+		Project: Project{ID: "proj-1", Name: "Releases"},
+		Tasks: []Task{
+			{
+				Title:    "Tag release",
+				Priority: PriorityHigh,
+				DueDate:  endOfDay(now.AddDate(0, 0, 2)),
+				Tags:     []string{"release"},
+				Content:  "Push the tag\n\nticky-key: tag",
+				Items:    []ChecklistItem{{Title: "Update changelog"}},
+			},
+			{Title: "Announce", DueDate: endOfDay(now)},
+			{Title: "Old", DueDate: endOfDay(now.AddDate(0, 0, -1))},
+		},
+	}
+
+	// Act
+	data, err := TemplateFromProject(pd, now)
+	if err != nil {
+		t.Fatalf("TemplateFromProject() returned unexpected error: %v", err)
+	}
+	m, err := ParseManifest(data)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("ParseManifest(template) returned unexpected error: %v\n%s", err, data)
+	}
+	want := []TaskSpec{
+		{Title: "Tag release", Project: "Releases", Priority: "high", Due: "+2d", Tags: []string{"release"}, Content: "Push the tag", Items: []string{"Update changelog"}},
+		{Title: "Announce", Project: "Releases", Due: "today"},
+		{Title: "Old", Project: "Releases", Due: "2026-03-09"},
+	}
+	if !reflect.DeepEqual(m.Tasks, want) {
+		t.Errorf("TemplateFromProject() tasks = %+v, want %+v", m.Tasks, want)
+	}
+}
+
+func TestRenderTemplate_VariablesStayText(t *testing.T) {
+	// Arrange — quoted and unquoted uses of the same variable
+	src := `tasks:
+  - title: Release {{.version}}
+    items:
+      - "{{.version}}"
+      - {{.version}}
+`
+	values := []string{"fix #42", "v2: beta", "true", "42", "1.10", "- [x]", `say "hi"`, "{{.other}}"}
+
+	for _, v := range values {
+		t.Run(v, func(t *testing.T) {
+			// Act
+			m, err := RenderTemplate(src, map[string]string{"version": v})
+
+			// Assert
+			if err != nil {
+				t.Fatalf("RenderTemplate() returned unexpected error: %v", err)
+			}
+			spec := m.Tasks[0]
+			if spec.Title != "Release "+v {
+				t.Errorf("spec.Title = %q, want %q", spec.Title, "Release "+v)
+			}
+			if !reflect.DeepEqual(spec.Items, []string{v, v}) {
+				t.Errorf("spec.Items = %q, want [%q %q]", spec.Items, v, v)
+			}
+		})
+	}
+}
+
+func TestTemplateFromProject_RoundTripsTemplateSyntax(t *testing.T) {
+	// Arrange
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.Local)
+	pd := &ProjectData{
+		Project: Project{ID: "proj-1", Name: "Docs {{team}}"},
+		Tasks: []Task{
+			{
+				Title:   "Use {{.x}} in docs",
+				Content: "Literal }} and {{ braces",
+				Tags:    []string{"{{tag}}"},
+				Items:   []ChecklistItem{{Title: `{{"quoted"}}`}},
+			},
+		},
+	}
+
+	// Act — save, then apply without variables
+	data, err := TemplateFromProject(pd, now)
+	if err != nil {
+		t.Fatalf("TemplateFromProject() returned unexpected error: %v", err)
+	}
+	m, err := RenderTemplate(string(data), nil)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("RenderTemplate(saved) returned unexpected error: %v\n%s", err, data)
+	}
+	want := TaskSpec{
+		Title:   "Use {{.x}} in docs",
+		Project: "Docs {{team}}",
+		Content: "Literal }} and {{ braces",
+		Tags:    []string{"{{tag}}"},
+		Items:   []string{`{{"quoted"}}`},
+	}
+	if !reflect.DeepEqual(m.Tasks[0], want) {
+		t.Errorf("applied task = %+v, want %+v", m.Tasks[0], want)
+	}
+}
+
+func TestScopeTemplateKeys_ReapplyWithOtherVars(t *testing.T) {
+	setupTestHome(t)
+
+	// Arrange — a template saved from a project has literal titles
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.Local)
+	pd := &ProjectData{
+		Project: Project{ID: "proj-1", Name: "Releases"},
+		Tasks:   []Task{{Title: "Tag release"}, {Title: "Announce"}},
+	}
+	data, err := TemplateFromProject(pd, now)
+	if err != nil {
+		t.Fatalf("TemplateFromProject() returned unexpected error: %v", err)
+	}
+	if err := SaveTemplate("release", data); err != nil {
+		t.Fatalf("SaveTemplate() returned unexpected error: %v", err)
+	}
+	apply := func(vars map[string]string) []string {
+		src, err := LoadTemplate("release")
+		if err != nil {
+			t.Fatalf("LoadTemplate() returned unexpected error: %v", err)
+		}
+		m, err := RenderTemplate(src, vars)
+		if err != nil {
+			t.Fatalf("RenderTemplate() returned unexpected error: %v", err)
+		}
+		ScopeTemplateKeys(m, "release", vars)
+		keys := make([]string, len(m.Tasks))
+		for i, spec := range m.Tasks {
+			keys[i] = spec.ResolvedKey()
+		}
+		return keys
+	}
+
+	// Act — apply for 1.0 and record its keys, as tasks apply does
+	first := apply(map[string]string{"version": "1.0"})
+	applied := make(AppliedKeys)
+	for _, key := range first {
+		applied.Record("proj-1", key, "task-"+key)
+	}
+	again := apply(map[string]string{"version": "1.0"})
+	next := apply(map[string]string{"version": "1.1"})
+
+	// Assert
+	if !reflect.DeepEqual(again, first) {
+		t.Errorf("keys with the same vars = %v, want %v", again, first)
+	}
+	if id := applied.Lookup("proj-1", next[0]); id != "" {
+		t.Errorf("derived key for version 1.1 was already applied as %s, want a new key", id)
+	}
+}
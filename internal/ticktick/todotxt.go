@@ -0,0 +1,239 @@
+package ticktick
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// TodoTxtItem is a single task parsed from a todo.txt file.
+type TodoTxtItem struct {
+	Completed      bool
+	CompletionDate string
+	Priority       int
+	Title          string
+	Project        string
+	Tags           []string
+	Due            string
+}
+
+var (
+	todoTxtPriorityRe = regexp.MustCompile(`^\(([A-Z])\) `)
+	todoTxtDateRe     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	todoTxtKeyValueRe = regexp.MustCompile(`^[A-Za-z][\w-]*:[^\s:]+$`)
+)
+
+// todoTxtEscape is the prefix that marks a title word as literal text. It
+// lets titles contain words that todo.txt would read as syntax, such as
+// "+1" or "due:soon".
+const todoTxtEscape = `\`
+
+// todoTxtSpace stands for a space inside @tag tokens, which cannot contain
+// whitespace.
+const todoTxtSpace = `\_`
+
+// TodoTxtPriority maps a todo.txt priority letter to a TickTick priority.
+// (A) is high, (B) medium and (C) low; lower letters are treated as low.
+func TodoTxtPriority(letter byte) int {
+	switch {
+	case letter == 'A':
+		return PriorityHigh
+	case letter == 'B':
+		return PriorityMedium
+	case letter >= 'C' && letter <= 'Z':
+		return PriorityLow
+	default:
+		return PriorityNone
+	}
+}
+
+// TodoTxtPriorityLetter maps a TickTick priority to a todo.txt priority letter,
+// returning 0 when the task has no priority.
+func TodoTxtPriorityLetter(p int) byte {
+	switch p {
+	case PriorityHigh:
+		return 'A'
+	case PriorityMedium:
+		return 'B'
+	case PriorityLow:
+		return 'C'
+	default:
+		return 0
+	}
+}
+
+// TodoTxtProjectName converts a project name to a todo.txt +project token
+// (without the leading '+'), replacing whitespace with underscores.
+func TodoTxtProjectName(name string) string {
+	return strings.Join(strings.Fields(name), "_")
+}
+
+// ParseTodoTxt parses all non-empty lines of a todo.txt stream.
+func ParseTodoTxt(r io.Reader) ([]TodoTxtItem, error) {
+	var items []TodoTxtItem
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		item, err := ParseTodoTxtLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		items = append(items, item)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read todo.txt: %w", err)
+	}
+	return items, nil
+}
+
+// ParseTodoTxtLine parses a single todo.txt line.
+func ParseTodoTxtLine(line string) (TodoTxtItem, error) {
+	var item TodoTxtItem
+	rest := strings.TrimSpace(line)
+
+	if strings.HasPrefix(rest, "x ") {
+		item.Completed = true
+		rest = strings.TrimSpace(rest[2:])
+		if date, tail, ok := cutTodoTxtDate(rest); ok {
+			item.CompletionDate = date
+			rest = tail
+		}
+	}
+
+	if m := todoTxtPriorityRe.FindStringSubmatch(rest); m != nil {
+		item.Priority = TodoTxtPriority(m[1][0])
+		rest = strings.TrimSpace(rest[len(m[0]):])
+	}
+
+	// Creation date is not representable in TickTick and is discarded.
+	if _, tail, ok := cutTodoTxtDate(rest); ok {
+		rest = tail
+	}
+
+	var words []string
+	for _, word := range strings.Fields(rest) {
+		switch {
+		case strings.HasPrefix(word, todoTxtEscape):
+			words = append(words, word[len(todoTxtEscape):])
+		case len(word) > 1 && word[0] == '+':
+			if item.Project == "" {
+				item.Project = word[1:]
+			}
+		case len(word) > 1 && word[0] == '@':
+			item.Tags = append(item.Tags, strings.ReplaceAll(word[1:], todoTxtSpace, " "))
+		case strings.HasPrefix(word, "due:"):
+			due := strings.TrimPrefix(word, "due:")
+			if !todoTxtDateRe.MatchString(due) {
+				return item, fmt.Errorf("invalid due date: %s", due)
+			}
+			item.Due = due
+		case strings.HasPrefix(word, "pri:") && len(word) == len("pri:A"):
+			item.Priority = TodoTxtPriority(word[4])
+		default:
+			words = append(words, word)
+		}
+	}
+
+	item.Title = strings.Join(words, " ")
+	if item.Title == "" {
+		return item, fmt.Errorf("empty task description")
+	}
+	return item, nil
+}
+
+// cutTodoTxtDate strips a leading YYYY-MM-DD date from s.
+func cutTodoTxtDate(s string) (string, string, bool) {
+	head, tail, _ := strings.Cut(s, " ")
+	if !todoTxtDateRe.MatchString(head) {
+		return "", s, false
+	}
+	if _, err := time.Parse("2006-01-02", head); err != nil {
+		return "", s, false
+	}
+	return head, strings.TrimSpace(tail), true
+}
+
+// CreateRequest converts the item into a task creation request for projectID.
+func (item TodoTxtItem) CreateRequest(projectID string) (*TaskCreateRequest, error) {
+	req := &TaskCreateRequest{
+		Title:     item.Title,
+		ProjectID: projectID,
+		Priority:  item.Priority,
+		Tags:      item.Tags,
+	}
+	if item.Due != "" {
+		due, err := ParseDate(item.Due)
+		if err != nil {
+			return nil, err
+		}
+		req.DueDate = due
+	}
+	return req, nil
+}
+
+// FormatTodoTxt renders a task as a todo.txt line. projectName is emitted as
+// a +project token when non-empty. Completed tasks carry their priority as a
+// pri: key, since todo.txt reserves the leading "(A)" for open tasks. Title
+// words that todo.txt would read as syntax are escaped with a backslash and
+// spaces in tags are written as "\_", so ParseTodoTxtLine gives back the same
+// task.
+func FormatTodoTxt(task Task, projectName string) string {
+	var parts []string
+	letter := TodoTxtPriorityLetter(task.Priority)
+
+	if task.Status == TaskStatusCompleted {
+		parts = append(parts, "x")
+		if date := localDate(task.CompletedAt); date != "" {
+			parts = append(parts, date)
+		}
+	} else if letter != 0 {
+		parts = append(parts, "("+string(letter)+")")
+	}
+
+	for i, word := range strings.Fields(task.Title) {
+		if isTodoTxtSyntax(word, i == 0) {
+			word = todoTxtEscape + word
+		}
+		parts = append(parts, word)
+	}
+
+	if name := TodoTxtProjectName(projectName); name != "" {
+		parts = append(parts, "+"+name)
+	}
+	for _, tag := range task.Tags {
+		parts = append(parts, "@"+strings.ReplaceAll(tag, " ", todoTxtSpace))
+	}
+	if due := localDate(task.DueDate); due != "" {
+		parts = append(parts, "due:"+due)
+	}
+	if task.Status == TaskStatusCompleted && letter != 0 {
+		parts = append(parts, "pri:"+string(letter))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// isTodoTxtSyntax reports whether a title word would not be read back as
+// text: a +project, @context or key:value token, an escaped word, or, as the
+// first word, a completion mark, priority or date.
+func isTodoTxtSyntax(word string, first bool) bool {
+	switch {
+	case strings.HasPrefix(word, todoTxtEscape):
+		return true
+	case len(word) > 1 && (word[0] == '+' || word[0] == '@'):
+		return true
+	case todoTxtKeyValueRe.MatchString(word) && !strings.Contains(word, "://"):
+		return true
+	case first:
+		return word == "x" || todoTxtPriorityRe.MatchString(word+" ") || todoTxtDateRe.MatchString(word)
+	}
+	return false
+}
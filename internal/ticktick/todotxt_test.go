@@ -0,0 +1,225 @@
+package ticktick
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTodoTxtLine_ValidInputs(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  TodoTxtItem
+	}{
+		{
+			"plain description",
+			"Call mom",
+			TodoTxtItem{Title: "Call mom"},
+		},
+		{
+			"priority project and context",
+			"(A) Review PR +Work @office @urgent",
+			TodoTxtItem{Priority: PriorityHigh, Title: "Review PR", Project: "Work", Tags: []string{"office", "urgent"}},
+		},
+		{
+			"creation date is dropped",
+			"(B) 2026-01-01 Plan sprint due:2026-01-10",
+			TodoTxtItem{Priority: PriorityMedium, Title: "Plan sprint", Due: "2026-01-10"},
+		},
+		{
+			"completed with dates and pri key",
+			"x 2026-01-05 2026-01-01 Ship release +My_Project pri:C",
+			TodoTxtItem{Completed: true, CompletionDate: "2026-01-05", Priority: PriorityLow, Title: "Ship release", Project: "My_Project"},
+		},
+		{
+			"lower priority letter maps to low",
+			"(D) Someday",
+			TodoTxtItem{Priority: PriorityLow, Title: "Someday"},
+		},
+		{
+			"priority not at start is text",
+			"Fix (A) bug",
+			TodoTxtItem{Title: "Fix (A) bug"},
+		},
+		{
+			"only first project is used",
+			"Sync +One +Two",
+			TodoTxtItem{Title: "Sync", Project: "One"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTodoTxtLine(tt.input)
+			if err != nil {
+				t.Fatalf("ParseTodoTxtLine(%q) returned unexpected error: %v", tt.input, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTodoTxtLine(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTodoTxtLine_InvalidInputs(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"only metadata", "(A) +Work @home"},
+		{"invalid due date", "Task due:tomorrow"},
+		{"completed without description", "x 2026-01-05"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := ParseTodoTxtLine(tt.input); err == nil {
+				t.Errorf("ParseTodoTxtLine(%q) = %+v, want error", tt.input, got)
+			}
+		})
+	}
+}
+
+func TestParseTodoTxt_SkipsBlankLines(t *testing.T) {
+	// Arrange
+	input := "(A) First\n\n   \nx Second\n"
+
+	// Act
+	got, err := ParseTodoTxt(strings.NewReader(input))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("ParseTodoTxt() returned unexpected error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("ParseTodoTxt() returned %d items, want 2", len(got))
+	}
+	if !got[1].Completed {
+		t.Error("second item Completed = false, want true")
+	}
+}
+
+func TestParseTodoTxt_ReportsLineNumber(t *testing.T) {
+	// Act
+	_, err := ParseTodoTxt(strings.NewReader("ok\n\nbad due:soon\n"))
+
+	// Assert
+	if err == nil {
+		t.Fatal("ParseTodoTxt() expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("error = %q, want to contain 'line 3'", err.Error())
+	}
+}
+
+func TestFormatTodoTxt(t *testing.T) {
+	due := endOfDay(time.Date(2026, 2, 12, 0, 0, 0, 0, time.Local))
+	completed := formatAPITime(time.Date(2026, 2, 10, 12, 0, 0, 0, time.Local))
+
+	tests := []struct {
+		name    string
+		task    Task
+		project string
+		want    string
+	}{
+		{
+			"open task with all fields",
+			Task{Title: "Review PR", Priority: PriorityHigh, Tags: []string{"work"}, DueDate: due},
+			"My Project",
+			"(A) Review PR +My_Project @work due:2026-02-12",
+		},
+		{
+			"no priority no project",
+			Task{Title: "Buy milk"},
+			"",
+			"Buy milk",
+		},
+		{
+			"completed task",
+			Task{Title: "Ship", Priority: PriorityMedium, Status: TaskStatusCompleted, CompletedAt: completed},
+			"Inbox",
+			"x 2026-02-10 Ship +Inbox pri:B",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatTodoTxt(tt.task, tt.project); got != tt.want {
+				t.Errorf("FormatTodoTxt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTodoTxt_RoundTrip(t *testing.T) {
+	// Arrange
+	lines := []string{
+		"(A) Review PR +Work @office due:2026-02-12",
+		"(C) Water plants +Home",
+		"x 2026-02-10 Ship release +Work @release pri:B",
+		"Call mom",
+	}
+
+	for _, line := range lines {
+		t.Run(line, func(t *testing.T) {
+			// Act — parse, convert to a task as TickTick would store it, format again
+			item, err := ParseTodoTxtLine(line)
+			if err != nil {
+				t.Fatalf("ParseTodoTxtLine(%q) returned unexpected error: %v", line, err)
+			}
+			req, err := item.CreateRequest("proj-1")
+			if err != nil {
+				t.Fatalf("CreateRequest() returned unexpected error: %v", err)
+			}
+			task := Task{Title: req.Title, Priority: req.Priority, Tags: req.Tags, DueDate: req.DueDate}
+			if item.Completed {
+				task.Status = TaskStatusCompleted
+				completion, _ := time.ParseInLocation("2006-01-02", item.CompletionDate, time.Local)
+				task.CompletedAt = formatAPITime(completion)
+			}
+			got := FormatTodoTxt(task, item.Project)
+
+			// Assert
+			if got != line {
+				t.Errorf("round trip = %q, want %q", got, line)
+			}
+		})
+	}
+}
+
+func TestTodoTxt_RoundTripsTitlesWithSyntax(t *testing.T) {
+	tests := []struct {
+		title string
+		tags  []string
+	}{
+		{"x marks the spot", nil},
+		{"ping @bob about +1", nil},
+		{"(A) foo", nil},
+		{"2024-01-01 retro", nil},
+		{"fix due:soon parser", nil},
+		{`C:\temp and \n`, nil},
+		{"read https://example.com/a", []string{"deep work"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			// Arrange
+			task := Task{Title: tt.title, Tags: tt.tags}
+
+			// Act
+			line := FormatTodoTxt(task, "Work")
+			item, err := ParseTodoTxtLine(line)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("ParseTodoTxtLine(%q) returned unexpected error: %v", line, err)
+			}
+			want := TodoTxtItem{Title: tt.title, Project: "Work", Tags: tt.tags}
+			if !reflect.DeepEqual(item, want) {
+				t.Errorf("ParseTodoTxtLine(%q) = %+v, want %+v", line, item, want)
+			}
+		})
+	}
+}
@@ -5,19 +5,29 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 const tokenDir = ".config/ticky"
 const tokenFile = "token.json"
 
-// TokenPath returns the full path to the token file.
+// TokenPath returns the full path to the token file of the active profile.
 func TokenPath() string {
-	home, _ := os.UserHomeDir()
-	return filepath.Join(home, tokenDir, tokenFile)
+	return filepath.Join(configDir(), tokenFile)
 }
 
-// SaveToken writes the token to disk with 0600 permissions.
+// SaveToken writes the token to disk with 0600 permissions, replacing the
+// file atomically. The token is encrypted when encrypt_token is set or the
+// existing file is encrypted.
+// When a credential helper is configured, the token is handed to it instead.
 func SaveToken(token *OAuthToken) error {
+	if helper := CredentialHelper(); helper != "" {
+		if err := helperStoreToken(helper, token); err != nil {
+			return fmt.Errorf("failed to store token: %w", err)
+		}
+		return nil
+	}
+
 	path := TokenPath()
 	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
 		return fmt.Errorf("failed to create token directory: %w", err)
@@ -28,21 +38,42 @@ func SaveToken(token *OAuthToken) error {
 		return fmt.Errorf("failed to marshal token: %w", err)
 	}
 
-	if err := os.WriteFile(path, data, 0600); err != nil {
+	if encryptTokenEnabled() || TokenEncrypted() {
+		if data, err = encryptToken(data); err != nil {
+			return fmt.Errorf("failed to encrypt token: %w", err)
+		}
+	}
+
+	if err := writeFileAtomic(path, data, 0600); err != nil {
 		return fmt.Errorf("failed to write token file: %w", err)
 	}
 
 	return nil
 }
 
-// LoadToken reads the token from disk.
+// LoadToken reads the token from the credential helper if one is configured,
+// otherwise from disk, decrypting it if needed.
 func LoadToken() (*OAuthToken, error) {
+	if helper := CredentialHelper(); helper != "" {
+		token, err := helperGetToken(helper)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get token: %w", err)
+		}
+		return token, nil
+	}
+
 	path := TokenPath()
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read token file: %w", err)
 	}
 
+	if isEncryptedToken(data) {
+		if data, err = decryptToken(data); err != nil {
+			return nil, fmt.Errorf("failed to decrypt token file: %w", err)
+		}
+	}
+
 	var token OAuthToken
 	if err := json.Unmarshal(data, &token); err != nil {
 		return nil, fmt.Errorf("failed to parse token file: %w", err)
@@ -51,42 +82,86 @@ func LoadToken() (*OAuthToken, error) {
 	return &token, nil
 }
 
-// DeleteToken removes the token file and cached config.
+// tokenExpired reports whether the access token has expired.
+func tokenExpired(token *OAuthToken) bool {
+	return token.ExpiresAt > 0 && time.Now().Unix() > token.ExpiresAt
+}
+
+// refreshToken refreshes the token of the active profile if it has expired
+// or its access token is rejected, the one the API just refused. It holds
+// the token lock while refreshing and saving, and reads the token again once
+// the lock is acquired: when several processes start with the same expired
+// token, only the first one refreshes it and the others use its result.
+// Refreshing twice would fail, as a refresh token can only be used once.
+func refreshToken(rejected string) (*OAuthToken, error) {
+	unlock, err := lockFile(TokenPath())
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	token, err := LoadToken()
+	if err != nil {
+		return nil, err
+	}
+	if !tokenExpired(token) && (rejected == "" || token.AccessToken != rejected) {
+		return token, nil
+	}
+	if token.RefreshToken == "" {
+		return nil, fmt.Errorf("token expired and no refresh token available: run 'ticky auth login'")
+	}
+
+	newToken, err := RefreshAccessToken(token.RefreshToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh token: %w (run 'ticky auth login')", err)
+	}
+	if err := SaveToken(newToken); err != nil {
+		return nil, fmt.Errorf("failed to save refreshed token: %w", err)
+	}
+	return newToken, nil
+}
+
+func encryptTokenEnabled() bool {
+	value, _, err := ConfigValue("encrypt_token")
+	return err == nil && value == "true"
+}
+
+// DeleteToken removes the token file, the token held by the credential
+// helper and the cached inbox ID. Other settings in the config file are kept.
 func DeleteToken() error {
+	if helper := CredentialHelper(); helper != "" {
+		if err := helperEraseToken(helper); err != nil {
+			return fmt.Errorf("failed to erase token: %w", err)
+		}
+	}
 	path := TokenPath()
 	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to delete token file: %w", err)
 	}
-	// Also remove cached config
-	configPath := configDir()
-	_ = os.Remove(filepath.Join(configPath, "config.json"))
+	// Also forget the cached inbox ID, which belongs to the account
+	_ = UnsetConfigValue("inbox_id")
 	return nil
 }
 
+// configDir returns the directory of the active profile.
 func configDir() string {
-	home, _ := os.UserHomeDir()
-	return filepath.Join(home, tokenDir)
+	return ProfileDir()
 }
 
-// SaveInboxID caches the inbox project ID.
+// SaveInboxID caches the inbox project ID in the config file.
 func SaveInboxID(id string) error {
-	dir := configDir()
-	if err := os.MkdirAll(dir, 0700); err != nil {
-		return err
-	}
-	data, _ := json.Marshal(map[string]string{"inbox_id": id})
-	return os.WriteFile(filepath.Join(dir, "config.json"), data, 0600)
+	return SetConfigValue("inbox_id", id)
 }
 
 // LoadInboxID reads the cached inbox project ID.
 func LoadInboxID() (string, error) {
-	data, err := os.ReadFile(filepath.Join(configDir(), "config.json"))
+	data, err := os.ReadFile(ConfigPath())
 	if err != nil {
 		return "", err
 	}
-	var cfg map[string]string
+	var cfg Config
 	if err := json.Unmarshal(data, &cfg); err != nil {
 		return "", err
 	}
-	return cfg["inbox_id"], nil
+	return cfg.InboxID, nil
 }
@@ -0,0 +1,287 @@
+package ticktick
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+const (
+	tokenCipher = "aes-256-gcm"
+	tokenKDF    = "pbkdf2-sha256"
+
+	keySourcePassphrase = "passphrase"
+	keySourceKeyFile    = "key_file"
+)
+
+// tokenKDFIterations is the PBKDF2 work factor for newly encrypted tokens.
+// The count is stored in the file, so it can be raised later.
+var tokenKDFIterations = 600000
+
+// ErrNoTokenKey is returned when an encrypted token cannot be read or written
+// because neither a key file nor a passphrase is available.
+var ErrNoTokenKey = errors.New("no token encryption key: set TICKY_KEY_FILE or TICKY_PASSPHRASE")
+
+// PassphraseFunc asks the user for the token passphrase. confirm is true when
+// a token is being encrypted and the passphrase should be entered twice. It is
+// set by the CLI; when nil, only TICKY_PASSPHRASE is used.
+var PassphraseFunc func(confirm bool) (string, error)
+
+// passphrase remembers the passphrase entered for this process so a token
+// refresh does not prompt again.
+var passphrase struct {
+	sync.Mutex
+	value string
+}
+
+// tokenEnvelope is the on-disk form of an encrypted token.
+type tokenEnvelope struct {
+	Encrypted  string `json:"encrypted"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	KeySource  string `json:"key_source"`
+	Salt       string `json:"salt"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+// isEncryptedToken reports whether data is an encrypted token file.
+func isEncryptedToken(data []byte) bool {
+	var env tokenEnvelope
+	return json.Unmarshal(data, &env) == nil && env.Encrypted != ""
+}
+
+// TokenEncrypted reports whether the token file of the active profile is
+// encrypted.
+func TokenEncrypted() bool {
+	data, err := os.ReadFile(TokenPath())
+	return err == nil && isEncryptedToken(data)
+}
+
+// keySource returns where the key for newly encrypted tokens comes from.
+func keySource() string {
+	if os.Getenv("TICKY_KEY_FILE") != "" {
+		return keySourceKeyFile
+	}
+	return keySourcePassphrase
+}
+
+// tokenSecret returns the secret the token key is derived from.
+func tokenSecret(source string, confirm bool) (string, error) {
+	switch source {
+	case keySourceKeyFile:
+		path := os.Getenv("TICKY_KEY_FILE")
+		if path == "" {
+			return "", fmt.Errorf("token was encrypted with a key file: set TICKY_KEY_FILE")
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read key file: %w", err)
+		}
+		secret := string(bytes.TrimSpace(data))
+		if secret == "" {
+			return "", fmt.Errorf("key file %s is empty", path)
+		}
+		return secret, nil
+	case keySourcePassphrase:
+		if p := os.Getenv("TICKY_PASSPHRASE"); p != "" {
+			return p, nil
+		}
+		passphrase.Lock()
+		defer passphrase.Unlock()
+		if passphrase.value != "" {
+			return passphrase.value, nil
+		}
+		if PassphraseFunc == nil {
+			return "", ErrNoTokenKey
+		}
+		p, err := PassphraseFunc(confirm)
+		if err != nil {
+			return "", err
+		}
+		if p == "" {
+			return "", fmt.Errorf("passphrase must not be empty")
+		}
+		passphrase.value = p
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown token key source %q", source)
+}
+
+func tokenAEAD(secret string, salt []byte, iterations int) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, secret, salt, iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptToken seals a plaintext token file.
+func encryptToken(plaintext []byte) ([]byte, error) {
+	source := keySource()
+	secret, err := tokenSecret(source, true)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := tokenAEAD(secret, salt, tokenKDFIterations)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	env := tokenEnvelope{
+		Encrypted:  tokenCipher,
+		KDF:        tokenKDF,
+		Iterations: tokenKDFIterations,
+		KeySource:  source,
+		Salt:       base64.StdEncoding.EncodeToString(salt),
+		Nonce:      base64.StdEncoding.EncodeToString(nonce),
+		Ciphertext: base64.StdEncoding.EncodeToString(aead.Seal(nil, nonce, plaintext, nil)),
+	}
+	return json.MarshalIndent(env, "", "  ")
+}
+
+// decryptToken opens an encrypted token file.
+func decryptToken(data []byte) ([]byte, error) {
+	var env tokenEnvelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, err
+	}
+	if env.Encrypted != tokenCipher || env.KDF != tokenKDF {
+		return nil, fmt.Errorf("unsupported token encryption %s/%s", env.Encrypted, env.KDF)
+	}
+	salt, err := base64.StdEncoding.DecodeString(env.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid salt: %w", err)
+	}
+	nonce, err := base64.StdEncoding.DecodeString(env.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("invalid nonce: %w", err)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(env.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ciphertext: %w", err)
+	}
+
+	secret, err := tokenSecret(env.KeySource, false)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := tokenAEAD(secret, salt, env.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce length")
+	}
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		if env.KeySource == keySourcePassphrase {
+			passphrase.Lock()
+			passphrase.value = ""
+			passphrase.Unlock()
+		}
+		return nil, fmt.Errorf("wrong passphrase or key file")
+	}
+	return plaintext, nil
+}
+
+// EncryptTokenFile encrypts the existing plaintext token of the active
+// profile in place and turns on encrypt_token so refreshed tokens stay
+// encrypted. The token is encrypted and written before the config changes, so
+// a missing key leaves both untouched. It returns false if the token was
+// already encrypted.
+func EncryptTokenFile() (bool, error) {
+	if helper := CredentialHelper(); helper != "" {
+		return false, fmt.Errorf("the token is kept by credential helper %q, not in a file", helper)
+	}
+
+	path := TokenPath()
+	unlock, err := lockFile(path)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read token file: %w", err)
+	}
+	if isEncryptedToken(original) {
+		return false, SetConfigValue("encrypt_token", "true")
+	}
+	var token OAuthToken
+	if err := json.Unmarshal(original, &token); err != nil {
+		return false, fmt.Errorf("failed to parse token file: %w", err)
+	}
+
+	encrypted, err := encryptToken(original)
+	if err != nil {
+		return false, fmt.Errorf("failed to encrypt token: %w", err)
+	}
+	if err := writeFileAtomic(path, encrypted, 0600); err != nil {
+		return false, fmt.Errorf("failed to write token file: %w", err)
+	}
+	if err := SetConfigValue("encrypt_token", "true"); err != nil {
+		if rbErr := writeFileAtomic(path, original, 0600); rbErr != nil {
+			return false, fmt.Errorf("%w (restoring the plaintext token also failed: %v)", err, rbErr)
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// DecryptTokenFile stores the token of the active profile as plaintext again
+// and turns off encrypt_token. It returns false if the token was not encrypted.
+func DecryptTokenFile() (bool, error) {
+	if helper := CredentialHelper(); helper != "" {
+		return false, fmt.Errorf("the token is kept by credential helper %q, not in a file", helper)
+	}
+
+	path := TokenPath()
+	unlock, err := lockFile(path)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	encrypted := TokenEncrypted()
+	token, err := LoadToken()
+	if err != nil {
+		return false, err
+	}
+	if encrypted {
+		data, err := json.MarshalIndent(token, "", "  ")
+		if err != nil {
+			return false, fmt.Errorf("failed to marshal token: %w", err)
+		}
+		if err := writeFileAtomic(path, data, 0600); err != nil {
+			return false, fmt.Errorf("failed to write token file: %w", err)
+		}
+	}
+	if err := UnsetConfigValue("encrypt_token"); err != nil {
+		return false, err
+	}
+	return encrypted, nil
+}
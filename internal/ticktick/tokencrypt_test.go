@@ -0,0 +1,203 @@
+package ticktick_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+)
+
+// setupTokenCrypt isolates HOME, lowers the KDF work factor and saves a
+// plaintext token.
+func setupTokenCrypt(t *testing.T) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("TICKY_KEY_FILE", "")
+	t.Setenv("TICKY_PASSPHRASE", "")
+	t.Cleanup(ticktick.SetKDFIterations(1000))
+	t.Cleanup(ticktick.ForgetPassphrase)
+	if err := ticktick.SaveToken(&ticktick.OAuthToken{AccessToken: "secret-access", RefreshToken: "secret-refresh"}); err != nil {
+		t.Fatalf("SaveToken() returned unexpected error: %v", err)
+	}
+}
+
+func TestEncryptTokenFile_Passphrase(t *testing.T) {
+	// Arrange
+	setupTokenCrypt(t)
+	t.Setenv("TICKY_PASSPHRASE", "correct horse")
+
+	// Act
+	changed, err := ticktick.EncryptTokenFile()
+
+	// Assert
+	if err != nil || !changed {
+		t.Fatalf("EncryptTokenFile() = %v, %v, want true, nil", changed, err)
+	}
+	data, _ := os.ReadFile(ticktick.TokenPath())
+	if strings.Contains(string(data), "secret-access") || strings.Contains(string(data), "secret-refresh") {
+		t.Errorf("token file still contains plaintext tokens: %s", data)
+	}
+	token, err := ticktick.LoadToken()
+	if err != nil {
+		t.Fatalf("LoadToken() returned unexpected error: %v", err)
+	}
+	if token.AccessToken != "secret-access" || token.RefreshToken != "secret-refresh" {
+		t.Errorf("LoadToken() = %+v, want the original tokens", token)
+	}
+}
+
+func TestEncryptTokenFile_NoKey(t *testing.T) {
+	// Arrange — neither TICKY_PASSPHRASE nor TICKY_KEY_FILE, and no prompt
+	setupTokenCrypt(t)
+	ticktick.PassphraseFunc = nil
+
+	// Act
+	changed, err := ticktick.EncryptTokenFile()
+
+	// Assert
+	if changed || !errors.Is(err, ticktick.ErrNoTokenKey) {
+		t.Fatalf("EncryptTokenFile() = %v, %v, want false, ErrNoTokenKey", changed, err)
+	}
+	cfg, err := ticktick.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() returned unexpected error: %v", err)
+	}
+	if cfg.EncryptToken {
+		t.Error("EncryptTokenFile() turned on encrypt_token although the token was not encrypted")
+	}
+	if ticktick.TokenEncrypted() {
+		t.Error("EncryptTokenFile() left an encrypted token file")
+	}
+	if token, err := ticktick.LoadToken(); err != nil || token.AccessToken != "secret-access" {
+		t.Errorf("LoadToken() = %+v, %v, want the plaintext token", token, err)
+	}
+}
+
+func TestLoadToken_WrongKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		setKey  func(t *testing.T)
+		wrongFn func(t *testing.T)
+	}{
+		{
+			name:    "passphrase",
+			setKey:  func(t *testing.T) { t.Setenv("TICKY_PASSPHRASE", "right") },
+			wrongFn: func(t *testing.T) { t.Setenv("TICKY_PASSPHRASE", "wrong") },
+		},
+		{
+			name: "key file",
+			setKey: func(t *testing.T) {
+				path := filepath.Join(t.TempDir(), "key")
+				os.WriteFile(path, []byte("key-one\n"), 0600)
+				t.Setenv("TICKY_KEY_FILE", path)
+			},
+			wrongFn: func(t *testing.T) {
+				path := filepath.Join(t.TempDir(), "key")
+				os.WriteFile(path, []byte("key-two\n"), 0600)
+				t.Setenv("TICKY_KEY_FILE", path)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			setupTokenCrypt(t)
+			tt.setKey(t)
+			if _, err := ticktick.EncryptTokenFile(); err != nil {
+				t.Fatalf("EncryptTokenFile() returned unexpected error: %v", err)
+			}
+			if _, err := ticktick.LoadToken(); err != nil {
+				t.Fatalf("LoadToken() with the right key returned unexpected error: %v", err)
+			}
+			tt.wrongFn(t)
+
+			// Act
+			_, err := ticktick.LoadToken()
+
+			// Assert
+			if err == nil || !strings.Contains(err.Error(), "wrong passphrase or key file") {
+				t.Errorf("LoadToken() error = %v, want wrong key error", err)
+			}
+		})
+	}
+}
+
+func TestSaveToken_StaysEncrypted(t *testing.T) {
+	// Arrange — an encrypted file without encrypt_token, as after a manual config edit
+	setupTokenCrypt(t)
+	t.Setenv("TICKY_PASSPHRASE", "pass")
+	ticktick.EncryptTokenFile()
+	ticktick.UnsetConfigValue("encrypt_token")
+
+	// Act — a token refresh saves a new token
+	if err := ticktick.SaveToken(&ticktick.OAuthToken{AccessToken: "refreshed"}); err != nil {
+		t.Fatalf("SaveToken() returned unexpected error: %v", err)
+	}
+
+	// Assert
+	if !ticktick.TokenEncrypted() {
+		t.Error("SaveToken() downgraded an encrypted token file to plaintext")
+	}
+	if token, err := ticktick.LoadToken(); err != nil || token.AccessToken != "refreshed" {
+		t.Errorf("LoadToken() = %+v, %v, want refreshed", token, err)
+	}
+}
+
+func TestTokenPassphrase_Prompt(t *testing.T) {
+	// Arrange
+	setupTokenCrypt(t)
+	prompts := 0
+	ticktick.PassphraseFunc = func(confirm bool) (string, error) {
+		prompts++
+		return "prompted", nil
+	}
+	defer func() { ticktick.PassphraseFunc = nil }()
+
+	// Act
+	if _, err := ticktick.EncryptTokenFile(); err != nil {
+		t.Fatalf("EncryptTokenFile() returned unexpected error: %v", err)
+	}
+	_, err := ticktick.LoadToken()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("LoadToken() returned unexpected error: %v", err)
+	}
+	if prompts != 1 {
+		t.Errorf("passphrase prompted %d times, want 1", prompts)
+	}
+
+	t.Run("no key available", func(t *testing.T) {
+		ticktick.PassphraseFunc = nil
+		ticktick.ForgetPassphrase()
+		if _, err := ticktick.LoadToken(); !errors.Is(err, ticktick.ErrNoTokenKey) {
+			t.Errorf("LoadToken() error = %v, want ErrNoTokenKey", err)
+		}
+	})
+}
+
+func TestDecryptTokenFile(t *testing.T) {
+	// Arrange
+	setupTokenCrypt(t)
+	t.Setenv("TICKY_PASSPHRASE", "pass")
+	ticktick.EncryptTokenFile()
+
+	// Act
+	changed, err := ticktick.DecryptTokenFile()
+
+	// Assert
+	if err != nil || !changed {
+		t.Fatalf("DecryptTokenFile() = %v, %v, want true, nil", changed, err)
+	}
+	data, _ := os.ReadFile(ticktick.TokenPath())
+	if !strings.Contains(string(data), "secret-access") {
+		t.Errorf("token file is not plaintext after decrypt: %s", data)
+	}
+	if v, _, _ := ticktick.ConfigValue("encrypt_token"); v != "false" {
+		t.Errorf("encrypt_token = %q after decrypt, want false", v)
+	}
+}
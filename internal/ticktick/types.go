@@ -20,23 +20,47 @@ type Project struct {
 
 // Task represents a TickTick task.
 type Task struct {
-	ID          string    `json:"id"`
-	ProjectID   string    `json:"projectId"`
-	Title       string    `json:"title"`
-	Content     string    `json:"content,omitempty"`
-	Desc        string    `json:"desc,omitempty"`
-	Priority    int       `json:"priority"`
-	Status      int       `json:"status"`
-	DueDate     string    `json:"dueDate,omitempty"`
-	StartDate   string    `json:"startDate,omitempty"`
-	Tags        []string  `json:"tags,omitempty"`
-	TimeZone    string    `json:"timeZone,omitempty"`
-	IsAllDay    bool      `json:"isAllDay"`
-	CompletedAt string       `json:"completedTime,omitempty"`
-	CreatedAt   FlexTime     `json:"createdTime,omitempty"`
-	ModifiedAt  FlexTime     `json:"modifiedTime,omitempty"`
+	ID          string          `json:"id"`
+	ProjectID   string          `json:"projectId"`
+	Title       string          `json:"title"`
+	Content     string          `json:"content,omitempty"`
+	Desc        string          `json:"desc,omitempty"`
+	Priority    int             `json:"priority"`
+	Status      int             `json:"status"`
+	DueDate     string          `json:"dueDate,omitempty"`
+	StartDate   string          `json:"startDate,omitempty"`
+	Tags        []string        `json:"tags,omitempty"`
+	TimeZone    string          `json:"timeZone,omitempty"`
+	IsAllDay    bool            `json:"isAllDay"`
+	RepeatFlag  string          `json:"repeatFlag,omitempty"`
+	Reminders   []string        `json:"reminders,omitempty"`
+	Items       []ChecklistItem `json:"items,omitempty"`
+	CompletedAt string          `json:"completedTime,omitempty"`
+	CreatedAt   FlexTime        `json:"createdTime,omitempty"`
+	ModifiedAt  FlexTime        `json:"modifiedTime,omitempty"`
 }
 
+// ChecklistItem represents a subtask (checklist item) of a TickTick task.
+type ChecklistItem struct {
+	ID          string `json:"id,omitempty"`
+	Title       string `json:"title"`
+	Status      int    `json:"status"`
+	SortOrder   int64  `json:"sortOrder,omitempty"`
+	CompletedAt string `json:"completedTime,omitempty"`
+}
+
+// Task status values matching TickTick API values.
+const (
+	TaskStatusNormal    = 0
+	TaskStatusCompleted = 2
+)
+
+// Checklist item status values matching TickTick API values.
+const (
+	ChecklistStatusNormal    = 0
+	ChecklistStatusCompleted = 1
+)
+
 // FlexTime handles TickTick's non-standard date format (+0000 instead of +00:00).
 type FlexTime struct {
 	time.Time
@@ -47,22 +71,12 @@ func (ft *FlexTime) UnmarshalJSON(b []byte) error {
 	if s == "" || s == "null" {
 		return nil
 	}
-	// Try RFC3339 first
-	if t, err := time.Parse(time.RFC3339, s); err == nil {
-		ft.Time = t
-		return nil
-	}
-	// Try TickTick's +0000 format
-	if t, err := time.Parse("2006-01-02T15:04:05.000+0000", s); err == nil {
-		ft.Time = t
-		return nil
+	t, err := parseAPITime(s)
+	if err != nil {
+		return err
 	}
-	// Try without milliseconds
-	if t, err := time.Parse("2006-01-02T15:04:05+0000", s); err == nil {
-		ft.Time = t
-		return nil
-	}
-	return &time.ParseError{Value: s, Message: "unsupported time format"}
+	ft.Time = t
+	return nil
 }
 
 func (ft FlexTime) MarshalJSON() ([]byte, error) {
@@ -78,18 +92,28 @@ type ProjectData struct {
 	Tasks   []Task  `json:"tasks"`
 }
 
+// ProjectCreateRequest is the request body for creating a project.
+type ProjectCreateRequest struct {
+	Name     string `json:"name"`
+	Color    string `json:"color,omitempty"`
+	ViewMode string `json:"viewMode,omitempty"`
+	Kind     string `json:"kind,omitempty"`
+}
+
 // TaskCreateRequest is the request body for creating a task.
 type TaskCreateRequest struct {
-	Title     string   `json:"title"`
-	ProjectID string   `json:"projectId,omitempty"`
-	Content   string   `json:"content,omitempty"`
-	Desc      string   `json:"desc,omitempty"`
-	Priority  int      `json:"priority,omitempty"`
-	DueDate   string   `json:"dueDate,omitempty"`
-	StartDate string   `json:"startDate,omitempty"`
-	Tags      []string `json:"tags,omitempty"`
-	TimeZone  string   `json:"timeZone,omitempty"`
-	IsAllDay  bool     `json:"isAllDay,omitempty"`
+	Title      string          `json:"title"`
+	ProjectID  string          `json:"projectId,omitempty"`
+	Content    string          `json:"content,omitempty"`
+	Desc       string          `json:"desc,omitempty"`
+	Priority   int             `json:"priority,omitempty"`
+	DueDate    string          `json:"dueDate,omitempty"`
+	StartDate  string          `json:"startDate,omitempty"`
+	Tags       []string        `json:"tags,omitempty"`
+	TimeZone   string          `json:"timeZone,omitempty"`
+	IsAllDay   bool            `json:"isAllDay,omitempty"`
+	RepeatFlag string          `json:"repeatFlag,omitempty"`
+	Items      []ChecklistItem `json:"items,omitempty"`
 }
 
 // TaskUpdateRequest is the request body for updating a task.
@@ -0,0 +1,195 @@
+package ticktick
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const watchStateFile = "watch_state.json"
+
+// Watch event types.
+const (
+	WatchCreated   = "created"
+	WatchUpdated   = "updated"
+	WatchCompleted = "completed"
+	WatchDeleted   = "deleted"
+)
+
+// WatchEvent is a task change found by Client.Poll. Updated events carry the
+// changed fields; completed and deleted events carry the last known task.
+type WatchEvent struct {
+	Type    string        `json:"type"`
+	Time    time.Time     `json:"time"`
+	Task    Task          `json:"task"`
+	Changes []FieldChange `json:"changes,omitempty"`
+}
+
+// WatchFilter limits which tasks are watched. Empty fields match everything.
+type WatchFilter struct {
+	ProjectIDs []string
+	// Tags reports only tasks carrying at least one of the tags, before or
+	// after the change.
+	Tags []string
+}
+
+// WatchState is the snapshot the next poll is compared against. A state
+// without tasks has no baseline yet.
+type WatchState struct {
+	UpdatedAt time.Time       `json:"updatedAt"`
+	Tasks     map[string]Task `json:"tasks"`
+}
+
+// WatchStatePath returns the default watch state file of the active profile.
+func WatchStatePath() string {
+	return filepath.Join(configDir(), watchStateFile)
+}
+
+// LoadWatchState reads a watch state file. A missing file yields an empty
+// state.
+func LoadWatchState(path string) (*WatchState, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &WatchState{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read watch state: %w", err)
+	}
+	var s WatchState
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse watch state %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// Save writes the state to path atomically.
+func (s *WatchState) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create watch state directory: %w", err)
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to encode watch state: %w", err)
+	}
+	if err := writeFileAtomic(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write watch state: %w", err)
+	}
+	return nil
+}
+
+// Poll fetches the watched projects, compares their tasks with state and
+// returns the changes, then advances state to the current tasks. The first
+// poll of an empty state only records the baseline. Tasks whose modified
+// time did not change are not compared field by field. Tasks that disappear
+// are looked up to tell completed tasks from deleted ones. On error state is
+// left unchanged.
+func (c *Client) Poll(state *WatchState, filter WatchFilter) ([]WatchEvent, error) {
+	projectIDs := filter.ProjectIDs
+	if len(projectIDs) == 0 {
+		var err error
+		if projectIDs, err = c.GetAllProjectIDs(); err != nil {
+			return nil, fmt.Errorf("failed to list projects: %w", err)
+		}
+	}
+
+	watched := make(map[string]bool)
+	current := make(map[string]bool)
+	var tasks []Task
+	for _, pid := range projectIDs {
+		pd, err := c.GetProjectData(pid)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get project %s: %w", pid, err)
+		}
+		watched[pid] = true
+		for _, t := range pd.Tasks {
+			current[t.ID] = true
+			tasks = append(tasks, t)
+		}
+	}
+
+	now := time.Now().UTC()
+	baseline := state.Tasks != nil
+	next := make(map[string]Task)
+	var events []WatchEvent
+	emit := func(typ string, t, old Task, changes []FieldChange) {
+		if filter.matchTags(t) || filter.matchTags(old) {
+			events = append(events, WatchEvent{Type: typ, Time: now, Task: t, Changes: changes})
+		}
+	}
+
+	// Tasks of projects outside this poll keep their recorded state
+	for id, t := range state.Tasks {
+		if !watched[t.ProjectID] {
+			next[id] = t
+		}
+	}
+
+	for _, cur := range tasks {
+		next[cur.ID] = cur
+		if !baseline {
+			continue
+		}
+		old, ok := state.Tasks[cur.ID]
+		switch {
+		case !ok:
+			emit(WatchCreated, cur, cur, nil)
+		case sameModifiedTime(old, cur):
+		default:
+			if changes := DiffTask(old, cur); len(changes) > 0 {
+				emit(WatchUpdated, cur, old, changes)
+			}
+		}
+	}
+
+	if baseline {
+		d := &SnapshotDiff{}
+		for id, old := range state.Tasks {
+			if watched[old.ProjectID] && !current[id] {
+				d.Removed = append(d.Removed, taskChange(old, nil))
+			}
+		}
+		sort.Slice(d.Removed, func(i, j int) bool { return d.Removed[i].ID < d.Removed[j].ID })
+		c.ClassifyRemoved(d)
+		for _, tc := range d.Completed {
+			old := state.Tasks[tc.ID]
+			done := old
+			done.Status = TaskStatusCompleted
+			emit(WatchCompleted, done, old, nil)
+		}
+		for _, tc := range d.Removed {
+			old := state.Tasks[tc.ID]
+			emit(WatchDeleted, old, old, nil)
+		}
+	}
+
+	state.Tasks = next
+	state.UpdatedAt = now
+	return events, nil
+}
+
+// sameModifiedTime reports whether both versions carry the same modified
+// time. The state file stores whole seconds.
+func sameModifiedTime(old, cur Task) bool {
+	if old.ModifiedAt.IsZero() || cur.ModifiedAt.IsZero() {
+		return false
+	}
+	return old.ModifiedAt.Truncate(time.Second).Equal(cur.ModifiedAt.Truncate(time.Second))
+}
+
+func (f WatchFilter) matchTags(t Task) bool {
+	if len(f.Tags) == 0 {
+		return true
+	}
+	for _, want := range f.Tags {
+		for _, tag := range t.Tags {
+			if strings.EqualFold(tag, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
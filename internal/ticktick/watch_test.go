@@ -0,0 +1,171 @@
+package ticktick_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+)
+
+const (
+	modifiedBefore = "2026-03-01T10:00:00.000+0000"
+	modifiedAfter  = "2026-03-01T11:30:00.000+0000"
+)
+
+// watchServer serves the tasks of proj-1, which tests can replace between
+// polls, and reports the task "done" as completed and any other task as gone.
+type watchServer struct {
+	mu    sync.Mutex
+	tasks []map[string]any
+}
+
+func (s *watchServer) set(tasks ...map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tasks = tasks
+}
+
+func (s *watchServer) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	switch r.URL.Path {
+	case "/project/proj-1/data":
+		json.NewEncoder(w).Encode(map[string]any{"tasks": s.tasks})
+	case "/project/proj-1/task/done":
+		json.NewEncoder(w).Encode(ticktick.Task{ID: "done", ProjectID: "proj-1", Status: ticktick.TaskStatusCompleted})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func watchTask(id, title, modified string, tags ...string) map[string]any {
+	return map[string]any{"id": id, "projectId": "proj-1", "title": title, "modifiedTime": modified, "tags": tags}
+}
+
+func eventSummary(events []ticktick.WatchEvent) string {
+	var parts []string
+	for _, ev := range events {
+		parts = append(parts, ev.Type+":"+ev.Task.ID)
+	}
+	return strings.Join(parts, ",")
+}
+
+func TestPoll_EmitsChangesAfterResume(t *testing.T) {
+	// Arrange
+	t.Setenv("HOME", t.TempDir())
+	srv := &watchServer{}
+	client, cleanup := setupMockServer(t, srv.handle)
+	defer cleanup()
+	filter := ticktick.WatchFilter{ProjectIDs: []string{"proj-1"}}
+	path := filepath.Join(t.TempDir(), "watch_state.json")
+
+	srv.set(
+		watchTask("same", "Unchanged", modifiedBefore),
+		watchTask("edit", "Old title", modifiedBefore),
+		watchTask("done", "Will complete", modifiedBefore),
+		watchTask("gone", "Will be deleted", modifiedBefore),
+	)
+	state, err := ticktick.LoadWatchState(path)
+	if err != nil {
+		t.Fatalf("LoadWatchState() returned unexpected error: %v", err)
+	}
+	baseline, err := client.Poll(state, filter)
+	if err != nil {
+		t.Fatalf("Poll() returned unexpected error: %v", err)
+	}
+	if err := state.Save(path); err != nil {
+		t.Fatalf("Save() returned unexpected error: %v", err)
+	}
+
+	// The title of "same" changes without a new modified time, so it is skipped
+	srv.set(
+		watchTask("same", "Not compared", modifiedBefore),
+		watchTask("edit", "New title", modifiedAfter),
+		watchTask("new", "Added", modifiedAfter),
+	)
+
+	// Act
+	resumed, _ := ticktick.LoadWatchState(path)
+	events, err := client.Poll(resumed, filter)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Poll() returned unexpected error: %v", err)
+	}
+	if len(baseline) != 0 {
+		t.Errorf("first Poll() = %s, want no events", eventSummary(baseline))
+	}
+	want := "updated:edit,created:new,completed:done,deleted:gone"
+	if got := eventSummary(events); got != want {
+		t.Fatalf("Poll() = %s, want %s", got, want)
+	}
+	wantChanges := []ticktick.FieldChange{{Field: "title", Old: "Old title", New: "New title"}}
+	if !reflect.DeepEqual(events[0].Changes, wantChanges) {
+		t.Errorf("updated changes = %+v, want %+v", events[0].Changes, wantChanges)
+	}
+	if events[2].Task.Status != ticktick.TaskStatusCompleted || events[2].Task.Title != "Will complete" {
+		t.Errorf("completed event task = %+v, want the last known task marked completed", events[2].Task)
+	}
+	if _, ok := resumed.Tasks["gone"]; ok || len(resumed.Tasks) != 3 {
+		t.Errorf("state tasks = %d, want the 3 current tasks", len(resumed.Tasks))
+	}
+}
+
+func TestPoll_TagFilter(t *testing.T) {
+	// Arrange
+	t.Setenv("HOME", t.TempDir())
+	srv := &watchServer{}
+	client, cleanup := setupMockServer(t, srv.handle)
+	defer cleanup()
+	filter := ticktick.WatchFilter{ProjectIDs: []string{"proj-1"}, Tags: []string{"Work"}}
+	state := &ticktick.WatchState{}
+
+	srv.set(
+		watchTask("a", "Tagged", modifiedBefore, "work"),
+		watchTask("b", "Other tag", modifiedBefore, "home"),
+		watchTask("c", "Loses tag", modifiedBefore, "work"),
+	)
+	client.Poll(state, filter)
+	srv.set(
+		watchTask("a", "Tagged, edited", modifiedAfter, "work"),
+		watchTask("b", "Other tag, edited", modifiedAfter, "home"),
+		watchTask("c", "Loses tag", modifiedAfter),
+	)
+
+	// Act
+	events, err := client.Poll(state, filter)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Poll() returned unexpected error: %v", err)
+	}
+	if got := eventSummary(events); got != "updated:a,updated:c" {
+		t.Errorf("Poll() = %s, want updated:a,updated:c", got)
+	}
+}
+
+func TestPoll_ErrorKeepsState(t *testing.T) {
+	// Arrange
+	client, cleanup := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	defer cleanup()
+	state := &ticktick.WatchState{Tasks: map[string]ticktick.Task{"t1": {ID: "t1", ProjectID: "proj-1"}}}
+
+	// Act
+	_, err := client.Poll(state, ticktick.WatchFilter{ProjectIDs: []string{"proj-1"}})
+
+	// Assert
+	if err == nil {
+		t.Fatal("Poll() returned nil error, want failure")
+	}
+	if len(state.Tasks) != 1 || !state.UpdatedAt.IsZero() {
+		t.Errorf("state = %+v, want it unchanged", state)
+	}
+}
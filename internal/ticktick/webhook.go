@@ -0,0 +1,338 @@
+package ticktick
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	webhooksFile    = "webhooks.json"
+	webhookQueueDir = "webhook_queue"
+
+	// WebhookTestEvent is the event type sent by "webhooks test".
+	WebhookTestEvent = "test"
+
+	// Headers of a webhook request. The signature is "sha256=" followed by
+	// the hex HMAC-SHA256 of the body keyed with the webhook secret.
+	WebhookEventHeader     = "X-Ticky-Event"
+	WebhookDeliveryHeader  = "X-Ticky-Delivery"
+	WebhookSignatureHeader = "X-Ticky-Signature"
+
+	webhookTimeout     = 10 * time.Second
+	webhookMaxAttempts = 10
+	webhookBaseBackoff = 30 * time.Second
+	webhookMaxBackoff  = time.Hour
+)
+
+// webhookEvents are the event types a webhook can subscribe to.
+var webhookEvents = []string{WatchCreated, WatchUpdated, WatchCompleted, WatchDeleted}
+
+// Webhook is an outbound HTTP endpoint notified of task events.
+type Webhook struct {
+	URL string `json:"url"`
+	// Events lists the subscribed event types; empty means all.
+	Events []string `json:"events,omitempty"`
+	Secret string   `json:"secret"`
+}
+
+// Matches reports whether the webhook subscribes to the event type.
+func (w Webhook) Matches(event string) bool {
+	return len(w.Events) == 0 || slices.Contains(w.Events, event)
+}
+
+// ValidateWebhook checks the URL and the event filter of a webhook.
+func ValidateWebhook(w Webhook) error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid webhook URL %q: must be an absolute http or https URL", w.URL)
+	}
+	for _, e := range w.Events {
+		if !slices.Contains(webhookEvents, e) {
+			return fmt.Errorf("unknown webhook event %q (use %s)", e, strings.Join(webhookEvents, ", "))
+		}
+	}
+	return nil
+}
+
+// GenerateWebhookSecret returns a random secret for signing payloads.
+func GenerateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// SignWebhookPayload returns the signature header value for body.
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// LoadWebhooks returns the configured webhooks, keyed by name.
+func LoadWebhooks() (map[string]Webhook, error) {
+	hooks := make(map[string]Webhook)
+	data, err := os.ReadFile(filepath.Join(configDir(), webhooksFile))
+	if os.IsNotExist(err) {
+		return hooks, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &hooks); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", webhooksFile, err)
+	}
+	return hooks, nil
+}
+
+// SaveWebhooks persists the configured webhooks.
+func SaveWebhooks(hooks map[string]Webhook) error {
+	dir := configDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(hooks, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(dir, webhooksFile), data, 0600)
+}
+
+// WebhookPayload is the JSON body of a webhook request.
+type WebhookPayload struct {
+	ID string `json:"id"`
+	WatchEvent
+}
+
+// SendWebhook posts a signed payload to the webhook. Any 2xx response is a
+// successful delivery.
+func SendWebhook(client *http.Client, hook Webhook, deliveryID, event string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ticky-webhook")
+	req.Header.Set(WebhookEventHeader, event)
+	req.Header.Set(WebhookDeliveryHeader, deliveryID)
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(hook.Secret, body))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// WebhookDelivery is a queued webhook request.
+type WebhookDelivery struct {
+	ID          string          `json:"id"`
+	Webhook     string          `json:"webhook"`
+	Event       string          `json:"event"`
+	Payload     json.RawMessage `json:"payload"`
+	Attempts    int             `json:"attempts"`
+	NextAttempt time.Time       `json:"nextAttempt"`
+	LastError   string          `json:"lastError,omitempty"`
+}
+
+// WebhookFlushResult summarizes a WebhookQueue.Flush.
+type WebhookFlushResult struct {
+	Delivered int
+	Pending   int
+	// Dropped lists deliveries given up after the last attempt.
+	Dropped []WebhookDelivery
+}
+
+// WebhookQueue stores pending deliveries as one file each in a directory,
+// so events survive restarts until their webhook accepts them.
+type WebhookQueue struct {
+	dir    string
+	client *http.Client
+	now    func() time.Time
+	last   int64 // timestamp of the last delivery ID
+}
+
+// NewWebhookQueue returns the webhook queue of the active profile.
+func NewWebhookQueue() *WebhookQueue {
+	return NewWebhookQueueAt(filepath.Join(configDir(), webhookQueueDir))
+}
+
+// NewWebhookQueueAt returns a webhook queue stored in dir.
+func NewWebhookQueueAt(dir string) *WebhookQueue {
+	return &WebhookQueue{dir: dir, client: &http.Client{Timeout: webhookTimeout}, now: time.Now}
+}
+
+// Enqueue queues one delivery per event and subscribed webhook and returns
+// the number queued.
+func (q *WebhookQueue) Enqueue(hooks map[string]Webhook, events []WatchEvent) (int, error) {
+	names := make([]string, 0, len(hooks))
+	for name := range hooks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	queued := 0
+	for _, ev := range events {
+		for _, name := range names {
+			if !hooks[name].Matches(ev.Type) {
+				continue
+			}
+			id, err := q.newDeliveryID()
+			if err != nil {
+				return queued, err
+			}
+			payload, err := json.Marshal(WebhookPayload{ID: id, WatchEvent: ev})
+			if err != nil {
+				return queued, fmt.Errorf("failed to encode webhook payload: %w", err)
+			}
+			d := WebhookDelivery{ID: id, Webhook: name, Event: ev.Type, Payload: payload, NextAttempt: q.now().UTC()}
+			if err := q.save(d); err != nil {
+				return queued, err
+			}
+			queued++
+		}
+	}
+	return queued, nil
+}
+
+// Flush sends every delivery that is due, oldest first. A failed delivery
+// is retried with exponential backoff and dropped after the last attempt;
+// later deliveries to the same webhook wait so they arrive in order.
+// Deliveries for removed webhooks are discarded.
+func (q *WebhookQueue) Flush(hooks map[string]Webhook) (*WebhookFlushResult, error) {
+	unlock, err := lockFile(q.dir)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	deliveries, err := q.list()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &WebhookFlushResult{}
+	blocked := make(map[string]bool)
+	for _, d := range deliveries {
+		hook, ok := hooks[d.Webhook]
+		if !ok {
+			q.remove(d)
+			continue
+		}
+		if blocked[d.Webhook] || q.now().Before(d.NextAttempt) {
+			blocked[d.Webhook] = true
+			result.Pending++
+			continue
+		}
+
+		err := SendWebhook(q.client, hook, d.ID, d.Event, d.Payload)
+		if err == nil {
+			q.remove(d)
+			result.Delivered++
+			continue
+		}
+
+		blocked[d.Webhook] = true
+		d.Attempts++
+		d.LastError = err.Error()
+		if d.Attempts >= webhookMaxAttempts {
+			q.remove(d)
+			result.Dropped = append(result.Dropped, d)
+			continue
+		}
+		d.NextAttempt = q.now().UTC().Add(webhookBackoff(d.Attempts))
+		if err := q.save(d); err != nil {
+			return result, err
+		}
+		result.Pending++
+	}
+	return result, nil
+}
+
+// webhookBackoff returns the delay before the next attempt after the given
+// number of failed attempts.
+func webhookBackoff(attempts int) time.Duration {
+	d := webhookBaseBackoff
+	for i := 1; i < attempts && d < webhookMaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, webhookMaxBackoff)
+}
+
+// newDeliveryID returns a unique ID that sorts in queueing order.
+func (q *WebhookQueue) newDeliveryID() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate delivery ID: %w", err)
+	}
+	ts := max(q.now().UnixNano(), q.last+1)
+	q.last = ts
+	return fmt.Sprintf("%019d-%s", ts, hex.EncodeToString(b)), nil
+}
+
+func (q *WebhookQueue) save(d WebhookDelivery) error {
+	if err := os.MkdirAll(q.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create webhook queue: %w", err)
+	}
+	data, err := json.Marshal(d)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook delivery: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(q.dir, d.ID+".json"), data, 0600); err != nil {
+		return fmt.Errorf("failed to queue webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func (q *WebhookQueue) remove(d WebhookDelivery) {
+	os.Remove(filepath.Join(q.dir, d.ID+".json"))
+}
+
+// list returns the queued deliveries, oldest first. Unreadable files are
+// skipped.
+func (q *WebhookQueue) list() ([]WebhookDelivery, error) {
+	entries, err := os.ReadDir(q.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook queue: %w", err)
+	}
+
+	var deliveries []WebhookDelivery
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(q.dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		var d WebhookDelivery
+		if json.Unmarshal(data, &d) == nil && d.ID != "" {
+			deliveries = append(deliveries, d)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID < deliveries[j].ID })
+	return deliveries, nil
+}
@@ -0,0 +1,202 @@
+package ticktick_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+)
+
+// webhookReceiver records webhook requests and answers with the queued
+// statuses, then 200.
+type webhookReceiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   []string
+}
+
+func (rcv *webhookReceiver) handle(w http.ResponseWriter, r *http.Request) {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	body, _ := io.ReadAll(r.Body)
+	rcv.requests = append(rcv.requests, r)
+	rcv.bodies = append(rcv.bodies, string(body))
+	if len(rcv.statuses) > 0 {
+		w.WriteHeader(rcv.statuses[0])
+		rcv.statuses = rcv.statuses[1:]
+	}
+}
+
+func queueLen(t *testing.T, dir string) int {
+	t.Helper()
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	return len(matches)
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	// Act
+	got := ticktick.SignWebhookPayload("secret", []byte(`{"id":"1"}`))
+
+	// Assert
+	want := "sha256=6146142a2ce0159e84c0767881e4ec80bc397da62526e7d19f70795eb79460c0"
+	if got != want {
+		t.Errorf("SignWebhookPayload() = %s, want %s", got, want)
+	}
+}
+
+func TestValidateWebhook(t *testing.T) {
+	tests := []struct {
+		name    string
+		hook    ticktick.Webhook
+		wantErr bool
+	}{
+		{"valid", ticktick.Webhook{URL: "http://localhost:5678/hook", Events: []string{"created", "completed"}}, false},
+		{"no scheme", ticktick.Webhook{URL: "localhost:5678/hook"}, true},
+		{"ftp", ticktick.Webhook{URL: "ftp://example.com/hook"}, true},
+		{"unknown event", ticktick.Webhook{URL: "https://example.com", Events: []string{"moved"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := ticktick.ValidateWebhook(tt.hook)
+
+			// Assert
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateWebhook() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWebhookQueue_DeliversSignedPayloads(t *testing.T) {
+	// Arrange
+	rcv := &webhookReceiver{}
+	server := httptest.NewServer(http.HandlerFunc(rcv.handle))
+	defer server.Close()
+	hooks := map[string]ticktick.Webhook{
+		"all":  {URL: server.URL + "/all", Secret: "s1"},
+		"done": {URL: server.URL + "/done", Events: []string{ticktick.WatchCompleted}, Secret: "s2"},
+	}
+	dir := filepath.Join(t.TempDir(), "queue")
+	q := ticktick.NewWebhookQueueAt(dir)
+	events := []ticktick.WatchEvent{
+		{Type: ticktick.WatchCreated, Task: ticktick.Task{ID: "t1", Title: "New"}},
+		{Type: ticktick.WatchCompleted, Task: ticktick.Task{ID: "t2", Title: "Done"}},
+	}
+
+	// Act
+	queued, err := q.Enqueue(hooks, events)
+	if err != nil {
+		t.Fatalf("Enqueue() returned unexpected error: %v", err)
+	}
+	result, err := q.Flush(hooks)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Flush() returned unexpected error: %v", err)
+	}
+	if queued != 3 || result.Delivered != 3 || queueLen(t, dir) != 0 {
+		t.Fatalf("queued %d, delivered %d, left %d; want 3, 3, 0", queued, result.Delivered, queueLen(t, dir))
+	}
+	var paths []string
+	for i, r := range rcv.requests {
+		paths = append(paths, r.URL.Path+":"+r.Header.Get(ticktick.WebhookEventHeader))
+		secret := hooks[strings.TrimPrefix(r.URL.Path, "/")].Secret
+		if got := r.Header.Get(ticktick.WebhookSignatureHeader); got != ticktick.SignWebhookPayload(secret, []byte(rcv.bodies[i])) {
+			t.Errorf("request %d signature = %s, want HMAC of the body", i, got)
+		}
+		var payload ticktick.WebhookPayload
+		json.Unmarshal([]byte(rcv.bodies[i]), &payload)
+		if payload.ID != r.Header.Get(ticktick.WebhookDeliveryHeader) || payload.Type != r.Header.Get(ticktick.WebhookEventHeader) {
+			t.Errorf("request %d payload = %+v, want the delivery ID and event of its headers", i, payload)
+		}
+	}
+	if got := strings.Join(paths, ","); got != "/all:created,/all:completed,/done:completed" {
+		t.Errorf("requests = %s, want every event to /all and completed to /done", got)
+	}
+}
+
+func TestWebhookQueue_RetriesWithBackoffAcrossRestarts(t *testing.T) {
+	// Arrange — the first two attempts fail
+	rcv := &webhookReceiver{statuses: []int{http.StatusInternalServerError, http.StatusBadGateway}}
+	server := httptest.NewServer(http.HandlerFunc(rcv.handle))
+	defer server.Close()
+	hooks := map[string]ticktick.Webhook{"hook": {URL: server.URL, Secret: "s"}}
+	dir := filepath.Join(t.TempDir(), "queue")
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	newQueue := func() *ticktick.WebhookQueue {
+		q := ticktick.NewWebhookQueueAt(dir)
+		q.SetClock(clock)
+		return q
+	}
+	newQueue().Enqueue(hooks, []ticktick.WatchEvent{{Type: ticktick.WatchCreated}, {Type: ticktick.WatchDeleted}})
+
+	// Act and Assert — each flush uses a fresh queue, as after a restart
+	steps := []struct {
+		advance       time.Duration
+		wantRequests  int
+		wantDelivered int
+	}{
+		{0, 1, 0},                // fails; the second delivery waits behind it
+		{10 * time.Second, 1, 0}, // not due yet
+		{20 * time.Second, 2, 0}, // first retry after 30s fails
+		{60 * time.Second, 4, 2}, // second retry after 60s succeeds, then the next one
+	}
+	for i, step := range steps {
+		now = now.Add(step.advance)
+		result, err := newQueue().Flush(hooks)
+		if err != nil {
+			t.Fatalf("step %d: Flush() returned unexpected error: %v", i, err)
+		}
+		if len(rcv.requests) != step.wantRequests || result.Delivered != step.wantDelivered {
+			t.Errorf("step %d: requests = %d, delivered = %d; want %d, %d", i, len(rcv.requests), result.Delivered, step.wantRequests, step.wantDelivered)
+		}
+	}
+	if queueLen(t, dir) != 0 {
+		t.Errorf("queue has %d deliveries left, want 0", queueLen(t, dir))
+	}
+}
+
+func TestWebhookQueue_DropsAfterLastAttempt(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	hooks := map[string]ticktick.Webhook{"hook": {URL: server.URL}}
+	dir := filepath.Join(t.TempDir(), "queue")
+	now := time.Now()
+	q := ticktick.NewWebhookQueueAt(dir)
+	q.SetClock(func() time.Time { return now })
+	q.Enqueue(hooks, []ticktick.WatchEvent{{Type: ticktick.WatchCreated}})
+
+	// Act
+	var dropped []ticktick.WebhookDelivery
+	for i := 0; i < 20 && len(dropped) == 0; i++ {
+		result, err := q.Flush(hooks)
+		if err != nil {
+			t.Fatalf("Flush() returned unexpected error: %v", err)
+		}
+		dropped = result.Dropped
+		now = now.Add(2 * time.Hour)
+	}
+
+	// Assert
+	if len(dropped) != 1 || dropped[0].Attempts != 10 || dropped[0].LastError != "webhook returned HTTP 503" {
+		t.Fatalf("dropped = %+v, want one delivery after 10 attempts", dropped)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("queue has %d files left, want 0", len(entries))
+	}
+}
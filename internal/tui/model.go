@@ -0,0 +1,552 @@
+// Package tui implements the interactive terminal UI started by "ticky ui".
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Backend is the subset of the TickTick client used by the UI.
+type Backend interface {
+	GetProjects() ([]ticktick.Project, error)
+	GetProjectData(projectID string) (*ticktick.ProjectData, error)
+	UpdateTask(req *ticktick.TaskUpdateRequest) (*ticktick.Task, error)
+	CompleteTask(projectID, taskID string) error
+	DeleteTask(projectID, taskID string) error
+	MoveTask(t *ticktick.Task, toProjectID string) (*ticktick.Task, error)
+}
+
+// Options configures a Model.
+type Options struct {
+	// InboxID is listed first in the sidebar as "Inbox" when set.
+	InboxID string
+	// RefreshInterval is the background refresh period. Zero disables it.
+	RefreshInterval time.Duration
+}
+
+type pane int
+
+const (
+	sidebarPane pane = iota
+	tasksPane
+)
+
+type mode int
+
+const (
+	normalMode mode = iota
+	titleMode
+	dueMode
+	tagsMode
+	confirmDeleteMode
+	moveMode
+)
+
+// Model is the bubbletea model of the UI.
+type Model struct {
+	backend Backend
+	opts    Options
+
+	projects   []ticktick.Project
+	projectIdx int
+	projectID  string // project whose tasks are shown
+	tasks      []ticktick.Task
+	taskIdx    int
+	focus      pane
+
+	mode    mode
+	input   []rune
+	moveIdx int
+
+	pending int  // mutations in flight; refreshes are skipped meanwhile
+	gen     int  // bumped when a mutation starts or ends; older loads are stale
+	stale   bool // a load was dropped; reload once no mutation is in flight
+	loading bool
+	status  string
+	err     error
+
+	width, height int
+}
+
+type projectsLoadedMsg struct {
+	projects []ticktick.Project
+	err      error
+}
+
+type tasksLoadedMsg struct {
+	projectID string
+	gen       int // mutation generation when the load started
+	tasks     []ticktick.Task
+	err       error
+}
+
+type tickMsg time.Time
+
+// opDoneMsg reports the result of an optimistic mutation. On error an update
+// is rolled back with undo, which only restores the fields it changed, and a
+// removed task is put back as prev at index.
+type opDoneMsg struct {
+	action    string
+	projectID string
+	taskID    string
+	undo      func(*ticktick.Task)
+	prev      ticktick.Task
+	index     int
+	result    *ticktick.Task // updated task to keep in the list, if any
+	err       error
+}
+
+// New returns a Model backed by b.
+func New(b Backend, opts Options) Model {
+	return Model{backend: b, opts: opts, loading: true}
+}
+
+// Init implements tea.Model.
+func (m Model) Init() tea.Cmd {
+	return tea.Batch(m.loadProjects(), m.scheduleRefresh())
+}
+
+func (m Model) loadProjects() tea.Cmd {
+	b, inboxID := m.backend, m.opts.InboxID
+	return func() tea.Msg {
+		projects, err := b.GetProjects()
+		if err != nil {
+			return projectsLoadedMsg{err: err}
+		}
+		if inboxID != "" {
+			projects = append([]ticktick.Project{{ID: inboxID, Name: "Inbox"}}, projects...)
+		}
+		return projectsLoadedMsg{projects: projects}
+	}
+}
+
+func (m Model) loadTasks(projectID string) tea.Cmd {
+	b, gen := m.backend, m.gen
+	return func() tea.Msg {
+		pd, err := b.GetProjectData(projectID)
+		if err != nil {
+			return tasksLoadedMsg{projectID: projectID, gen: gen, err: err}
+		}
+		return tasksLoadedMsg{projectID: projectID, gen: gen, tasks: pd.Tasks}
+	}
+}
+
+func (m Model) scheduleRefresh() tea.Cmd {
+	if m.opts.RefreshInterval <= 0 {
+		return nil
+	}
+	return tea.Tick(m.opts.RefreshInterval, func(t time.Time) tea.Msg { return tickMsg(t) })
+}
+
+// Update implements tea.Model.
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		return m, nil
+
+	case projectsLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.err = fmt.Errorf("failed to load projects: %w", msg.err)
+			return m, nil
+		}
+		m.projects = msg.projects
+		if len(m.projects) == 0 {
+			return m, nil
+		}
+		m.projectIdx = clamp(m.projectIdx, len(m.projects))
+		return m.openProject()
+
+	case tasksLoadedMsg:
+		if msg.projectID != m.projectID {
+			return m, nil
+		}
+		// A load that overlapped a mutation may predate it and would undo
+		// the optimistic change; fetch again once the mutations are done
+		if msg.gen != m.gen || m.pending > 0 {
+			if m.pending > 0 {
+				m.stale = true
+				return m, nil
+			}
+			return m, m.loadTasks(m.projectID)
+		}
+		m.loading = false
+		if msg.err != nil {
+			m.err = fmt.Errorf("failed to load tasks: %w", msg.err)
+			return m, nil
+		}
+		m.err = nil
+		m.setTasks(msg.tasks)
+		return m, nil
+
+	case tickMsg:
+		cmds := []tea.Cmd{m.scheduleRefresh()}
+		if m.pending == 0 && m.mode == normalMode && m.projectID != "" {
+			cmds = append(cmds, m.loadTasks(m.projectID))
+		}
+		return m, tea.Batch(cmds...)
+
+	case opDoneMsg:
+		m.pending--
+		m.gen++
+		var reload tea.Cmd
+		if m.pending == 0 && m.stale {
+			m.stale = false
+			reload = m.loadTasks(m.projectID)
+		}
+		if msg.err != nil {
+			m.rollback(msg)
+			m.err = fmt.Errorf("failed to %s task: %w", msg.action, msg.err)
+			return m, reload
+		}
+		if msg.result != nil && msg.projectID == m.projectID {
+			if i := m.indexOf(msg.taskID); i >= 0 {
+				m.tasks[i] = *msg.result
+			}
+		}
+		m.status = fmt.Sprintf("Task %s", pastTense(msg.action))
+		return m, reload
+
+	case tea.KeyMsg:
+		if m.mode != normalMode {
+			return m.updateInput(msg)
+		}
+		return m.updateNormal(msg)
+	}
+	return m, nil
+}
+
+func (m Model) updateNormal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.status = ""
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "tab":
+		if m.focus == sidebarPane {
+			m.focus = tasksPane
+		} else {
+			m.focus = sidebarPane
+		}
+		return m, nil
+	case "r":
+		if m.projectID == "" {
+			return m, nil
+		}
+		m.loading = true
+		return m, m.loadTasks(m.projectID)
+	}
+
+	if m.focus == sidebarPane {
+		switch msg.String() {
+		case "j", "down":
+			m.projectIdx = clamp(m.projectIdx+1, len(m.projects))
+		case "k", "up":
+			m.projectIdx = clamp(m.projectIdx-1, len(m.projects))
+		case "enter", "l", "right":
+			if len(m.projects) == 0 {
+				return m, nil
+			}
+			m.focus = tasksPane
+			return m.openProject()
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "j", "down":
+		m.taskIdx = clamp(m.taskIdx+1, len(m.tasks))
+		return m, nil
+	case "k", "up":
+		m.taskIdx = clamp(m.taskIdx-1, len(m.tasks))
+		return m, nil
+	case "h", "left":
+		m.focus = sidebarPane
+		return m, nil
+	}
+
+	task := m.selected()
+	if task == nil {
+		return m, nil
+	}
+	switch msg.String() {
+	case "x":
+		return m.complete(*task)
+	case "d":
+		m.mode = confirmDeleteMode
+	case "e":
+		m.startInput(titleMode, task.Title)
+	case "u":
+		m.startInput(dueMode, task.DueDay())
+	case "t":
+		m.startInput(tagsMode, strings.Join(task.Tags, ", "))
+	case "p":
+		req := task.UpdateRequest()
+		next := nextPriority(task.Priority)
+		req.Priority = &next
+		prev := task.Priority
+		return m.update(*task, req,
+			func(t *ticktick.Task) { t.Priority = next },
+			func(t *ticktick.Task) { t.Priority = prev })
+	case "m":
+		m.mode = moveMode
+		m.moveIdx = m.projectIdx
+	}
+	return m, nil
+}
+
+func (m *Model) startInput(md mode, initial string) {
+	m.mode = md
+	m.input = []rune(initial)
+}
+
+func (m Model) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	task := m.selected()
+	if task == nil {
+		m.mode = normalMode
+		return m, nil
+	}
+
+	switch m.mode {
+	case confirmDeleteMode:
+		m.mode = normalMode
+		if msg.String() == "y" {
+			return m.delete(*task)
+		}
+		return m, nil
+
+	case moveMode:
+		switch msg.String() {
+		case "j", "down":
+			m.moveIdx = clamp(m.moveIdx+1, len(m.projects))
+		case "k", "up":
+			m.moveIdx = clamp(m.moveIdx-1, len(m.projects))
+		case "enter":
+			m.mode = normalMode
+			return m.move(*task, m.projects[m.moveIdx])
+		case "esc", "q":
+			m.mode = normalMode
+		}
+		return m, nil
+	}
+
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.mode = normalMode
+		return m, nil
+	case tea.KeyEnter:
+		return m.submitInput(*task)
+	case tea.KeyBackspace:
+		if len(m.input) > 0 {
+			m.input = m.input[:len(m.input)-1]
+		}
+	case tea.KeyCtrlU:
+		m.input = nil
+	case tea.KeySpace:
+		m.input = append(m.input, ' ')
+	case tea.KeyRunes:
+		m.input = append(m.input, msg.Runes...)
+	}
+	return m, nil
+}
+
+func (m Model) submitInput(task ticktick.Task) (tea.Model, tea.Cmd) {
+	value := strings.TrimSpace(string(m.input))
+	md := m.mode
+	m.mode = normalMode
+	m.input = nil
+	req := task.UpdateRequest()
+
+	switch md {
+	case titleMode:
+		if value == "" || value == task.Title {
+			return m, nil
+		}
+		req.Title = value
+		return m.update(task, req,
+			func(t *ticktick.Task) { t.Title = value },
+			func(t *ticktick.Task) { t.Title = task.Title })
+
+	case dueMode:
+		due := ""
+		if value != "" {
+			d, err := ticktick.ParseDate(value)
+			if err != nil {
+				m.err = err
+				return m, nil
+			}
+			due = d
+		}
+		req.DueDate = &due
+		return m.update(task, req,
+			func(t *ticktick.Task) { t.DueDate = due },
+			func(t *ticktick.Task) { t.DueDate = task.DueDate })
+
+	case tagsMode:
+		var tags []string
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+		req.Tags = tags
+		return m.update(task, req,
+			func(t *ticktick.Task) { t.Tags = tags },
+			func(t *ticktick.Task) { t.Tags = task.Tags })
+	}
+	return m, nil
+}
+
+// update applies edit locally and sends req to the API. undo reverts the
+// edit if the API rejects it.
+func (m Model) update(task ticktick.Task, req *ticktick.TaskUpdateRequest, edit, undo func(*ticktick.Task)) (tea.Model, tea.Cmd) {
+	edit(&m.tasks[m.indexOf(task.ID)])
+	m.startMutation()
+	b := m.backend
+	return m, func() tea.Msg {
+		updated, err := b.UpdateTask(req)
+		return opDoneMsg{action: "update", projectID: task.ProjectID, taskID: task.ID, undo: undo, result: updated, err: err}
+	}
+}
+
+func (m Model) complete(task ticktick.Task) (tea.Model, tea.Cmd) {
+	i := m.remove(task.ID)
+	m.startMutation()
+	b := m.backend
+	return m, func() tea.Msg {
+		err := b.CompleteTask(task.ProjectID, task.ID)
+		return opDoneMsg{action: "complete", projectID: task.ProjectID, taskID: task.ID, prev: task, index: i, err: err}
+	}
+}
+
+func (m Model) delete(task ticktick.Task) (tea.Model, tea.Cmd) {
+	i := m.remove(task.ID)
+	m.startMutation()
+	b := m.backend
+	return m, func() tea.Msg {
+		err := b.DeleteTask(task.ProjectID, task.ID)
+		return opDoneMsg{action: "delete", projectID: task.ProjectID, taskID: task.ID, prev: task, index: i, err: err}
+	}
+}
+
+func (m Model) move(task ticktick.Task, to ticktick.Project) (tea.Model, tea.Cmd) {
+	if to.ID == task.ProjectID {
+		return m, nil
+	}
+	i := m.remove(task.ID)
+	m.startMutation()
+	b := m.backend
+	return m, func() tea.Msg {
+		_, err := b.MoveTask(&task, to.ID)
+		return opDoneMsg{action: "move", projectID: task.ProjectID, taskID: task.ID, prev: task, index: i, err: err}
+	}
+}
+
+// startMutation records that a mutation is in flight.
+func (m *Model) startMutation() {
+	m.pending++
+	m.gen++
+}
+
+// rollback reverts a failed mutation, leaving other changes to the task in
+// place.
+func (m *Model) rollback(msg opDoneMsg) {
+	if msg.projectID != m.projectID {
+		return
+	}
+	i := m.indexOf(msg.taskID)
+	switch {
+	case msg.undo != nil:
+		if i >= 0 {
+			msg.undo(&m.tasks[i])
+		}
+	case i < 0:
+		i = min(msg.index, len(m.tasks))
+		m.tasks = append(m.tasks[:i], append([]ticktick.Task{msg.prev}, m.tasks[i:]...)...)
+	}
+}
+
+func (m Model) openProject() (tea.Model, tea.Cmd) {
+	p := m.projects[m.projectIdx]
+	if p.ID != m.projectID {
+		m.projectID = p.ID
+		m.tasks = nil
+		m.taskIdx = 0
+	}
+	m.loading = true
+	return m, m.loadTasks(p.ID)
+}
+
+// setTasks replaces the task list, keeping the cursor on the same task.
+func (m *Model) setTasks(tasks []ticktick.Task) {
+	var selectedID string
+	if t := m.selected(); t != nil {
+		selectedID = t.ID
+	}
+	m.tasks = tasks
+	m.taskIdx = clamp(m.taskIdx, len(tasks))
+	if i := m.indexOf(selectedID); i >= 0 {
+		m.taskIdx = i
+	}
+}
+
+// remove deletes a task from the list and returns its former index.
+func (m *Model) remove(taskID string) int {
+	i := m.indexOf(taskID)
+	m.tasks = append(m.tasks[:i:i], m.tasks[i+1:]...)
+	m.taskIdx = clamp(m.taskIdx, len(m.tasks))
+	return i
+}
+
+func (m Model) selected() *ticktick.Task {
+	if m.taskIdx < 0 || m.taskIdx >= len(m.tasks) {
+		return nil
+	}
+	return &m.tasks[m.taskIdx]
+}
+
+func (m Model) indexOf(taskID string) int {
+	for i, t := range m.tasks {
+		if t.ID == taskID {
+			return i
+		}
+	}
+	return -1
+}
+
+// clamp limits i to the valid indexes of a list of length n.
+func clamp(i, n int) int {
+	if i >= n {
+		i = n - 1
+	}
+	if i < 0 {
+		i = 0
+	}
+	return i
+}
+
+// nextPriority cycles none → low → medium → high → none.
+func nextPriority(p int) int {
+	switch p {
+	case ticktick.PriorityNone:
+		return ticktick.PriorityLow
+	case ticktick.PriorityLow:
+		return ticktick.PriorityMedium
+	case ticktick.PriorityMedium:
+		return ticktick.PriorityHigh
+	default:
+		return ticktick.PriorityNone
+	}
+}
+
+func pastTense(action string) string {
+	if strings.HasSuffix(action, "e") {
+		return action + "d"
+	}
+	return action + "ed"
+}
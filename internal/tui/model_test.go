@@ -0,0 +1,347 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// fakeBackend serves fixed projects and tasks and records mutations.
+type fakeBackend struct {
+	mu       sync.Mutex
+	projects []ticktick.Project
+	tasks    map[string][]ticktick.Task
+	fail     error
+	calls    []string
+	updates  []*ticktick.TaskUpdateRequest
+}
+
+func newFakeBackend() *fakeBackend {
+	return &fakeBackend{
+		projects: []ticktick.Project{{ID: "proj-1", Name: "Work"}, {ID: "proj-2", Name: "Home"}},
+		tasks: map[string][]ticktick.Task{
+			"inbox": {{ID: "task-0", ProjectID: "inbox", Title: "Inbox task"}},
+			"proj-1": {
+				{ID: "task-1", ProjectID: "proj-1", Title: "Write report", Tags: []string{"work"}},
+				{ID: "task-2", ProjectID: "proj-1", Title: "Review PR", Priority: ticktick.PriorityMedium},
+			},
+		},
+	}
+}
+
+func (f *fakeBackend) record(call string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, call)
+	return f.fail
+}
+
+func (f *fakeBackend) GetProjects() ([]ticktick.Project, error) {
+	return f.projects, nil
+}
+
+func (f *fakeBackend) GetProjectData(projectID string) (*ticktick.ProjectData, error) {
+	return &ticktick.ProjectData{Tasks: f.tasks[projectID]}, nil
+}
+
+func (f *fakeBackend) UpdateTask(req *ticktick.TaskUpdateRequest) (*ticktick.Task, error) {
+	if err := f.record("update " + req.ID); err != nil {
+		return nil, err
+	}
+	f.updates = append(f.updates, req)
+	return &ticktick.Task{ID: req.ID, ProjectID: req.ProjectID, Title: req.Title, Priority: *req.Priority, Tags: req.Tags}, nil
+}
+
+func (f *fakeBackend) CompleteTask(projectID, taskID string) error {
+	return f.record("complete " + taskID)
+}
+
+func (f *fakeBackend) DeleteTask(projectID, taskID string) error {
+	return f.record("delete " + taskID)
+}
+
+func (f *fakeBackend) MoveTask(t *ticktick.Task, toProjectID string) (*ticktick.Task, error) {
+	if err := f.record("move " + t.ID + " " + toProjectID); err != nil {
+		return nil, err
+	}
+	return &ticktick.Task{ID: "moved", ProjectID: toProjectID}, nil
+}
+
+// send delivers msg to the model and runs the resulting commands to
+// completion, feeding their messages back in.
+func send(t *testing.T, m Model, msg tea.Msg) Model {
+	t.Helper()
+	next, cmd := m.Update(msg)
+	m = next.(Model)
+	return runCmd(t, m, cmd)
+}
+
+func runCmd(t *testing.T, m Model, cmd tea.Cmd) Model {
+	t.Helper()
+	if cmd == nil {
+		return m
+	}
+	switch msg := cmd().(type) {
+	case nil:
+		return m
+	case tea.BatchMsg:
+		for _, c := range msg {
+			m = runCmd(t, m, c)
+		}
+		return m
+	case tea.QuitMsg:
+		return m
+	default:
+		return send(t, m, msg)
+	}
+}
+
+func keys(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+// startModel returns a model showing the tasks of "Work" with the task pane focused.
+func startModel(t *testing.T, b *fakeBackend) Model {
+	t.Helper()
+	m := New(b, Options{InboxID: "inbox"})
+	m = runCmd(t, m, m.Init())
+	m = send(t, m, keys("j"))
+	m = send(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.projectID != "proj-1" || len(m.tasks) != 2 {
+		t.Fatalf("startModel: project = %q with %d tasks, want proj-1 with 2", m.projectID, len(m.tasks))
+	}
+	return m
+}
+
+func TestModel_LoadsInboxFirst(t *testing.T) {
+	// Act
+	m := New(newFakeBackend(), Options{InboxID: "inbox"})
+	m = runCmd(t, m, m.Init())
+
+	// Assert
+	if len(m.projects) != 3 || m.projects[0].Name != "Inbox" {
+		t.Fatalf("projects = %+v, want Inbox followed by 2 projects", m.projects)
+	}
+	if m.projectID != "inbox" || len(m.tasks) != 1 {
+		t.Errorf("shown project = %q with %d tasks, want inbox with 1", m.projectID, len(m.tasks))
+	}
+}
+
+func TestModel_Complete(t *testing.T) {
+	// Arrange
+	b := newFakeBackend()
+	m := startModel(t, b)
+
+	// Act
+	m = send(t, m, keys("x"))
+
+	// Assert
+	if len(m.tasks) != 1 || m.tasks[0].ID != "task-2" {
+		t.Errorf("tasks = %+v, want only task-2 left", m.tasks)
+	}
+	if len(b.calls) != 1 || b.calls[0] != "complete task-1" {
+		t.Errorf("calls = %v, want [complete task-1]", b.calls)
+	}
+	if m.pending != 0 || m.err != nil {
+		t.Errorf("pending = %d, err = %v, want 0 and nil", m.pending, m.err)
+	}
+}
+
+func TestModel_RollbackOnError(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []tea.Msg
+	}{
+		{"complete", []tea.Msg{keys("x")}},
+		{"delete", []tea.Msg{keys("d"), keys("y")}},
+		{"priority", []tea.Msg{keys("p")}},
+		{"move", []tea.Msg{keys("m"), keys("j"), tea.KeyMsg{Type: tea.KeyEnter}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			b := newFakeBackend()
+			m := startModel(t, b)
+			b.fail = errors.New("boom")
+
+			// Act
+			for _, msg := range tt.input {
+				m = send(t, m, msg)
+			}
+
+			// Assert
+			if len(b.calls) != 1 {
+				t.Fatalf("calls = %v, want one API call", b.calls)
+			}
+			if len(m.tasks) != 2 || m.tasks[0].ID != "task-1" || m.tasks[0].Priority != ticktick.PriorityNone {
+				t.Errorf("tasks = %+v, want task-1 restored unchanged at index 0", m.tasks)
+			}
+			if m.err == nil || !strings.Contains(m.err.Error(), "boom") {
+				t.Errorf("err = %v, want API error", m.err)
+			}
+		})
+	}
+}
+
+func TestModel_EditTitle(t *testing.T) {
+	// Arrange
+	b := newFakeBackend()
+	m := startModel(t, b)
+
+	// Act — replace the prefilled title
+	m = send(t, m, keys("e"))
+	m = send(t, m, tea.KeyMsg{Type: tea.KeyCtrlU})
+	m = send(t, m, keys("Write"))
+	m = send(t, m, tea.KeyMsg{Type: tea.KeySpace})
+	m = send(t, m, keys("summary"))
+	m = send(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+
+	// Assert
+	if m.mode != normalMode {
+		t.Errorf("mode = %v, want normal after enter", m.mode)
+	}
+	if m.tasks[0].Title != "Write summary" {
+		t.Errorf("title = %q, want %q", m.tasks[0].Title, "Write summary")
+	}
+	if len(b.updates) != 1 || b.updates[0].Title != "Write summary" || len(b.updates[0].Tags) != 1 {
+		t.Errorf("updates = %+v, want title change keeping tags", b.updates)
+	}
+}
+
+func TestModel_SetDueAndTags(t *testing.T) {
+	// Arrange
+	b := newFakeBackend()
+	m := startModel(t, b)
+
+	// Act
+	m = send(t, m, keys("u"))
+	m = send(t, m, keys("2026-03-02"))
+	m = send(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	m = send(t, m, keys("t"))
+	m = send(t, m, tea.KeyMsg{Type: tea.KeyCtrlU})
+	m = send(t, m, keys("a,b"))
+	m = send(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+
+	// Assert
+	if len(b.updates) != 2 {
+		t.Fatalf("updates = %d, want 2", len(b.updates))
+	}
+	want, _ := ticktick.ParseDate("2026-03-02")
+	if b.updates[0].DueDate == nil || *b.updates[0].DueDate != want {
+		t.Errorf("due update = %v, want %q", b.updates[0].DueDate, want)
+	}
+	if got := strings.Join(b.updates[1].Tags, ","); got != "a,b" {
+		t.Errorf("tags update = %q, want %q", got, "a,b")
+	}
+}
+
+func TestModel_InvalidDueKeepsTask(t *testing.T) {
+	// Arrange
+	b := newFakeBackend()
+	m := startModel(t, b)
+
+	// Act
+	m = send(t, m, keys("u"))
+	m = send(t, m, keys("someday"))
+	m = send(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+
+	// Assert
+	if len(b.calls) != 0 {
+		t.Errorf("calls = %v, want no API call", b.calls)
+	}
+	if m.err == nil {
+		t.Error("err = nil, want date parse error")
+	}
+}
+
+func TestModel_RefreshKeepsCursor(t *testing.T) {
+	// Arrange
+	b := newFakeBackend()
+	m := startModel(t, b)
+	m = send(t, m, keys("j"))
+	b.tasks["proj-1"] = []ticktick.Task{
+		{ID: "task-3", ProjectID: "proj-1", Title: "New"},
+		{ID: "task-1", ProjectID: "proj-1", Title: "Write report"},
+		{ID: "task-2", ProjectID: "proj-1", Title: "Review PR"},
+	}
+
+	// Act
+	m = send(t, m, tickMsg{})
+
+	// Assert
+	if len(m.tasks) != 3 {
+		t.Fatalf("tasks = %d, want 3 after refresh", len(m.tasks))
+	}
+	if got := m.selected().ID; got != "task-2" {
+		t.Errorf("selected = %q, want cursor to stay on task-2", got)
+	}
+}
+
+// pendingMsg runs cmd without delivering its message, so a test can choose
+// the order in which results arrive.
+func pendingMsg(t *testing.T, cmd tea.Cmd) tea.Msg {
+	t.Helper()
+	if cmd == nil {
+		t.Fatal("expected a command")
+	}
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		for _, c := range batch {
+			if c != nil {
+				return pendingMsg(t, c)
+			}
+		}
+	}
+	return msg
+}
+
+func TestModel_LateRefreshDoesNotUndoMutation(t *testing.T) {
+	// Arrange — a refresh starts while the account still has task-1
+	b := newFakeBackend()
+	m := startModel(t, b)
+	next, cmd := m.Update(tickMsg{})
+	m = next.(Model)
+	late := pendingMsg(t, cmd)
+
+	// Act — task-1 is completed before the refresh result arrives
+	m = send(t, m, keys("x"))
+	b.tasks["proj-1"] = b.tasks["proj-1"][1:]
+	m = send(t, m, late)
+
+	// Assert
+	if len(m.tasks) != 1 || m.tasks[0].ID != "task-2" {
+		t.Errorf("tasks = %+v, want only task-2", m.tasks)
+	}
+}
+
+func TestModel_RollbackKeepsStackedUpdate(t *testing.T) {
+	// Arrange — change the priority, then the title, before either returns
+	b := newFakeBackend()
+	m := startModel(t, b)
+	next, priorityCmd := m.Update(keys("p"))
+	m = next.(Model)
+	m = send(t, m, keys("e"))
+	m = send(t, m, tea.KeyMsg{Type: tea.KeyCtrlU})
+	m = send(t, m, keys("Renamed"))
+	next, titleCmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(Model)
+
+	// Act — the title update succeeds, the priority update fails
+	m = send(t, m, pendingMsg(t, titleCmd))
+	b.fail = errors.New("boom")
+	m = send(t, m, pendingMsg(t, priorityCmd))
+
+	// Assert
+	if m.tasks[0].Title != "Renamed" || m.tasks[0].Priority != ticktick.PriorityNone {
+		t.Errorf("task = %+v, want title Renamed with the priority rolled back", m.tasks[0])
+	}
+	if m.pending != 0 {
+		t.Errorf("pending = %d, want 0", m.pending)
+	}
+}
@@ -0,0 +1,183 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tackeyy/ticky/internal/ticktick"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const sidebarWidth = 24
+
+var (
+	paneStyle   = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(0, 1)
+	activeStyle = paneStyle.BorderForeground(lipgloss.Color("12"))
+	cursorStyle = lipgloss.NewStyle().Reverse(true)
+	dimStyle    = lipgloss.NewStyle().Faint(true)
+	titleStyle  = lipgloss.NewStyle().Bold(true)
+	errorStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+
+	priorityStyles = map[int]lipgloss.Style{
+		ticktick.PriorityLow:    lipgloss.NewStyle().Foreground(lipgloss.Color("4")),
+		ticktick.PriorityMedium: lipgloss.NewStyle().Foreground(lipgloss.Color("3")),
+		ticktick.PriorityHigh:   lipgloss.NewStyle().Foreground(lipgloss.Color("1")),
+	}
+)
+
+// View implements tea.Model.
+func (m Model) View() string {
+	if m.width == 0 {
+		return "Loading..."
+	}
+
+	// Borders and padding take 4 columns per pane; the footer takes 2 rows.
+	height := max(m.height-2-2, 3)
+	listWidth := max((m.width-sidebarWidth-12)*3/5, 20)
+	detailWidth := max(m.width-sidebarWidth-listWidth-12, 20)
+
+	sidebar := m.renderSidebar()
+	list := m.renderTasks(listWidth)
+	detail := m.renderDetail(detailWidth)
+
+	sideStyle, listStyle := paneStyle, activeStyle
+	if m.focus == sidebarPane || m.mode == moveMode {
+		sideStyle, listStyle = activeStyle, paneStyle
+	}
+	body := lipgloss.JoinHorizontal(lipgloss.Top,
+		sideStyle.Width(sidebarWidth).Height(height).Render(sidebar),
+		listStyle.Width(listWidth).Height(height).Render(list),
+		paneStyle.Width(detailWidth).Height(height).Render(detail),
+	)
+	return body + "\n" + m.renderFooter()
+}
+
+func (m Model) renderSidebar() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Projects") + "\n")
+	cursor := m.projectIdx
+	if m.mode == moveMode {
+		b.Reset()
+		b.WriteString(titleStyle.Render("Move to") + "\n")
+		cursor = m.moveIdx
+	}
+	for i, p := range m.projects {
+		line := truncate(p.Name, sidebarWidth)
+		if p.ID == m.projectID && m.mode != moveMode {
+			line = titleStyle.Render(line)
+		}
+		if i == cursor {
+			line = cursorStyle.Render(line)
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+func (m Model) renderTasks(width int) string {
+	if m.loading && len(m.tasks) == 0 {
+		return dimStyle.Render("Loading...")
+	}
+	if len(m.tasks) == 0 {
+		return dimStyle.Render("No tasks")
+	}
+
+	var b strings.Builder
+	for i, t := range m.tasks {
+		line := taskLine(t, width)
+		if i == m.taskIdx {
+			line = cursorStyle.Render(line)
+		} else if s, ok := priorityStyles[t.Priority]; ok {
+			line = s.Render(line)
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+func taskLine(t ticktick.Task, width int) string {
+	marks := map[int]string{ticktick.PriorityLow: "!", ticktick.PriorityMedium: "!!", ticktick.PriorityHigh: "!!!"}
+	line := fmt.Sprintf("%-3s %s", marks[t.Priority], t.Title)
+	if due := t.DueDay(); due != "" {
+		line += "  " + due
+	}
+	return truncate(line, width)
+}
+
+func (m Model) renderDetail(width int) string {
+	t := m.selected()
+	if t == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(truncate(t.Title, width)) + "\n\n")
+	field := func(name, value string) {
+		if value == "" {
+			value = dimStyle.Render("-")
+		}
+		fmt.Fprintf(&b, "%-9s %s\n", name+":", value)
+	}
+	field("Priority", ticktick.PriorityString(t.Priority))
+	field("Due", t.DueDay())
+	field("Tags", strings.Join(t.Tags, ", "))
+	field("ID", t.ID)
+	if t.Content != "" {
+		b.WriteString("\n" + lipgloss.NewStyle().Width(width).Render(t.Content) + "\n")
+	}
+	if len(t.Items) > 0 {
+		b.WriteString("\n")
+		for _, item := range t.Items {
+			box := "[ ]"
+			if item.Status == ticktick.ChecklistStatusCompleted {
+				box = "[x]"
+			}
+			b.WriteString(truncate(box+" "+item.Title, width) + "\n")
+		}
+	}
+	return b.String()
+}
+
+func (m Model) renderFooter() string {
+	var status string
+	switch m.mode {
+	case titleMode:
+		status = "Title: " + string(m.input) + "█"
+	case dueMode:
+		status = "Due (today, tomorrow, +3d, YYYY-MM-DD; empty clears): " + string(m.input) + "█"
+	case tagsMode:
+		status = "Tags (comma-separated): " + string(m.input) + "█"
+	case confirmDeleteMode:
+		status = "Delete this task? (y/n)"
+	case moveMode:
+		status = "Choose a project with j/k and press enter (esc cancels)"
+	default:
+		switch {
+		case m.err != nil:
+			status = errorStyle.Render(m.err.Error())
+		case m.pending > 0:
+			status = dimStyle.Render("Saving...")
+		default:
+			status = m.status
+		}
+	}
+
+	help := "q quit · tab pane · j/k move · enter open · x complete · d delete · e title · p priority · u due · t tags · m move · r refresh"
+	if m.mode != normalMode {
+		help = "enter confirm · esc cancel"
+	}
+	return status + "\n" + dimStyle.Render(truncate(help, m.width))
+}
+
+// truncate shortens s to at most width columns.
+func truncate(s string, width int) string {
+	if width <= 0 || lipgloss.Width(s) <= width {
+		return s
+	}
+	r := []rune(s)
+	for len(r) > 0 && lipgloss.Width(string(r))+1 > width {
+		r = r[:len(r)-1]
+	}
+	return string(r) + "…"
+}
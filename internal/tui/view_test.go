@@ -0,0 +1,57 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestModel_View(t *testing.T) {
+	// Arrange
+	m := startModel(t, newFakeBackend())
+	m = send(t, m, tea.WindowSizeMsg{Width: 120, Height: 30})
+
+	// Act
+	got := m.View()
+
+	// Assert
+	for _, want := range []string{"Inbox", "Work", "Write report", "!!  Review PR", "Tags:", "q quit"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("View() does not contain %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestModel_ViewInputPrompt(t *testing.T) {
+	// Arrange
+	m := startModel(t, newFakeBackend())
+	m = send(t, m, tea.WindowSizeMsg{Width: 120, Height: 30})
+
+	// Act
+	m = send(t, m, keys("d"))
+
+	// Assert
+	if got := m.View(); !strings.Contains(got, "Delete this task? (y/n)") {
+		t.Errorf("View() does not show the delete prompt:\n%s", got)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		in    string
+		width int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"exactly10!", 10, "exactly10!"},
+		{"much longer text", 8, "much lo…"},
+		{"日本語のタスク", 7, "日本語…"},
+	}
+
+	for _, tt := range tests {
+		if got := truncate(tt.in, tt.width); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.in, tt.width, got, tt.want)
+		}
+	}
+}